@@ -0,0 +1,269 @@
+package goga
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrUnknownGenome is returned by Tell when it is passed a genome that was
+// not handed out by Ask in the current generation, or was already told
+var ErrUnknownGenome = errors.New("genome was not handed out by Ask")
+
+// reset discards any generation in progress so that the next call to Ask
+// starts again by simulating the current population
+func (ga *GeneticAlgorithm) reset() {
+	ga.lru = New(ga.LRUSize)
+	ga.generation = 0
+	ga.elite = nil
+	ga.scores = nil
+	ga.objectiveCount = 0
+	ga.generationStarted = false
+	ga.selectionPopulation = nil
+	ga.parents = nil
+	ga.offspring = nil
+	ga.filled = 0
+	ga.queue = nil
+	ga.asked = make(map[Genome]int)
+	ga.outstanding = 0
+	ga.rejected = 0
+	ga.replaced = 0
+	ga.reused = nil
+	ga.cacheHits = 0
+	ga.cacheMisses = 0
+}
+
+// beginGeneration starts a new generation, the first generation simulates the
+// population itself, every following generation breeds a new population of
+// 'populationSize * MaterExtraRatio' genomes with the elite at its head
+func (ga *GeneticAlgorithm) beginGeneration() {
+	ga.shareRand()
+	if setter, ok := ga.Selector.(GenerationSetter); ok {
+		setter.SetGeneration(ga.generation)
+	}
+	if setter, ok := ga.Simulator.(DirectionSetter); ok {
+		setter.SetDirection(ga.direction)
+	}
+	extraGenomes := ga.Simulator.OnBeginSimulation()
+	ga.selectionPopulation, ga.totalFitness = scaleFitness(ga.scored(ga.population), ga.direction, ga.scaling)
+	ga.generationStarted = true
+	ga.replaced = 0
+
+	if ga.generation == 0 {
+		for i := 0; i < len(extraGenomes) && i < ga.populationSize; i++ {
+			ga.population[i] = extraGenomes[i]
+		}
+		ga.queue = nil
+		for _, g := range ga.population {
+			if f, ok := ga.getFitness(g.Key()); ok {
+				ga.cacheHits++
+				setStoredFitness(g, f)
+				continue
+			}
+			ga.queue = append(ga.queue, g)
+		}
+		if ga.duplicates == ReuseFitness {
+			for _, g := range ga.population {
+				ga.remember(g.Key(), g)
+			}
+		}
+		return
+	}
+
+	ga.offspring = make([]Genome, ga.populationSize*ga.MaterExtraRatio)
+	ga.offspring[0] = ga.elite
+	ga.filled = 1
+	ga.rejected = 0
+	for i := 0; i < len(extraGenomes) && ga.filled < len(ga.offspring)/2; i++ {
+		ga.acceptOffspring(extraGenomes[i])
+	}
+}
+
+// acceptOffspring adds 'g' to the population being bred, a genome with the same key as one
+// seen before is rejected, or reuses the fitness of that genome, as set by DuplicateGenomes
+// Once MaxDuplicateRejections genomes have been rejected in a generation duplicates are
+// accepted and simulated again, it reports whether 'g' was added
+func (ga *GeneticAlgorithm) acceptOffspring(g Genome) bool {
+	k := g.Key()
+	if cached, ok := ga.lru.Get(k); ok {
+		ga.cacheHits++
+		if source, ok := cached.(Genome); ok && ga.duplicates == ReuseFitness {
+			ga.reuse(g, source)
+			return true
+		}
+		if ga.duplicates == RejectDuplicates && ga.rejected < ga.maxDuplicateRejections {
+			ga.rejected++
+			return false
+		}
+	} else if f, ok := ga.getFitness(k); ok {
+		// Genomes found in the FitnessStore are never simulated, whatever the DuplicatePolicy
+		ga.cacheHits++
+		source := fitnessGenome(f)
+		ga.remember(k, source)
+		ga.reuse(g, source)
+		return true
+	} else {
+		ga.cacheMisses++
+	}
+	ga.remember(k, g)
+	ga.offspring[ga.filled] = g
+	ga.filled++
+	ga.queue = append(ga.queue, g)
+	return true
+}
+
+// selectParent picks a genome to mate, a BatchSelector is asked for the parents of
+// the rest of the generation in one go, which are then handed out one at a time
+func (ga *GeneticAlgorithm) selectParent() Genome {
+	batchSelector, ok := ga.Selector.(BatchSelector)
+	if !ok {
+		return unscaled(ga.Selector.Go(ga.selectionPopulation, ga.totalFitness))
+	}
+	if len(ga.parents) == 0 {
+		ga.parents = batchSelector.GoBatch(ga.selectionPopulation, ga.totalFitness, 2*(len(ga.offspring)-ga.filled))
+	}
+	g := ga.parents[0]
+	ga.parents = ga.parents[1:]
+	return unscaled(g)
+}
+
+func (ga *GeneticAlgorithm) breed() {
+	g1 := ga.selectParent()
+	g2 := ga.selectParent()
+	g3, g4 := ga.Mater.Go(g1, g2)
+	ga.acceptOffspring(g3)
+	if ga.filled < len(ga.offspring) {
+		ga.acceptOffspring(g4)
+	}
+}
+
+// Ask returns up to 'n' genomes of the current generation that need simulating,
+// breeding them with the Selector and Mater as required
+// The fitness of each returned genome should be set, by whatever means, and the genomes
+// passed back to Tell. Once every genome of a generation has been handed out Ask returns
+// an empty slice until they have all been told, at which point the next generation begins
+func (ga *GeneticAlgorithm) Ask(n int) []Genome {
+	if ga.populationSize == 0 {
+		return nil
+	}
+	if !ga.generationStarted {
+		ga.beginGeneration()
+	}
+
+	ret := make([]Genome, 0, n)
+	for len(ret) < n {
+		if len(ga.queue) == 0 {
+			if ga.generation == 0 || ga.filled == len(ga.offspring) {
+				break
+			}
+			ga.breed()
+			continue
+		}
+		g := ga.queue[0]
+		ga.queue = ga.queue[1:]
+		ga.asked[g]++
+		ga.outstanding++
+		ret = append(ret, g)
+	}
+	return ret
+}
+
+// Tell hands back genomes returned by Ask once their fitness has been set
+// When the last genome of a generation is told the generation is completed, the
+// elite is passed to the Mater and EliteConsumer and the next call to Ask starts
+// a new generation
+// A generation that needs nothing simulating, because the fitness of every genome was
+// reused, see DuplicateGenomes and StoreFitness, is completed by telling no genomes
+// ErrUnknownGenome is returned, and none of 'genomes' are told, if any of them
+// are not waiting to be told, as is ErrObjectiveCount in multi-objective mode if any of
+// them have a different number of objectives than the genomes told before. Otherwise the genomes are told and the first error from
+// the FitnessStore since the last call, if any, is returned
+func (ga *GeneticAlgorithm) Tell(genomes []Genome) error {
+	return ga.tell(genomes, genomes)
+}
+
+// tell is Tell, other than only the fitness of 'simulated', which should be some of 'genomes',
+// being put in the FitnessStore
+func (ga *GeneticAlgorithm) tell(genomes []Genome, simulated []Genome) error {
+	told := make(map[Genome]int)
+	for _, g := range genomes {
+		told[g]++
+		if told[g] > ga.asked[g] {
+			return ErrUnknownGenome
+		}
+	}
+	if err := ga.checkObjectives(genomes); err != nil {
+		return err
+	}
+	for g, n := range told {
+		ga.asked[g] -= n
+		if ga.asked[g] == 0 {
+			delete(ga.asked, g)
+		}
+		ga.outstanding -= n
+	}
+	ga.putFitness(simulated)
+
+	if ga.generationStarted && ga.outstanding == 0 && len(ga.queue) == 0 &&
+		(ga.generation == 0 || ga.filled == len(ga.offspring)) {
+		ga.endGeneration()
+	}
+	err := ga.storeErr
+	ga.storeErr = nil
+	return err
+}
+
+// endGeneration replaces the population with the fittest of the bred genomes, or the best
+// of the population and the bred genomes by Pareto front in multi-objective mode, and passes
+// the elite of the new population on
+func (ga *GeneticAlgorithm) endGeneration() {
+	ga.reuseFitness()
+	if ga.multiObjective {
+		candidates := append([]Genome{}, ga.population...)
+		if ga.generation > 0 {
+			candidates = append(candidates, ga.offspring[1:]...)
+		}
+		ga.scores = paretoScores(candidates, ga.direction)
+		ga.population = paretoSelect(candidates, ga.populationSize, ga.scores, ga.direction)
+	} else if ga.generation > 0 {
+		newPopulation := ga.offspring
+		sort.SliceStable(newPopulation, func(i, j int) bool {
+			return ga.fitter(newPopulation[i], newPopulation[j])
+		})
+		ga.population = make([]Genome, ga.populationSize)
+		kept := int(float64(ga.populationSize) * (1. - ga.randomRatio))
+		for i := 0; i < ga.populationSize; i++ {
+			if i < kept {
+				ga.population[i] = newPopulation[i]
+			} else {
+				// Random genomes are not simulated so are given the fitness of the least
+				// fit genome kept, which holds whatever the direction or sign of fitness
+				ga.population[i] = ga.createGenome()
+				if kept > 0 {
+					ga.population[i].SetFitness(newPopulation[kept-1].GetFitness())
+				}
+			}
+		}
+	}
+	ga.Simulator.OnEndSimulation(ga.population)
+
+	ga.generationStarted = false
+	ga.selectionPopulation = nil
+	ga.parents = nil
+	ga.offspring = nil
+	ga.filled = 0
+	ga.generation++
+	ga.elite = ga.getElite()
+	ga.Mater.OnElite(ga.elite)
+	ga.EliteConsumer.OnElite(ga.elite)
+}
+
+// Generation returns the number of generations that have been fully simulated
+func (ga *GeneticAlgorithm) Generation() int {
+	return ga.generation
+}
+
+// Elite returns the elite of the last fully simulated generation, or nil if
+// no generation has been fully simulated
+func (ga *GeneticAlgorithm) Elite() Genome {
+	return ga.elite
+}
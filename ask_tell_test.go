@@ -0,0 +1,95 @@
+package goga_test
+
+import (
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type AskTellSuite struct {
+}
+
+var _ = Suite(&AskTellSuite{})
+
+func helperTellFitness(genAlgo *goga.GeneticAlgorithm, genomes []goga.Genome, fitness float64) error {
+	for _, g := range genomes {
+		g.SetFitness(fitness)
+	}
+	return genAlgo.Tell(genomes)
+}
+
+func (s *AskTellSuite) TestShouldAskForNothingWithNoPopulation(t *C) {
+	genAlgo := goga.NewGeneticAlgorithm()
+	t.Assert(genAlgo.Ask(10), HasLen, 0)
+}
+
+func (s *AskTellSuite) TestShouldAskForPopulationFirst(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	populationSize := 10
+	genAlgo.Init(goga.PopulationSize(populationSize))
+
+	first := genAlgo.Ask(4)
+	t.Assert(first, HasLen, 4)
+	rest := genAlgo.Ask(100)
+	t.Assert(rest, HasLen, populationSize-4)
+	t.Assert(genAlgo.Ask(100), HasLen, 0)
+
+	population := genAlgo.GetPopulation()
+	for i, g := range append(first, rest...) {
+		t.Assert(g, Equals, population[i])
+	}
+}
+
+func (s *AskTellSuite) TestShouldCompleteGenerationOnceAllAreTold(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	ec := MyEliteConsumerCounter{}
+	genAlgo.EliteConsumer = &ec
+	genAlgo.Init(goga.PopulationSize(10))
+
+	genomes := genAlgo.Ask(10)
+	t.Assert(helperTellFitness(&genAlgo, genomes[:5], 1), IsNil)
+	t.Assert(genAlgo.Generation(), Equals, 0)
+	t.Assert(genAlgo.Elite(), IsNil)
+	t.Assert(genAlgo.Ask(10), HasLen, 0)
+
+	genomes[7].SetFitness(2)
+	t.Assert(genAlgo.Tell(genomes[5:]), IsNil)
+	t.Assert(genAlgo.Generation(), Equals, 1)
+	t.Assert(genAlgo.Elite(), Equals, genomes[7])
+	t.Assert(ec.NumCalls, Equals, 1)
+}
+
+func (s *AskTellSuite) TestShouldBreedFollowingGenerations(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	populationSize := 10
+	genAlgo.Init(goga.PopulationSize(populationSize), goga.MaterExtraRatio(3))
+
+	t.Assert(helperTellFitness(&genAlgo, genAlgo.Ask(populationSize), 1), IsNil)
+
+	// The elite is carried over without being asked for again
+	expected := (populationSize * 3) - 1
+	var children []goga.Genome
+	for genomes := genAlgo.Ask(7); len(genomes) > 0; genomes = genAlgo.Ask(7) {
+		for _, g := range genomes {
+			t.Assert(g, Not(Equals), genAlgo.Elite())
+		}
+		children = append(children, genomes...)
+	}
+	t.Assert(children, HasLen, expected)
+
+	t.Assert(helperTellFitness(&genAlgo, children, 2), IsNil)
+	t.Assert(genAlgo.Generation(), Equals, 2)
+	t.Assert(genAlgo.GetPopulation(), HasLen, populationSize)
+	t.Assert(genAlgo.Elite().GetFitness(), Equals, 2.0)
+}
+
+func (s *AskTellSuite) TestShouldRejectUnknownGenomes(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	genAlgo.Init(goga.PopulationSize(10))
+
+	genomes := genAlgo.Ask(2)
+	unknown := goga.NewGenome(goga.Bitset{})
+	t.Assert(genAlgo.Tell([]goga.Genome{genomes[0], unknown}), Equals, goga.ErrUnknownGenome)
+	t.Assert(genAlgo.Tell([]goga.Genome{genomes[0], genomes[0]}), Equals, goga.ErrUnknownGenome)
+	t.Assert(genAlgo.Tell([]goga.Genome{genomes[0]}), IsNil)
+	t.Assert(genAlgo.Tell([]goga.Genome{genomes[0]}), Equals, goga.ErrUnknownGenome)
+}
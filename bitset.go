@@ -1,15 +1,29 @@
 package goga
 
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+const wordSize = 64
+
 // Bitset - a simple bitset implementation
+// Bits are packed 64 to a uint64 word, bit 'i' being bit 'i % 64' of word 'i / 64', and
+// any bits of the last word beyond the size of the bitset are always 0
 type Bitset struct {
-	size int
-	bits []byte
+	size  int
+	words []uint64
+}
+
+func numWords(size int) int {
+	return (size + wordSize - 1) / wordSize
 }
 
 // Create - creates a bitset of length 'size'
 func (b *Bitset) Create(size int) {
 	b.size = size
-	b.bits = make([]byte, size)
+	b.words = make([]uint64, numWords(size))
 }
 
 // GetSize returns the size of the bitset
@@ -20,24 +34,36 @@ func (b *Bitset) GetSize() int {
 // Get returns the value in the bitset at index 'index'
 // or -1 if the index is out of range
 func (b *Bitset) Get(index int) int {
-	if index < b.size {
-		return int(b.bits[index])
+	if index >= 0 && index < b.size {
+		return int(b.words[index/wordSize] >> uint(index%wordSize) & 1)
 	}
 	return -1
 }
 
-// GetAll returns an int array of all the bits in the bitset
+// GetAll returns a copy of the bits in the bitset, one byte of 0 or 1 per bit
+// It is kept for compatibility with the original one byte per bit storage, the packed bits
+// are better read a word at a time with Uint64
 func (b *Bitset) GetAll() []byte {
-	return b.bits
+	ret := make([]byte, b.size)
+	for i := range ret {
+		ret[i] = byte(b.Get(i))
+	}
+	return ret
 }
 
 func (b *Bitset) setImpl(index, value int) {
-	b.bits[index] = byte(value)
+	mask := uint64(1) << uint(index%wordSize)
+	if value != 0 {
+		b.words[index/wordSize] |= mask
+	} else {
+		b.words[index/wordSize] &^= mask
+	}
 }
 
-// Set assigns value 'value' to the bit at index 'index'
+// Set assigns value 'value' to the bit at index 'index', any value other than 0 sets the bit
+// It returns false if the index is out of range
 func (b *Bitset) Set(index, value int) bool {
-	if index < b.size {
+	if index >= 0 && index < b.size {
 		b.setImpl(index, value)
 		return true
 	}
@@ -46,54 +72,295 @@ func (b *Bitset) Set(index, value int) bool {
 
 // SetAll assigns the value 'value' to all the bits in the set
 func (b *Bitset) SetAll(value int) {
-	for i := 0; i < b.size; i++ {
-		b.setImpl(i, value)
+	fill := uint64(0)
+	if value != 0 {
+		fill = math.MaxUint64
+	}
+	for i := range b.words {
+		b.words[i] = fill
 	}
+	b.clearTail()
 }
 
-// SetAll assigns the value 'value' to all the bits in the set
+// SetAllArr re-creates the bitset from 'value', one byte of 0 or 1 per bit as returned by GetAll
+// It is kept for compatibility with the original one byte per bit storage
 func (b *Bitset) SetAllArr(value []byte) {
-	b.bits = value
+	b.Create(len(value))
+	for i, v := range value {
+		b.setImpl(i, int(v))
+	}
+}
+
+// Uint64 returns the 'width' bits, at most 64, starting at index 'index' as an unsigned
+// integer whose least significant bit is the bit at 'index'
+// It returns false if any of the bits are out of range
+func (b *Bitset) Uint64(index, width int) (uint64, bool) {
+	if index < 0 || width < 0 || width > wordSize || index+width > b.size {
+		return 0, false
+	}
+	if width == 0 {
+		return 0, true
+	}
+	word, offset := index/wordSize, uint(index%wordSize)
+	ret := b.words[word] >> offset
+	if offset != 0 && int(offset)+width > wordSize {
+		ret |= b.words[word+1] << (wordSize - offset)
+	}
+	if width < wordSize {
+		ret &= (uint64(1) << uint(width)) - 1
+	}
+	return ret, true
+}
+
+// SetUint64 assigns the 'width' least significant bits of 'value', at most 64, to the bits
+// starting at index 'index', see Uint64
+// It returns false, and sets nothing, if any of the bits are out of range
+func (b *Bitset) SetUint64(index, width int, value uint64) bool {
+	if index < 0 || width < 0 || width > wordSize || index+width > b.size {
+		return false
+	}
+	if width == 0 {
+		return true
+	}
+	mask := uint64(math.MaxUint64)
+	if width < wordSize {
+		mask = (uint64(1) << uint(width)) - 1
+	}
+	value &= mask
+	word, offset := index/wordSize, uint(index%wordSize)
+	b.words[word] = b.words[word]&^(mask<<offset) | value<<offset
+	if offset != 0 && int(offset)+width > wordSize {
+		b.words[word+1] = b.words[word+1]&^(mask>>(wordSize-offset)) | value>>(wordSize-offset)
+	}
+	return true
+}
+
+// copyRange copies 'n' bits of 'src' starting at 'srcIndex' to 'dst' starting at
+// 'dstIndex' a word at a time, both ranges must be in range
+func copyRange(dst *Bitset, dstIndex int, src *Bitset, srcIndex, n int) {
+	for n > 0 {
+		width := n
+		if width > wordSize {
+			width = wordSize
+		}
+		value, _ := src.Uint64(srcIndex, width)
+		dst.SetUint64(dstIndex, width, value)
+		dstIndex += width
+		srcIndex += width
+		n -= width
+	}
+}
+
+// clearTail zeroes the bits of the last word beyond the size of the bitset
+func (b *Bitset) clearTail() {
+	if tail := uint(b.size % wordSize); tail != 0 {
+		b.words[len(b.words)-1] &= (uint64(1) << tail) - 1
+	}
 }
 
 // CreateCopy returns a bit for bit copy of the bitset
 func (b *Bitset) CreateCopy() Bitset {
-	newBitset := Bitset{}
-	newBitset.Create(b.size)
-	for i := 0; i < b.size; i++ {
-		newBitset.Set(i, b.Get(i))
-	}
+	newBitset := Bitset{size: b.size, words: make([]uint64, len(b.words))}
+	copy(newBitset.words, b.words)
 	return newBitset
 }
 
-// Slice returns an out of memory slice of the current bitset
+// Slice returns a copy of the bits of the current bitset
 // between bits 'startingBit' and 'startingBit + size'
 func (b *Bitset) Slice(startingBit, size int) Bitset {
+	if startingBit < 0 || size < 0 || startingBit+size > b.size {
+		panic("slice is out of range")
+	}
 	ret := Bitset{}
 	ret.Create(size)
-	ret.bits = b.bits[startingBit : startingBit+size]
+	copyRange(&ret, 0, b, startingBit, size)
+	return ret
+}
+
+// And returns the bitwise and of the bitset and 'other'
+// The result is as long as the longer of the two, bits beyond the end of a bitset count as 0
+func (b *Bitset) And(other *Bitset) Bitset {
+	return b.combine(other, func(x, y uint64) uint64 { return x & y })
+}
+
+// Or returns the bitwise or of the bitset and 'other', see And
+func (b *Bitset) Or(other *Bitset) Bitset {
+	return b.combine(other, func(x, y uint64) uint64 { return x | y })
+}
+
+// Xor returns the bitwise exclusive or of the bitset and 'other', see And
+func (b *Bitset) Xor(other *Bitset) Bitset {
+	return b.combine(other, func(x, y uint64) uint64 { return x ^ y })
+}
+
+func (b *Bitset) combine(other *Bitset, op func(x, y uint64) uint64) Bitset {
+	ret := Bitset{}
+	if b.size > other.size {
+		ret.Create(b.size)
+	} else {
+		ret.Create(other.size)
+	}
+	for i := range ret.words {
+		var x, y uint64
+		if i < len(b.words) {
+			x = b.words[i]
+		}
+		if i < len(other.words) {
+			y = other.words[i]
+		}
+		ret.words[i] = op(x, y)
+	}
 	return ret
 }
 
+// Not returns a copy of the bitset with every bit flipped
+func (b *Bitset) Not() Bitset {
+	ret := b.CreateCopy()
+	for i := range ret.words {
+		ret.words[i] = ^ret.words[i]
+	}
+	ret.clearTail()
+	return ret
+}
+
+// Flip flips the bits from index 'start' up to, but not including, index 'end'
+// It returns false, and flips nothing, if the range is out of range
+func (b *Bitset) Flip(start, end int) bool {
+	if start < 0 || end > b.size || start > end {
+		return false
+	}
+	for i := start; i < end; i += wordSize {
+		width := wordSize
+		if end-i < width {
+			width = end - i
+		}
+		value, _ := b.Uint64(i, width)
+		b.SetUint64(i, width, ^value)
+	}
+	return true
+}
+
+// PopCount returns the number of set bits
+func (b *Bitset) PopCount() int {
+	ret := 0
+	for _, w := range b.words {
+		ret += bits.OnesCount64(w)
+	}
+	return ret
+}
+
+// HammingDistance returns the number of bits that differ between the bitset and 'other'
+// Every bit beyond the end of the shorter bitset counts as differing
+func (b *Bitset) HammingDistance(other *Bitset) int {
+	shorter, longer := b, other
+	if shorter.size > longer.size {
+		shorter, longer = longer, shorter
+	}
+	ret := longer.size - shorter.size
+	for i := 0; i < shorter.size; i += wordSize {
+		width := wordSize
+		if shorter.size-i < width {
+			width = shorter.size - i
+		}
+		x, _ := shorter.Uint64(i, width)
+		y, _ := longer.Uint64(i, width)
+		ret += bits.OnesCount64(x ^ y)
+	}
+	return ret
+}
+
+// Equal reports whether the bitset and 'other' have the same size and bits
+func (b *Bitset) Equal(other *Bitset) bool {
+	if b.size != other.size {
+		return false
+	}
+	for i := range b.words {
+		if b.words[i] != other.words[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Concat returns a new bitset holding the bits of the bitset followed by those of 'other'
+func (b *Bitset) Concat(other *Bitset) Bitset {
+	ret := b.CreateCopy()
+	ret.Append(other)
+	return ret
+}
+
+// Append adds the bits of 'other' to the end of the bitset
+func (b *Bitset) Append(other *Bitset) {
+	index := b.size
+	b.size += other.size
+	for len(b.words) < numWords(b.size) {
+		b.words = append(b.words, 0)
+	}
+	copyRange(b, index, other, 0, other.size)
+}
+
+// NextSet returns the index of the first set bit at or after index 'index'
+// or -1 if there is none
+func (b *Bitset) NextSet(index int) int {
+	if index < 0 {
+		index = 0
+	}
+	if index >= b.size {
+		return -1
+	}
+	word := index / wordSize
+	w := b.words[word] >> uint(index%wordSize) << uint(index%wordSize)
+	for {
+		if w != 0 {
+			return word*wordSize + bits.TrailingZeros64(w)
+		}
+		word++
+		if word == len(b.words) {
+			return -1
+		}
+		w = b.words[word]
+	}
+}
+
+// ForEachSet calls 'f' with the index of each set bit in order until 'f' returns false
+func (b *Bitset) ForEachSet(f func(index int) bool) {
+	for i := b.NextSet(0); i >= 0; i = b.NextSet(i + 1) {
+		if !f(i) {
+			return
+		}
+	}
+}
+
+// key returns a string that is equal for bitsets holding the same bits
+func (b *Bitset) key() string {
+	buf := make([]byte, 8*(len(b.words)+1))
+	binary.LittleEndian.PutUint64(buf, uint64(b.size))
+	for i, w := range b.words {
+		binary.LittleEndian.PutUint64(buf[8*(i+1):], w)
+	}
+	return string(buf)
+}
+
+// ParseBitsToFloat64Arr decodes the float64s held by a bitset created by ParseFloat64ArrToBits,
+// 64 bits per float64
 func ParseBitsToFloat64Arr(b *Bitset) []float64 {
-	bits := b.GetAll()
-	params := make([]float64, len(bits)/8)
-	for i := 0; i < len(bits)/8; i++ {
-		paramBytes := bits[i*8 : i*8+8]
-		params[i] = ByteToFloat64(paramBytes)
+	params := make([]float64, b.GetSize()/wordSize)
+	for i := range params {
+		bits, _ := b.Uint64(i*wordSize, wordSize)
+		params[i] = math.Float64frombits(bits)
 	}
 	return params
 }
 
+// ParseFloat64ArrToBits encodes 'arr' into a bitset, 64 bits per float64, each float64's
+// IEEE 754 bits starting from the least significant one
+// Bitsets used to hold one byte per bit, with a float64 taking 8 'bits'; the bits of
+// the float64 at index 'i' now start at bit 'i * 64' and can be read with Uint64
 func ParseFloat64ArrToBits(arr []float64) *Bitset {
 	b := Bitset{}
-	b.Create(len(arr) * 8)
-	for i := 0; i < len(arr); i++ {
-		var param = arr[i]
-		byteArr := Float64ToByte(param)
-		for idx := 0; idx < 8; idx++ {
-			b.Set(i*8+idx, int(byteArr[idx]))
-		}
+	b.Create(len(arr) * wordSize)
+	for i, param := range arr {
+		b.SetUint64(i*wordSize, wordSize, math.Float64bits(param))
 	}
 	return &b
 }
@@ -1,5 +1,7 @@
 package goga
 
+import "math/rand"
+
 // BitsetCreate - an interface to a bitset create struct
 type BitsetCreate interface {
 	Go() Bitset
@@ -13,3 +15,26 @@ type NullBitsetCreate struct {
 func (ngc *NullBitsetCreate) Go() Bitset {
 	return Bitset{}
 }
+
+// RandomBitsetCreate - creates bitsets of 'Size' bits where each bit is
+// randomly 0 or 1
+type RandomBitsetCreate struct {
+	Size int
+	rand *rand.Rand
+}
+
+// Go returns a bitset of random bits
+func (rbc *RandomBitsetCreate) Go() Bitset {
+	rng := RandOrGlobal(rbc.rand)
+	b := Bitset{}
+	b.Create(rbc.Size)
+	for i := 0; i < rbc.Size; i++ {
+		b.Set(i, rng.Intn(2))
+	}
+	return b
+}
+
+// SetRand sets the random number generator the bits are drawn from
+func (rbc *RandomBitsetCreate) SetRand(rng *rand.Rand) {
+	rbc.rand = rng
+}
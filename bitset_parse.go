@@ -1,15 +1,264 @@
 package goga
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+var (
+	// ErrInvalidField is returned when a field descriptor can't be decoded, such as one that
+	// is too wide or a fixed-point field with an empty range
+	ErrInvalidField = errors.New("invalid field")
+
+	// ErrFormatMismatch is returned when the size of a bitset does not match its format
+	ErrFormatMismatch = errors.New("format does not match bitset size")
+
+	// ErrInvalidValue is returned when a value can't be encoded into its field, either
+	// because it is of the wrong type or because it is out of range
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// FieldKind - how the bits of a field are decoded
+type FieldKind int
+
+const (
+	// FieldUnsigned - an unsigned integer, least significant bit first, decoded as a uint64
+	FieldUnsigned FieldKind = iota
+	// FieldSigned - a two's complement signed integer, decoded as an int64
+	FieldSigned
+	// FieldGray - a Gray-coded unsigned integer, decoded as a uint64
+	// Neighbouring values differ by a single bit, so a single mutation can always
+	// step to them
+	FieldGray
+	// FieldFixedPoint - a real in [Min, Max], evenly spaced over the values of its bits
+	// and decoded as a float64
+	FieldFixedPoint
+	// FieldBool - a single bit, decoded as a bool
+	FieldBool
+	// FieldEnum - an index into 'Values' values, decoded as an int
+	// Bit patterns beyond the last value wrap around
+	FieldEnum
+)
+
+// Field - the descriptor of a field of a bitset, see BitsetParse
+type Field struct {
+	Kind FieldKind
+
+	// Bits is the width of the field, at most 64, it is ignored for FieldBool
+	// and FieldEnum fields
+	Bits int
+
+	// Min and Max are the range of a FieldFixedPoint field
+	Min, Max float64
+
+	// Values is the number of values of a FieldEnum field
+	Values int
+}
+
+// Unsigned returns the descriptor of a 'bits' wide unsigned integer field
+func Unsigned(bits int) Field {
+	return Field{Kind: FieldUnsigned, Bits: bits}
+}
+
+// Signed returns the descriptor of a 'bits' wide two's complement signed integer field
+func Signed(bits int) Field {
+	return Field{Kind: FieldSigned, Bits: bits}
+}
+
+// Gray returns the descriptor of a 'bits' wide Gray-coded unsigned integer field
+func Gray(bits int) Field {
+	return Field{Kind: FieldGray, Bits: bits}
+}
+
+// FixedPoint returns the descriptor of a 'bits' wide real field in ['min', 'max']
+func FixedPoint(bits int, min, max float64) Field {
+	return Field{Kind: FieldFixedPoint, Bits: bits, Min: min, Max: max}
+}
+
+// Bool returns the descriptor of a single bit boolean field
+func Bool() Field {
+	return Field{Kind: FieldBool}
+}
+
+// Enum returns the descriptor of a field that indexes 'values' values
+func Enum(values int) Field {
+	return Field{Kind: FieldEnum, Values: values}
+}
+
+// width returns the number of bits of the field
+func (f Field) width() int {
+	switch f.Kind {
+	case FieldBool:
+		return 1
+	case FieldEnum:
+		return bits.Len(uint(f.Values - 1))
+	}
+	return f.Bits
+}
+
+func (f Field) validate() error {
+	switch f.Kind {
+	case FieldUnsigned, FieldSigned, FieldGray:
+		if f.Bits < 0 || f.Bits > wordSize {
+			return ErrInvalidField
+		}
+	case FieldFixedPoint:
+		if f.Bits < 1 || f.Bits > wordSize || !(f.Min < f.Max) || math.IsInf(f.Max-f.Min, 0) {
+			return ErrInvalidField
+		}
+	case FieldBool:
+	case FieldEnum:
+		if f.Values < 1 {
+			return ErrInvalidField
+		}
+	default:
+		return ErrInvalidField
+	}
+	return nil
+}
+
+// maxUnsigned returns the largest value 'width' bits hold
+func maxUnsigned(width int) uint64 {
+	if width == wordSize {
+		return math.MaxUint64
+	}
+	return (uint64(1) << uint(width)) - 1
+}
+
+func (f Field) decode(u uint64) interface{} {
+	switch f.Kind {
+	case FieldSigned:
+		if f.Bits > 0 && f.Bits < wordSize && u>>uint(f.Bits-1) != 0 {
+			return int64(u) - int64(1)<<uint(f.Bits)
+		}
+		return int64(u)
+	case FieldGray:
+		for shift := uint(1); shift < wordSize; shift <<= 1 {
+			u ^= u >> shift
+		}
+		return u
+	case FieldFixedPoint:
+		return math.Min(f.Max, f.Min+(f.Max-f.Min)*float64(u)/float64(maxUnsigned(f.Bits)))
+	case FieldBool:
+		return u != 0
+	case FieldEnum:
+		return int(u % uint64(f.Values))
+	}
+	return u
+}
+
+func (f Field) encode(v interface{}) (uint64, bool) {
+	width := f.width()
+	switch f.Kind {
+	case FieldUnsigned, FieldGray:
+		u, ok := toUint64(v)
+		if !ok || u > maxUnsigned(width) {
+			return 0, false
+		}
+		if f.Kind == FieldGray {
+			u ^= u >> 1
+		}
+		return u, true
+	case FieldSigned:
+		s, ok := toInt64(v)
+		if !ok || width == 0 && s != 0 ||
+			width > 0 && width < wordSize && (s < -int64(1)<<uint(width-1) || s >= int64(1)<<uint(width-1)) {
+			return 0, false
+		}
+		return uint64(s), true
+	case FieldFixedPoint:
+		r, ok := v.(float64)
+		if !ok || !(r >= f.Min && r <= f.Max) {
+			return 0, false
+		}
+		steps := float64(maxUnsigned(width))
+		u := math.Round((r - f.Min) / (f.Max - f.Min) * steps)
+		if u >= steps {
+			return maxUnsigned(width), true
+		}
+		return uint64(u), true
+	case FieldBool:
+		b, ok := v.(bool)
+		if !ok {
+			return 0, false
+		}
+		if b {
+			return 1, true
+		}
+		return 0, true
+	case FieldEnum:
+		i, ok := toInt64(v)
+		if !ok || i < 0 || i >= int64(f.Values) {
+			return 0, false
+		}
+		return uint64(i), true
+	}
+	return 0, false
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case uint64:
+		return v, true
+	case uint:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint8:
+		return uint64(v), true
+	}
+	if s, ok := toInt64(v); ok && s >= 0 {
+		return uint64(s), true
+	}
+	return 0, false
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint32:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	}
+	return 0, false
+}
+
 // BitsetParse - an interface to an object that is able
 // to parse a bitset into an array of uint64s
+// With SetFields a bitset is split into typed fields, see Field, that Decode returns as
+// values and Encode turns back into a bitset
 type BitsetParse interface {
 	SetFormat([]int)
 	Process(*Bitset) []uint64
+
+	SetFields([]Field) error
+	Decode(*Bitset) ([]interface{}, error)
+	Encode([]interface{}) (Bitset, error)
 }
 
 type bitsetParse struct {
 	expectedBitsetSize int
-	format             []int
+	fields             []Field
 }
 
 // CreateBitsetParse returns an instance of a bitset parser
@@ -17,31 +266,86 @@ func CreateBitsetParse() BitsetParse {
 	return &bitsetParse{}
 }
 
+// SetFormat sets the format to unsigned integer fields of the given widths
 func (bp *bitsetParse) SetFormat(format []int) {
-	bp.expectedBitsetSize = 0
-	for _, i := range format {
-		bp.expectedBitsetSize += i
+	fields := make([]Field, len(format))
+	for i, numBits := range format {
+		fields[i] = Unsigned(numBits)
 	}
-	bp.format = format
+	bp.setFields(fields)
 }
 
+// Process returns the fields of 'bitset' as unsigned integers, whatever their kind,
+// it panics if the size of the bitset does not match the format
 func (bp *bitsetParse) Process(bitset *Bitset) []uint64 {
 	if bitset.GetSize() != bp.expectedBitsetSize {
 		panic("Input format does not match bitset size")
 	}
 
-	ret := make([]uint64, len(bp.format))
+	ret := make([]uint64, len(bp.fields))
 	runningBits := 0
-	for retIndex, numBits := range bp.format {
-		ret[retIndex] = 0
+	for retIndex, f := range bp.fields {
+		ret[retIndex], _ = bitset.Uint64(runningBits, f.width())
+		runningBits += f.width()
+	}
+	return ret
+}
 
-		for i := 0; i < numBits; i++ {
-			if bitset.Get(i+runningBits) == 1 {
-				ret[retIndex] |= (1 << uint(i))
-			}
+// SetFields sets the format to 'fields', it returns an error, and leaves the format
+// as it was, if any of them is invalid
+func (bp *bitsetParse) SetFields(fields []Field) error {
+	for i, f := range fields {
+		if err := f.validate(); err != nil {
+			return fmt.Errorf("field %d: %w", i, err)
 		}
+	}
+	bp.setFields(fields)
+	return nil
+}
+
+func (bp *bitsetParse) setFields(fields []Field) {
+	bp.expectedBitsetSize = 0
+	for _, f := range fields {
+		bp.expectedBitsetSize += f.width()
+	}
+	bp.fields = fields
+}
 
-		runningBits += numBits
+// Decode returns the value of each field of 'bitset', the type of each value depends on
+// the kind of its field, see FieldKind
+func (bp *bitsetParse) Decode(bitset *Bitset) ([]interface{}, error) {
+	if bitset.GetSize() != bp.expectedBitsetSize {
+		return nil, ErrFormatMismatch
 	}
-	return ret
+
+	ret := make([]interface{}, len(bp.fields))
+	runningBits := 0
+	for i, f := range bp.fields {
+		u, _ := bitset.Uint64(runningBits, f.width())
+		ret[i] = f.decode(u)
+		runningBits += f.width()
+	}
+	return ret, nil
+}
+
+// Encode returns a bitset that decodes to 'values'
+// Integer fields accept any integer type, FieldFixedPoint fields a float64 that is rounded
+// to the nearest value the field holds and FieldBool fields a bool
+func (bp *bitsetParse) Encode(values []interface{}) (Bitset, error) {
+	if len(values) != len(bp.fields) {
+		return Bitset{}, ErrFormatMismatch
+	}
+
+	ret := Bitset{}
+	ret.Create(bp.expectedBitsetSize)
+	runningBits := 0
+	for i, f := range bp.fields {
+		u, ok := f.encode(values[i])
+		if !ok {
+			return Bitset{}, fmt.Errorf("field %d: %w", i, ErrInvalidValue)
+		}
+		ret.SetUint64(runningBits, f.width(), u)
+		runningBits += f.width()
+	}
+	return ret, nil
 }
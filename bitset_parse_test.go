@@ -1,6 +1,8 @@
 package goga_test
 
 import (
+	"errors"
+	"math"
 	"math/rand"
 
 	"github.com/tomcraven/goga"
@@ -107,3 +109,148 @@ func (s *BitsetParseSuite) TestShouldProcessMultipleFormat(t *C) {
 	}
 	t.Assert(s.bp.Process(&inputBitset), DeepEquals, []uint64{0, 255})
 }
+
+func (s *BitsetParseSuite) TestShouldDecodeFields(t *C) {
+	fields := []goga.Field{
+		goga.Unsigned(4),
+		goga.Signed(4),
+		goga.Gray(3),
+		goga.FixedPoint(2, -1, 2),
+		goga.Bool(),
+		goga.Enum(3),
+	}
+	t.Assert(s.bp.SetFields(fields), IsNil)
+
+	// Least significant bit first within each field
+	inputBitset := helperBitsetFromString("1010" + "1101" + "011" + "01" + "1" + "11")
+	values, err := s.bp.Decode(&inputBitset)
+	t.Assert(err, IsNil)
+	t.Assert(values, DeepEquals, []interface{}{
+		uint64(5), int64(-5), uint64(4), float64(1), true, int(0),
+	})
+}
+
+func (s *BitsetParseSuite) TestShouldDecodeGrayCodeWithSingleBitSteps(t *C) {
+	t.Assert(s.bp.SetFields([]goga.Field{goga.Gray(8)}), IsNil)
+	for i := 0; i < 255; i++ {
+		b1, err := s.bp.Encode([]interface{}{i})
+		t.Assert(err, IsNil)
+		b2, err := s.bp.Encode([]interface{}{i + 1})
+		t.Assert(err, IsNil)
+		t.Assert(b1.HammingDistance(&b2), Equals, 1)
+
+		values, err := s.bp.Decode(&b1)
+		t.Assert(err, IsNil)
+		t.Assert(values[0], Equals, uint64(i))
+	}
+}
+
+func (s *BitsetParseSuite) TestShouldEncodeAndDecodeRoundTrip(t *C) {
+	t.Assert(s.bp.SetFields([]goga.Field{
+		goga.Unsigned(64),
+		goga.Signed(64),
+		goga.Signed(7),
+		goga.FixedPoint(10, 0, 1),
+		goga.Bool(),
+		goga.Enum(5),
+		goga.Gray(64),
+	}), IsNil)
+
+	values := []interface{}{uint64(1<<63 + 1), int64(-1 << 62), int64(-64), 0.5, false, 4, uint64(12345)}
+	bitset, err := s.bp.Encode(values)
+	t.Assert(err, IsNil)
+	t.Assert(bitset.GetSize(), Equals, 64+64+7+10+1+3+64)
+
+	decoded, err := s.bp.Decode(&bitset)
+	t.Assert(err, IsNil)
+	t.Assert(decoded[:3], DeepEquals, values[:3])
+	t.Assert(decoded[3].(float64)-0.5 < 1.0/1023, IsTrue)
+	t.Assert(decoded[4:], DeepEquals, []interface{}{false, 4, uint64(12345)})
+}
+
+func (s *BitsetParseSuite) TestShouldDecodeFixedPointRange(t *C) {
+	t.Assert(s.bp.SetFields([]goga.Field{goga.FixedPoint(3, -10, 4)}), IsNil)
+
+	b := helperBitsetFromString("000")
+	values, _ := s.bp.Decode(&b)
+	t.Assert(values[0], Equals, -10.0)
+
+	b = helperBitsetFromString("111")
+	values, _ = s.bp.Decode(&b)
+	t.Assert(values[0], Equals, 4.0)
+
+	b, err := s.bp.Encode([]interface{}{4.0})
+	t.Assert(err, IsNil)
+	t.Assert(b.String(), Equals, "111")
+}
+
+func (s *BitsetParseSuite) TestShouldWrapEnumIndex(t *C) {
+	t.Assert(s.bp.SetFields([]goga.Field{goga.Enum(3)}), IsNil)
+	b := helperBitsetFromString("11")
+	values, err := s.bp.Decode(&b)
+	t.Assert(err, IsNil)
+	t.Assert(values[0], Equals, 0)
+}
+
+func (s *BitsetParseSuite) TestShouldRejectInvalidFields(t *C) {
+	t.Assert(s.bp.SetFields([]goga.Field{goga.Unsigned(4)}), IsNil)
+	for _, f := range []goga.Field{
+		goga.Unsigned(65),
+		goga.Signed(-1),
+		goga.FixedPoint(0, 0, 1),
+		goga.FixedPoint(8, 1, 1),
+		goga.Enum(0),
+		{Kind: goga.FieldKind(100)},
+	} {
+		err := s.bp.SetFields([]goga.Field{goga.Bool(), f})
+		t.Assert(errors.Is(err, goga.ErrInvalidField), IsTrue)
+		t.Assert(err, ErrorMatches, "field 1: .*")
+	}
+
+	// The format is kept as it was
+	b := helperBitsetFromString("1111")
+	values, err := s.bp.Decode(&b)
+	t.Assert(err, IsNil)
+	t.Assert(values, DeepEquals, []interface{}{uint64(15)})
+}
+
+func (s *BitsetParseSuite) TestShouldReturnErrorsInsteadOfPanicking(t *C) {
+	t.Assert(s.bp.SetFields([]goga.Field{goga.Unsigned(4), goga.Signed(4)}), IsNil)
+
+	b := helperBitsetFromString("101")
+	_, err := s.bp.Decode(&b)
+	t.Assert(err, Equals, goga.ErrFormatMismatch)
+
+	_, err = s.bp.Encode([]interface{}{1})
+	t.Assert(err, Equals, goga.ErrFormatMismatch)
+
+	for _, values := range [][]interface{}{
+		{16, 0},
+		{-1, 0},
+		{1, 8},
+		{1, -9},
+		{1, "one"},
+		{1.0, 0},
+	} {
+		_, err = s.bp.Encode(values)
+		t.Assert(errors.Is(err, goga.ErrInvalidValue), IsTrue)
+	}
+
+	t.Assert(s.bp.SetFields([]goga.Field{goga.FixedPoint(4, 0, 1), goga.Bool(), goga.Enum(2)}), IsNil)
+	for _, values := range [][]interface{}{
+		{1.5, true, 0},
+		{math.NaN(), true, 0},
+		{1, true, 0},
+		{0.5, 1, 0},
+		{0.5, true, 2},
+	} {
+		_, err = s.bp.Encode(values)
+		t.Assert(errors.Is(err, goga.ErrInvalidValue), IsTrue)
+	}
+}
+
+func (s *BitsetParseSuite) TestShouldProcessFieldsAsUnsigned(t *C) {
+	t.Assert(s.bp.SetFields([]goga.Field{goga.Signed(4), goga.Bool()}), IsNil)
+	b := helperBitsetFromString("11111")
+	t.Assert(s.bp.Process(&b), DeepEquals, []uint64{15, 1})
+}
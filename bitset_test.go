@@ -97,6 +97,231 @@ func (s *BitsetSuite) TestShouldGetAll(t *C) {
 	t.Assert(len(bits), Equals, kBitsetSize)
 
 	for i := 0; i < kBitsetSize; i++ {
-		t.Assert(bits[i], Equals, 1)
+		t.Assert(bits[i], Equals, byte(1))
 	}
 }
+
+func (s *BitsetSuite) TestShouldSetAndGetAcrossWords(t *C) {
+	s.bitset.Create(200)
+	for i := 0; i < 200; i += 3 {
+		t.Assert(s.bitset.Set(i, 1), IsTrue)
+	}
+	for i := 0; i < 200; i++ {
+		expected := 0
+		if i%3 == 0 {
+			expected = 1
+		}
+		t.Assert(s.bitset.Get(i), Equals, expected, Commentf("Index [%v]", i))
+	}
+	t.Assert(s.bitset.Get(-1), Equals, -1)
+	t.Assert(s.bitset.Set(-1, 1), IsFalse)
+	t.Assert(s.bitset.Get(200), Equals, -1)
+}
+
+func (s *BitsetSuite) TestShouldSetAndGetUint64(t *C) {
+	s.bitset.Create(150)
+	t.Assert(s.bitset.SetUint64(60, 64, 0xdeadbeefcafef00d), IsTrue)
+	value, ok := s.bitset.Uint64(60, 64)
+	t.Assert(ok, IsTrue)
+	t.Assert(value, Equals, uint64(0xdeadbeefcafef00d))
+
+	// Bits are least significant first
+	t.Assert(s.bitset.Get(60), Equals, 1)
+	t.Assert(s.bitset.Get(61), Equals, 0)
+	value, _ = s.bitset.Uint64(60, 4)
+	t.Assert(value, Equals, uint64(0xd))
+
+	// Only 'width' bits are written
+	t.Assert(s.bitset.SetUint64(0, 3, 0xff), IsTrue)
+	value, _ = s.bitset.Uint64(0, 8)
+	t.Assert(value, Equals, uint64(7))
+
+	_, ok = s.bitset.Uint64(100, 64)
+	t.Assert(ok, IsFalse)
+	t.Assert(s.bitset.SetUint64(100, 64, 1), IsFalse)
+	t.Assert(s.bitset.SetUint64(0, 65, 1), IsFalse)
+}
+
+func (s *BitsetSuite) TestShouldCreateIndependentCopy(t *C) {
+	s.bitset.Create(100)
+	s.bitset.Set(70, 1)
+	copied := s.bitset.CreateCopy()
+	copied.Set(70, 0)
+	copied.Set(5, 1)
+	t.Assert(s.bitset.Get(70), Equals, 1)
+	t.Assert(s.bitset.Get(5), Equals, 0)
+	t.Assert(copied.GetSize(), Equals, 100)
+}
+
+func (s *BitsetSuite) TestShouldSliceAcrossWords(t *C) {
+	s.bitset.Create(200)
+	for i := 0; i < 200; i += 2 {
+		s.bitset.Set(i, 1)
+	}
+	slice := s.bitset.Slice(61, 90)
+	t.Assert(slice.GetSize(), Equals, 90)
+	for i := 0; i < 90; i++ {
+		t.Assert(slice.Get(i), Equals, i%2)
+	}
+}
+
+func (s *BitsetSuite) TestShouldKeepOneBytePerBitCompatibility(t *C) {
+	s.bitset.Create(70)
+	s.bitset.SetAll(1)
+	s.bitset.Set(3, 0)
+	bits := s.bitset.GetAll()
+	t.Assert(bits, HasLen, 70)
+	t.Assert(bits[3], Equals, byte(0))
+	t.Assert(bits[69], Equals, byte(1))
+
+	restored := goga.Bitset{}
+	restored.SetAllArr(bits)
+	t.Assert(restored.GetSize(), Equals, 70)
+	t.Assert(goga.NewGenome(restored).Key(), Equals, goga.NewGenome(*s.bitset).Key())
+}
+
+func (s *BitsetSuite) TestShouldRoundTripFloat64s(t *C) {
+	params := []float64{-1.5, 0, 3.25e10, 1. / 3}
+	bits := goga.ParseFloat64ArrToBits(params)
+	t.Assert(bits.GetSize(), Equals, len(params)*64)
+	t.Assert(goga.ParseBitsToFloat64Arr(bits), DeepEquals, params)
+}
+
+func (s *BitsetSuite) TestShouldKeyOnSizeAndBits(t *C) {
+	b1, b2, b3 := goga.Bitset{}, goga.Bitset{}, goga.Bitset{}
+	b1.Create(10)
+	b2.Create(10)
+	b3.Create(11)
+	g1, g2, g3 := goga.NewGenome(b1), goga.NewGenome(b2), goga.NewGenome(b3)
+	t.Assert(g1.Key(), Equals, g2.Key())
+	t.Assert(g1.Key(), Not(Equals), g3.Key())
+
+	b2.Set(9, 1)
+	t.Assert(g1.Key(), Not(Equals), g2.Key())
+}
+
+func helperBitsetFromString(bits string) goga.Bitset {
+	b := goga.Bitset{}
+	b.Create(len(bits))
+	for i, c := range bits {
+		b.Set(i, int(c-'0'))
+	}
+	return b
+}
+
+func helperBitsetToString(b goga.Bitset) string {
+	ret := ""
+	for i := 0; i < b.GetSize(); i++ {
+		ret += string(rune('0' + b.Get(i)))
+	}
+	return ret
+}
+
+func (s *BitsetSuite) TestShouldCombineBitwise(t *C) {
+	b1, b2 := helperBitsetFromString("1100"), helperBitsetFromString("101011")
+	t.Assert(helperBitsetToString(b1.And(&b2)), Equals, "100000")
+	t.Assert(helperBitsetToString(b1.Or(&b2)), Equals, "111011")
+	t.Assert(helperBitsetToString(b1.Xor(&b2)), Equals, "011011")
+	t.Assert(helperBitsetToString(b2.Not()), Equals, "010100")
+
+	// Inputs are left untouched
+	t.Assert(helperBitsetToString(b1), Equals, "1100")
+	t.Assert(helperBitsetToString(b2), Equals, "101011")
+}
+
+func (s *BitsetSuite) TestShouldKeepBitsBeyondSizeClear(t *C) {
+	s.bitset.Create(70)
+	not := s.bitset.Not()
+	t.Assert(not.PopCount(), Equals, 70)
+
+	s.bitset.SetAll(1)
+	t.Assert(s.bitset.PopCount(), Equals, 70)
+	t.Assert(s.bitset.Flip(0, 70), IsTrue)
+	t.Assert(s.bitset.PopCount(), Equals, 0)
+}
+
+func (s *BitsetSuite) TestShouldFlipRange(t *C) {
+	s.bitset.Create(200)
+	t.Assert(s.bitset.Flip(50, 150), IsTrue)
+	t.Assert(s.bitset.PopCount(), Equals, 100)
+	t.Assert(s.bitset.Get(49), Equals, 0)
+	t.Assert(s.bitset.Get(50), Equals, 1)
+	t.Assert(s.bitset.Get(149), Equals, 1)
+	t.Assert(s.bitset.Get(150), Equals, 0)
+
+	t.Assert(s.bitset.Flip(150, 201), IsFalse)
+	t.Assert(s.bitset.Flip(-1, 10), IsFalse)
+	t.Assert(s.bitset.Flip(10, 5), IsFalse)
+	t.Assert(s.bitset.PopCount(), Equals, 100)
+}
+
+func (s *BitsetSuite) TestShouldMeasureHammingDistance(t *C) {
+	b1, b2 := helperBitsetFromString("1100"), helperBitsetFromString("1010")
+	t.Assert(b1.HammingDistance(&b2), Equals, 2)
+	t.Assert(b1.HammingDistance(&b1), Equals, 0)
+
+	// Missing bits always differ
+	b3 := helperBitsetFromString("110000")
+	t.Assert(b1.HammingDistance(&b3), Equals, 2)
+	t.Assert(b3.HammingDistance(&b1), Equals, 2)
+
+	large1, large2 := goga.Bitset{}, goga.Bitset{}
+	large1.Create(1000)
+	large2.Create(1000)
+	large2.Flip(100, 900)
+	t.Assert(large1.HammingDistance(&large2), Equals, 800)
+}
+
+func (s *BitsetSuite) TestShouldCompareBitsets(t *C) {
+	b1, b2, b3 := helperBitsetFromString("1100"), helperBitsetFromString("1100"), helperBitsetFromString("11000")
+	t.Assert(b1.Equal(&b2), IsTrue)
+	t.Assert(b1.Equal(&b3), IsFalse)
+	b2.Set(3, 1)
+	t.Assert(b1.Equal(&b2), IsFalse)
+}
+
+func (s *BitsetSuite) TestShouldConcatAndAppend(t *C) {
+	b1 := helperBitsetFromString("101")
+	b2 := goga.Bitset{}
+	b2.Create(100)
+	b2.SetAll(1)
+
+	concat := b1.Concat(&b2)
+	t.Assert(concat.GetSize(), Equals, 103)
+	t.Assert(concat.PopCount(), Equals, 102)
+	t.Assert(helperBitsetToString(concat.Slice(0, 5)), Equals, "10111")
+	t.Assert(b1.GetSize(), Equals, 3)
+
+	b1.Append(&b2)
+	t.Assert(b1.Equal(&concat), IsTrue)
+	b1.Append(&goga.Bitset{})
+	t.Assert(b1.Equal(&concat), IsTrue)
+}
+
+func (s *BitsetSuite) TestShouldIterateOverSetBits(t *C) {
+	s.bitset.Create(300)
+	expected := []int{0, 63, 64, 65, 128, 299}
+	for _, i := range expected {
+		s.bitset.Set(i, 1)
+	}
+
+	var visited []int
+	s.bitset.ForEachSet(func(index int) bool {
+		visited = append(visited, index)
+		return true
+	})
+	t.Assert(visited, DeepEquals, expected)
+
+	visited = nil
+	s.bitset.ForEachSet(func(index int) bool {
+		visited = append(visited, index)
+		return len(visited) < 2
+	})
+	t.Assert(visited, DeepEquals, expected[:2])
+
+	t.Assert(s.bitset.NextSet(1), Equals, 63)
+	t.Assert(s.bitset.NextSet(129), Equals, 299)
+	t.Assert(s.bitset.NextSet(300), Equals, -1)
+	t.Assert(s.bitset.NextSet(-5), Equals, 0)
+	t.Assert((&goga.Bitset{}).NextSet(0), Equals, -1)
+}
@@ -0,0 +1,74 @@
+package goga
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSimulationTimeout is the error of a simulation that did not finish within the
+// timeout set by SimulationTimeout
+var ErrSimulationTimeout = errors.New("simulation timed out")
+
+// MaxEvaluations stops a run once 'n' genomes have been passed to the simulator, 0 means no limit
+// No genome is handed to a simulation worker once the budget is spent, the genomes already being
+// simulated are waited for and a generation left incomplete is not recorded in the result
+func MaxEvaluations(n int) Option {
+	return func(o *Options) {
+		o.MaxEvaluations = n
+	}
+}
+
+// MaxDuration stops a run once it has taken 'd' of wall-clock time, including the time taken
+// before a checkpoint it was restored from, 0 means no limit
+// The run stops as it would when the context of SimulateContext is done, but with the
+// TerminationTimeBudget reason and no error
+func MaxDuration(d time.Duration) Option {
+	return func(o *Options) {
+		o.MaxDuration = d
+	}
+}
+
+// SimulationTimeout gives each simulation 'd' to finish, a genome whose simulation takes longer
+// is given the fitness 'penalty' and the run carries on without waiting for it, 0 means no limit
+// The simulation's context, see ContextSimulator and ErrorSimulator, is done once the timeout
+// passes. As a simulation that ignores its context may carry on running, the simulator is passed
+// a copy of the genome whose fitness, origin and objectives are copied back if it finishes in time
+// Timed out simulations are neither retried nor handled by the FailurePolicy
+func SimulationTimeout(d time.Duration, penalty float64) Option {
+	return func(o *Options) {
+		o.SimulationTimeout = d
+		o.TimeoutPenalty = penalty
+	}
+}
+
+// withTimeBudget returns 'ctx' with a deadline once 'budget' less the time already 'spent'
+// has passed, if there is a budget
+func withTimeBudget(ctx context.Context, budget, spent time.Duration) (context.Context, context.CancelFunc) {
+	if budget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, budget-spent)
+}
+
+// budgetReason returns the reason, and error, a run stopped for, replacing the context of
+// 'ctx' being done with the time budget running out when it is only the time budget's
+// context, derived from 'ctx', that is done
+func budgetReason(ctx context.Context, reason TerminationReason, err error) (TerminationReason, error) {
+	if reason == TerminationContext && ctx.Err() == nil {
+		return TerminationTimeBudget, nil
+	}
+	return reason, err
+}
+
+// countFailures returns the number of 'failures' that are failed and timed out simulations
+func countFailures(failures map[Genome]error) (failed, timedOut int) {
+	for _, err := range failures {
+		if err == ErrSimulationTimeout {
+			timedOut++
+		} else {
+			failed++
+		}
+	}
+	return failed, timedOut
+}
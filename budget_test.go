@@ -0,0 +1,104 @@
+package goga_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type BudgetSuite struct {
+}
+
+var _ = Suite(&BudgetSuite{})
+
+// MySimulatorSlow never finishes simulating genomes whose first bit is set, unless it is
+// stopped by its context, and takes 'Delay' to score the number of bits set of any other
+type MySimulatorSlow struct {
+	MySimulatorBitCount
+	Delay time.Duration
+}
+
+func (ms *MySimulatorSlow) Simulate(goga.Genome) {
+	panic("Simulate should not be called on a ContextSimulator")
+}
+func (ms *MySimulatorSlow) SimulateContext(ctx context.Context, g goga.Genome) {
+	if g.GetBits().Get(0) == 1 {
+		<-ctx.Done()
+		return
+	}
+	time.Sleep(ms.Delay)
+	ms.MySimulatorBitCount.Simulate(g)
+}
+
+func (s *BudgetSuite) TestShouldStopOnceEvaluationsAreSpent(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(1), goga.MaxEvaluations(50), goga.ParallelSimulations(kNumThreads))
+	genAlgo.Simulator = &MySimulatorBitCount{}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationEvaluationBudget)
+	t.Assert(result.Err, IsNil)
+	t.Assert(result.Evaluations, Equals, 50)
+	// The second generation needs 39 evaluations so is left incomplete
+	t.Assert(result.Generations, Equals, 1)
+
+	genAlgo = helperGenerateSeededGeneticAlgorithm(goga.Seed(1), goga.MaxEvaluations(20+39))
+	genAlgo.Simulator = &MySimulatorBitCount{}
+	result = genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationEvaluationBudget)
+	t.Assert(result.Evaluations, Equals, 20+39)
+	t.Assert(result.Generations, Equals, 2)
+}
+
+func (s *BudgetSuite) TestShouldCountEvaluationsOfEveryIsland(t *C) {
+	islands := goga.NewIslands(
+		helperGenerateIsland(&MySimulatorBitCount{}),
+		helperGenerateIsland(&MySimulatorBitCount{}),
+	)
+	islands.Init(goga.ParallelSimulations(kNumThreads), goga.MaxEvaluations(25))
+	result := islands.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationEvaluationBudget)
+	t.Assert(result.Evaluations, Equals, 25)
+	t.Assert(result.Generations, Equals, 1)
+}
+
+func (s *BudgetSuite) TestShouldStopOnceTimeIsSpent(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(2), goga.MaxDuration(50*time.Millisecond),
+		goga.SimulationTimeout(time.Millisecond, -1))
+	genAlgo.Simulator = &MySimulatorSlow{Delay: time.Millisecond}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationTimeBudget)
+	t.Assert(result.Err, IsNil)
+	t.Assert(result.Duration >= 50*time.Millisecond, Equals, true)
+	t.Assert(result.Duration < 5*time.Second, Equals, true)
+
+	islands := goga.NewIslands(helperGenerateIsland(&MySimulatorSlow{Delay: time.Millisecond}))
+	islands.Init(goga.MaxDuration(20 * time.Millisecond))
+	t.Assert(islands.Simulate().Reason, Equals, goga.TerminationTimeBudget)
+}
+
+func (s *BudgetSuite) TestShouldPenaliseTimedOutSimulations(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(3), goga.MaxGenerations(2), goga.RandomRatio(0),
+		goga.ParallelSimulations(kNumThreads), goga.SimulationTimeout(10*time.Millisecond, -1))
+	genAlgo.Simulator = &MySimulatorSlow{}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Timeouts > 0, Equals, true)
+	t.Assert(result.Failures, Equals, 0)
+	for _, g := range genAlgo.GetPopulation() {
+		if g.GetBits().Get(0) == 1 {
+			t.Assert(g.GetFitness(), Equals, -1.)
+		} else {
+			t.Assert(g.GetFitness() >= 0, Equals, true)
+		}
+	}
+}
+
+func (s *BudgetSuite) TestShouldStillHonourContext(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(4), goga.MaxDuration(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := genAlgo.SimulateContext(ctx)
+	t.Assert(err, Equals, context.Canceled)
+	t.Assert(result.Reason, Equals, goga.TerminationContext)
+}
@@ -0,0 +1,260 @@
+package goga
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	checkpointMagic = "GOGACKPT"
+
+	// CheckpointVersion is the version of the checkpoint format written by WriteCheckpoint
+	// and the only version RestoreCheckpoint reads
+	CheckpointVersion = 1
+)
+
+var (
+	// ErrGenerationInProgress is returned when a checkpoint is written while genomes
+	// of a generation are still being asked for or told
+	ErrGenerationInProgress = errors.New("a generation is in progress")
+
+	// ErrNotACheckpoint is returned when restoring from data that was not written by WriteCheckpoint
+	ErrNotACheckpoint = errors.New("not a goga checkpoint")
+
+	// ErrUnsupportedGenome is returned when a checkpoint is written of a population holding genomes
+	// that can not be restored, any other than those created by NewGenome, NewRealGenome,
+	// NewPermutationGenome and NewIntegerGenome
+	ErrUnsupportedGenome = errors.New("genome can not be checkpointed")
+
+	// ErrUnsupportedRandSource is returned when a checkpoint is written or restored by an algorithm
+	// drawing its random numbers from a RandSource other than a Source, whose state can not be saved
+	ErrUnsupportedRandSource = errors.New("random number source can not be checkpointed")
+)
+
+// checkpointGenome - a genome as stored in a checkpoint, one byte per bit or, for
+// real-valued, permutation and integer genomes, its values or permutation
+type checkpointGenome struct {
+	Bits        []byte
+	Fitness     float64
+	Origin      float64
+	Objectives  []float64
+	Values      []float64
+	Permutation []int
+	Integers    []int
+}
+
+// checkpointFitness returns 'g' as stored in the duplicate genome cache of a checkpoint,
+// without its genes
+func checkpointFitness(g Genome) checkpointGenome {
+	f := storedFitness(g)
+	return checkpointGenome{Fitness: f.Fitness, Origin: f.Origin, Objectives: f.Objectives}
+}
+
+// checkpointData - everything needed to carry on a run from the end of a generation
+type checkpointData struct {
+	Generation          int
+	Population          []checkpointGenome
+	Elite               int
+	LRUSize             int
+	LRUKeys             []string
+	Evaluations         int
+	Duration            time.Duration
+	EliteFitnessHistory []float64
+	BestFitness         float64
+	StagnantGenerations int
+	HasRandState        bool
+	RandState           uint64
+	CachedFitness       map[string]checkpointGenome
+	CacheHits           int
+	CacheMisses         int
+	Failures            int
+	Timeouts            int
+}
+
+// WriteCheckpoint writes the state of the algorithm at the end of the last fully simulated
+// generation to 'w' so that the run can later be carried on with RestoreCheckpoint
+// The population, the fitness, origin and objectives of each genome, the generation counter,
+// the keys held in the duplicate genome cache, along with the fitness of the genomes it holds
+// when reusing fitness, see DuplicateGenomes, the counters of the current run and the state of
+// the random number generator, when it is a Source, are written
+// Populations holding other genomes than those created by NewGenome, NewRealGenome,
+// NewPermutationGenome and NewIntegerGenome, and algorithms given a RandSource that is not
+// a Source, can not be checkpointed
+func (ga *GeneticAlgorithm) WriteCheckpoint(w io.Writer) error {
+	if ga.generationStarted {
+		return ErrGenerationInProgress
+	}
+	source, hasRandState := ga.source.(*Source)
+	if ga.source != nil && !hasRandState {
+		return ErrUnsupportedRandSource
+	}
+
+	c := checkpointData{
+		Generation: ga.generation,
+		Population: make([]checkpointGenome, len(ga.population)),
+		Elite:      -1,
+		LRUSize:    ga.LRUSize,
+	}
+	for i, g := range ga.population {
+		c.Population[i] = checkpointGenome{
+			Fitness: g.GetFitness(),
+			Origin:  g.GetOrigin(),
+		}
+		switch cg := g.(type) {
+		case *realGenome:
+			c.Population[i].Values = cg.GetValues()
+		case *permutationGenome:
+			c.Population[i].Permutation = cg.GetPermutation()
+		case *integerGenome:
+			c.Population[i].Integers = cg.GetValues()
+		case *genome:
+			c.Population[i].Bits = cg.GetBits().GetAll()
+		default:
+			return ErrUnsupportedGenome
+		}
+		if mog, ok := g.(MultiObjectiveGenome); ok {
+			c.Population[i].Objectives = mog.GetObjectives()
+		}
+		if g == ga.elite {
+			c.Elite = i
+		}
+	}
+	if ga.lru != nil {
+		c.CachedFitness = make(map[string]checkpointGenome)
+		for _, k := range ga.lru.keys() {
+			key, ok := k.(string)
+			if !ok {
+				continue
+			}
+			c.LRUKeys = append(c.LRUKeys, key)
+			if g, ok := ga.lru.peek(key); ok && g != nil {
+				c.CachedFitness[key] = checkpointFitness(g.(Genome))
+			}
+		}
+	}
+	c.CacheHits, c.CacheMisses = ga.CacheStats()
+	if hasRandState {
+		c.HasRandState = true
+		c.RandState = source.State()
+	}
+	if ga.result != nil {
+		c.Evaluations = ga.result.Evaluations
+		c.Duration = ga.result.Duration
+		c.EliteFitnessHistory = ga.result.EliteFitnessHistory
+		c.BestFitness = ga.result.bestFitness
+		c.StagnantGenerations = ga.result.stagnantGenerations
+		c.Failures = ga.result.Failures
+		c.Timeouts = ga.result.Timeouts
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(checkpointMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, uint32(CheckpointVersion)); err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(bw).Encode(&c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// RestoreCheckpoint replaces the state of the algorithm with a checkpoint read from 'r'
+// The next call to Simulate, SimulateUntil or SimulateContext carries on the run from the
+// end of the checkpointed generation rather than starting again, as does Ask
+// Init should be called, with the same options as the checkpointed run, before restoring
+// A checkpoint holding the state of a Source can only be restored by an algorithm given a Source
+func (ga *GeneticAlgorithm) RestoreCheckpoint(r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(checkpointMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != checkpointMagic {
+		return ErrNotACheckpoint
+	}
+	var version uint32
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return ErrNotACheckpoint
+	}
+
+	if version != CheckpointVersion {
+		return fmt.Errorf("unsupported checkpoint version %v", version)
+	}
+	var c checkpointData
+	if err := gob.NewDecoder(br).Decode(&c); err != nil {
+		return err
+	}
+	source, ok := ga.source.(*Source)
+	if c.HasRandState && !ok {
+		return ErrUnsupportedRandSource
+	}
+
+	ga.reset()
+	ga.population = make([]Genome, len(c.Population))
+	for i, cg := range c.Population {
+		var g Genome
+		if cg.Values != nil {
+			g = NewRealGenome(cg.Values)
+		} else if cg.Permutation != nil {
+			g = NewPermutationGenome(cg.Permutation)
+		} else if cg.Integers != nil {
+			g = NewIntegerGenome(cg.Integers)
+		} else {
+			b := Bitset{}
+			b.SetAllArr(cg.Bits)
+			g = NewGenome(b)
+		}
+		g.SetFitness(cg.Fitness)
+		g.SetOrigin(cg.Origin)
+		if cg.Objectives != nil {
+			g.(MultiObjectiveGenome).SetObjectives(cg.Objectives)
+		}
+		ga.population[i] = g
+	}
+	ga.populationSize = len(ga.population)
+	ga.rescore()
+	if c.Elite >= 0 && c.Elite < len(ga.population) {
+		ga.elite = ga.population[c.Elite]
+		ga.Mater.OnElite(ga.elite)
+	}
+	ga.generation = c.Generation
+	ga.LRUSize = c.LRUSize
+	ga.lru = New(ga.LRUSize)
+	for _, k := range c.LRUKeys {
+		cg, ok := c.CachedFitness[k]
+		if !ok {
+			ga.lru.Add(k, nil)
+			continue
+		}
+		// Only the fitness of a cached genome is needed to reuse it
+		ga.lru.Add(k, fitnessGenome(StoredFitness{Fitness: cg.Fitness, Origin: cg.Origin, Objectives: cg.Objectives}))
+	}
+	ga.cacheHits = c.CacheHits
+	ga.cacheMisses = c.CacheMisses
+
+	ga.result = &Result{
+		Elite:               ga.elite,
+		Generations:         len(c.EliteFitnessHistory),
+		Evaluations:         c.Evaluations,
+		Duration:            c.Duration,
+		EliteFitnessHistory: c.EliteFitnessHistory,
+		bestFitness:         c.BestFitness,
+		stagnantGenerations: c.StagnantGenerations,
+		CacheHits:           c.CacheHits,
+		CacheMisses:         c.CacheMisses,
+		Failures:            c.Failures,
+		Timeouts:            c.Timeouts,
+	}
+	if ga.multiObjective {
+		ga.result.ParetoFront = ga.ParetoFront()
+	}
+	if c.HasRandState {
+		source.SetState(c.RandState)
+	}
+	ga.resumed = true
+	return nil
+}
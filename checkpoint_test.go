@@ -0,0 +1,155 @@
+package goga_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type CheckpointSuite struct {
+}
+
+var _ = Suite(&CheckpointSuite{})
+
+type MySimulatorBitCount struct {
+}
+
+func (ms *MySimulatorBitCount) Simulate(g goga.Genome) {
+	bits := g.GetBits()
+	fitness := 0
+	for i := 0; i < bits.GetSize(); i++ {
+		fitness += bits.Get(i)
+	}
+	g.SetFitness(float64(fitness))
+}
+func (ms *MySimulatorBitCount) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorBitCount) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorBitCount) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+func helperGenerateCheckpointedGeneticAlgorithm(opt ...goga.Option) goga.GeneticAlgorithm {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorBitCount{}
+	genAlgo.Init(append([]goga.Option{goga.PopulationSize(10), goga.ParallelSimulations(kNumThreads)}, opt...)...)
+	return genAlgo
+}
+
+func (s *CheckpointSuite) TestShouldRestorePopulation(t *C) {
+	genAlgo := helperGenerateCheckpointedGeneticAlgorithm(goga.MaxGenerations(5))
+	genAlgo.Simulate()
+
+	buffer := bytes.Buffer{}
+	t.Assert(genAlgo.WriteCheckpoint(&buffer), IsNil)
+
+	restored := helperGenerateCheckpointedGeneticAlgorithm()
+	t.Assert(restored.RestoreCheckpoint(&buffer), IsNil)
+	t.Assert(restored.Generation(), Equals, genAlgo.Generation())
+	t.Assert(restored.Elite().Key(), Equals, genAlgo.Elite().Key())
+
+	expected, obtained := genAlgo.GetPopulation(), restored.GetPopulation()
+	t.Assert(obtained, HasLen, len(expected))
+	for i := range expected {
+		t.Assert(obtained[i].Key(), Equals, expected[i].Key())
+		t.Assert(obtained[i].GetFitness(), Equals, expected[i].GetFitness())
+		t.Assert(obtained[i].GetOrigin(), Equals, expected[i].GetOrigin())
+	}
+}
+
+func (s *CheckpointSuite) TestShouldCarryOnFromCheckpoint(t *C) {
+	checkpoints := map[int]*bytes.Buffer{}
+	create := func(generation int) (io.Writer, error) {
+		checkpoints[generation] = &bytes.Buffer{}
+		return checkpoints[generation], nil
+	}
+	genAlgo := helperGenerateCheckpointedGeneticAlgorithm(goga.MaxGenerations(6), goga.CheckpointEvery(3, create))
+	first := genAlgo.Simulate()
+	t.Assert(checkpoints, HasLen, 2)
+
+	restored := helperGenerateCheckpointedGeneticAlgorithm(goga.MaxGenerations(10))
+	t.Assert(restored.RestoreCheckpoint(checkpoints[6]), IsNil)
+	result := restored.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Generations, Equals, 10)
+	t.Assert(result.EliteFitnessHistory[:6], DeepEquals, first.EliteFitnessHistory)
+	t.Assert(result.Evaluations > first.Evaluations, IsTrue)
+
+	// The carried on run never goes backwards
+	for i := 1; i < len(result.EliteFitnessHistory); i++ {
+		t.Assert(result.EliteFitnessHistory[i] >= result.EliteFitnessHistory[i-1], IsTrue)
+	}
+}
+
+func (s *CheckpointSuite) TestShouldStopWhenCheckpointFails(t *C) {
+	failure := errors.New("disk full")
+	create := func(generation int) (io.Writer, error) {
+		return nil, failure
+	}
+	genAlgo := helperGenerateCheckpointedGeneticAlgorithm(goga.CheckpointEvery(2, create))
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationError)
+	t.Assert(result.Err, Equals, failure)
+	t.Assert(result.Generations, Equals, 2)
+}
+
+func (s *CheckpointSuite) TestShouldNotCheckpointMidGeneration(t *C) {
+	genAlgo := helperGenerateCheckpointedGeneticAlgorithm()
+	genAlgo.Ask(1)
+	t.Assert(genAlgo.WriteCheckpoint(&bytes.Buffer{}), Equals, goga.ErrGenerationInProgress)
+}
+
+func (s *CheckpointSuite) TestShouldRejectInvalidCheckpoints(t *C) {
+	genAlgo := helperGenerateCheckpointedGeneticAlgorithm()
+	t.Assert(genAlgo.RestoreCheckpoint(bytes.NewBufferString("not a checkpoint")), Equals, goga.ErrNotACheckpoint)
+	t.Assert(genAlgo.RestoreCheckpoint(&bytes.Buffer{}), Equals, goga.ErrNotACheckpoint)
+
+	future := bytes.NewBufferString("GOGACKPT")
+	binary.Write(future, binary.LittleEndian, uint32(goga.CheckpointVersion+1))
+	t.Assert(genAlgo.RestoreCheckpoint(future), ErrorMatches, "unsupported checkpoint version .*")
+}
+
+type MyCustomGenome struct {
+	goga.Genome
+}
+
+type MyCustomGenomeMater struct {
+	goga.NullMater
+}
+
+func (m *MyCustomGenomeMater) Go(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	c1, c2 := m.NullMater.Go(g1, g2)
+	return &MyCustomGenome{c1}, &MyCustomGenome{c2}
+}
+
+func (s *CheckpointSuite) TestShouldNotCheckpointCustomGenomes(t *C) {
+	genAlgo := helperGenerateCheckpointedGeneticAlgorithm()
+	genAlgo.Mater = &MyCustomGenomeMater{}
+	genAlgo.Init(goga.PopulationSize(10), goga.MaxGenerations(2), goga.RandomRatio(0))
+	genAlgo.Simulate()
+	t.Assert(genAlgo.WriteCheckpoint(&bytes.Buffer{}), Equals, goga.ErrUnsupportedGenome)
+}
+
+type MyLockedSource struct {
+	rand.Source
+}
+
+func (s *CheckpointSuite) TestShouldNotCheckpointOtherRandSources(t *C) {
+	genAlgo := helperGenerateCheckpointedGeneticAlgorithm(goga.RandSource(&MyLockedSource{rand.NewSource(1)}), goga.MaxGenerations(2))
+	genAlgo.Simulate()
+	t.Assert(genAlgo.WriteCheckpoint(&bytes.Buffer{}), Equals, goga.ErrUnsupportedRandSource)
+
+	seeded := helperGenerateCheckpointedGeneticAlgorithm(goga.Seed(1), goga.MaxGenerations(2))
+	seeded.Simulate()
+	buffer := bytes.Buffer{}
+	t.Assert(seeded.WriteCheckpoint(&buffer), IsNil)
+	t.Assert(genAlgo.RestoreCheckpoint(&buffer), Equals, goga.ErrUnsupportedRandSource)
+	t.Assert(genAlgo.Generation(), Equals, 2)
+}
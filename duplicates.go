@@ -0,0 +1,74 @@
+package goga
+
+// DuplicatePolicy - what the genetic algorithm does with a bred genome whose key is already
+// in its duplicate genome cache, see LRUSize
+type DuplicatePolicy int
+
+const (
+	// RejectDuplicates - the genome is discarded and another is bred in its place, the default
+	RejectDuplicates DuplicatePolicy = iota
+	// ReuseFitness - the genome joins the offspring with the fitness, origin and objectives of
+	// the genome it duplicates, which are copied once the generation has been simulated, and
+	// is not passed to the simulator
+	ReuseFitness
+)
+
+// DuplicateGenomes sets what happens to bred genomes that duplicate one seen before, see DuplicatePolicy
+// With ReuseFitness the cache holds on to each genome it has a key for, rather than only its key,
+// so that its fitness can be copied, and the initial population and immigrants are cached too
+func DuplicateGenomes(p DuplicatePolicy) Option {
+	return func(o *Options) {
+		o.DuplicateGenomes = p
+	}
+}
+
+// MaxDuplicateRejections bounds the number of duplicate genomes rejected in each generation,
+// once 'n' have been rejected any further duplicates are accepted and simulated again, which
+// stops the breeding of a generation from looping forever when the Mater keeps producing
+// genomes that have already been seen, such as when the search space is smaller than the cache
+// The default is 1000, 0 accepts every duplicate
+func MaxDuplicateRejections(n int) Option {
+	return func(o *Options) {
+		o.MaxDuplicateRejections = n
+	}
+}
+
+// reusedGenome - an offspring that duplicates 'source' and is given its fitness
+// at the end of the generation rather than being simulated
+type reusedGenome struct {
+	genome Genome
+	source Genome
+}
+
+// remember adds 'key' to the duplicate genome cache, along with the genome 'g' it is the key
+// of when its fitness may be reused
+func (ga *GeneticAlgorithm) remember(key string, g Genome) {
+	if ga.duplicates == ReuseFitness {
+		ga.lru.Add(key, g)
+	} else {
+		ga.lru.Add(key, nil)
+	}
+}
+
+// reuse adds 'g' to the population being bred without simulating it, it is given the
+// fitness of 'source' at the end of the generation
+func (ga *GeneticAlgorithm) reuse(g Genome, source Genome) {
+	ga.reused = append(ga.reused, reusedGenome{genome: g, source: source})
+	ga.offspring[ga.filled] = g
+	ga.filled++
+}
+
+// reuseFitness copies the fitness, origin and objectives of the genome each reused
+// offspring duplicates on to it
+func (ga *GeneticAlgorithm) reuseFitness() {
+	for _, r := range ga.reused {
+		setStoredFitness(r.genome, storedFitness(r.source))
+	}
+	ga.reused = nil
+}
+
+// CacheStats returns the number of bred genomes whose key was found in the duplicate genome
+// cache, and the number whose key was not, since the run started
+func (ga *GeneticAlgorithm) CacheStats() (hits, misses int) {
+	return ga.cacheHits, ga.cacheMisses
+}
@@ -0,0 +1,113 @@
+package goga_test
+
+import (
+	"bytes"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type DuplicatesSuite struct {
+}
+
+var _ = Suite(&DuplicatesSuite{})
+
+// MySimulatorNumbered scores each genome with the number of genomes simulated so far
+type MySimulatorNumbered struct {
+	NumCalls int
+}
+
+func (ms *MySimulatorNumbered) Simulate(g goga.Genome) {
+	ms.NumCalls++
+	g.SetFitness(float64(ms.NumCalls))
+}
+func (ms *MySimulatorNumbered) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorNumbered) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorNumbered) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+// helperGenerateDuplicatingGeneticAlgorithm returns a genetic algorithm whose genomes
+// are all empty, so every genome it breeds is a duplicate
+func helperGenerateDuplicatingGeneticAlgorithm(ms goga.Simulator, opt ...goga.Option) goga.GeneticAlgorithm {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = ms
+	genAlgo.Init(append([]goga.Option{goga.PopulationSize(10), goga.ParallelSimulations(1)}, opt...)...)
+	return genAlgo
+}
+
+func (s *DuplicatesSuite) TestShouldStopRejectingDuplicates(t *C) {
+	ms := MySimulatorNumbered{}
+	genAlgo := helperGenerateDuplicatingGeneticAlgorithm(&ms, goga.MaxGenerations(3), goga.MaxDuplicateRejections(5))
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+
+	// Each bred generation holds the elite and 19 simulated genomes, the first of which is new
+	t.Assert(ms.NumCalls, Equals, 10+2*19)
+	t.Assert(result.Evaluations, Equals, ms.NumCalls)
+	t.Assert(result.CacheMisses, Equals, 1)
+	t.Assert(result.CacheHits, Equals, 2*(19+5)-1)
+
+	hits, misses := genAlgo.CacheStats()
+	t.Assert(hits, Equals, result.CacheHits)
+	t.Assert(misses, Equals, result.CacheMisses)
+}
+
+func (s *DuplicatesSuite) TestShouldSimulateEveryDuplicateWithoutRejections(t *C) {
+	ms := MySimulatorNumbered{}
+	genAlgo := helperGenerateDuplicatingGeneticAlgorithm(&ms, goga.MaxGenerations(3), goga.MaxDuplicateRejections(0))
+	result := genAlgo.Simulate()
+	t.Assert(ms.NumCalls, Equals, 10+2*19)
+	t.Assert(result.CacheHits, Equals, 2*19-1)
+}
+
+func (s *DuplicatesSuite) TestShouldReuseFitnessOfDuplicates(t *C) {
+	ms := MySimulatorNumbered{}
+	genAlgo := helperGenerateDuplicatingGeneticAlgorithm(&ms, goga.MaxGenerations(3), goga.DuplicateGenomes(goga.ReuseFitness))
+	result := genAlgo.Simulate()
+
+	// Only the initial population is simulated, the last of which is cached
+	t.Assert(ms.NumCalls, Equals, 10)
+	t.Assert(result.Evaluations, Equals, 10)
+	t.Assert(result.CacheMisses, Equals, 0)
+	t.Assert(result.CacheHits, Equals, 2*19)
+	for _, g := range genAlgo.GetPopulation() {
+		t.Assert(g.GetFitness(), Equals, 10.)
+	}
+}
+
+func (s *DuplicatesSuite) TestShouldSimulateOnlyCacheMisses(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	ms := MySimulatorNumbered{}
+	genAlgo.Simulator = &ms
+	genAlgo.Init(goga.PopulationSize(10), goga.MaxGenerations(20), goga.DuplicateGenomes(goga.ReuseFitness))
+	result := genAlgo.Simulate()
+	t.Assert(result.CacheHits+result.CacheMisses, Equals, 19*19)
+	t.Assert(result.Evaluations, Equals, 10+result.CacheMisses)
+	t.Assert(ms.NumCalls, Equals, result.Evaluations)
+}
+
+func (s *DuplicatesSuite) TestShouldCheckpointCachedFitness(t *C) {
+	ms := MySimulatorNumbered{}
+	genAlgo := helperGenerateDuplicatingGeneticAlgorithm(&ms, goga.MaxGenerations(2), goga.DuplicateGenomes(goga.ReuseFitness))
+	genAlgo.Simulate()
+	buffer := bytes.Buffer{}
+	t.Assert(genAlgo.WriteCheckpoint(&buffer), IsNil)
+
+	restoredSimulator := MySimulatorNumbered{}
+	restored := helperGenerateDuplicatingGeneticAlgorithm(&restoredSimulator, goga.MaxGenerations(4), goga.DuplicateGenomes(goga.ReuseFitness))
+	t.Assert(restored.RestoreCheckpoint(&buffer), IsNil)
+	hits, misses := restored.CacheStats()
+	t.Assert(hits, Equals, 19)
+	t.Assert(misses, Equals, 0)
+
+	result := restored.Simulate()
+	t.Assert(restoredSimulator.NumCalls, Equals, 0)
+	t.Assert(result.CacheHits, Equals, 3*19)
+	for _, g := range restored.GetPopulation() {
+		t.Assert(g.GetFitness(), Equals, 10.)
+	}
+}
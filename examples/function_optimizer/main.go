@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/tomcraven/goga"
 	fo "github.com/tomcraven/goga/function_optimizer"
 	"math"
@@ -36,13 +38,7 @@ func main() {
 		}
 		return sum
 	}
-	transFunc := func(v float64) float64 {
-		if v < -10000000 {
-			return 0
-		} else {
-			return v + 10000001
-		}
-	}
-	algo := fo.NewFuncAlgo(fo.Function(function), fo.ParamSize(paramSize), fo.Requirement(&requirement), fo.TransFunc(transFunc))
-	algo.Simulate()
+	algo := fo.NewFuncAlgo(fo.Function(function), fo.ParamSize(paramSize), fo.Requirement(&requirement), fo.Scaling(goga.SigmaScaling))
+	result := fo.DecodeResult(algo.Simulate())
+	fmt.Println("params:", result.Params, "func value:", result.Elite.GetOrigin(), "generations:", result.Generations, "in", result.Duration)
 }
@@ -4,17 +4,17 @@ import (
 	"fmt"
 	"image"
 	_ "image/jpeg"
-	"math/rand"
 	"os"
 	"runtime"
-	"time"
 
 	"github.com/tomcraven/goga"
 )
 
 const (
 	// Fiddle with these
-	numShapes               = 10
+	minShapes               = 5
+	maxShapes               = 50
+	shapePenalty            = 100.0 // fitness lost per shape, stops shapes being added that barely help
 	populationSize          = 10
 	maxIterations           = 9999999
 	bitsPerCoordinateNumber = 9
@@ -33,9 +33,10 @@ type imageMatcherSimulator struct {
 	totalIterations int
 }
 
-func (simulator *imageMatcherSimulator) OnBeginSimulation() {
+func (simulator *imageMatcherSimulator) OnBeginSimulation() []goga.Genome {
+	return nil
 }
-func (simulator *imageMatcherSimulator) OnEndSimulation() {
+func (simulator *imageMatcherSimulator) OnEndSimulation([]goga.Genome) {
 	simulator.totalIterations++
 }
 func (simulator *imageMatcherSimulator) Simulate(g goga.Genome) {
@@ -60,21 +61,8 @@ func (simulator *imageMatcherSimulator) ExitFunc(g goga.Genome) bool {
 	return simulator.totalIterations >= maxIterations
 }
 
-type myBitsetCreate struct {
-}
-
-func (bc *myBitsetCreate) Go() goga.Bitset {
-	b := goga.Bitset{}
-	b.Create(numShapes * largestShapeBits)
-	for i := 0; i < b.GetSize(); i++ {
-		b.Set(i, rand.Intn(2))
-	}
-	return b
-}
-
 var (
-	largestShapeBits   int
-	totalBitsPerGenome int
+	largestShapeBits int
 
 	inputImage image.Image
 
@@ -89,8 +77,6 @@ func init() {
 	} else {
 		largestShapeBits += bitsPerCircle
 	}
-
-	totalBitsPerGenome = largestShapeBits * numShapes
 }
 
 func getImageFromFile(filename string) image.Image {
@@ -119,13 +105,24 @@ func main() {
 		bitsPerColourChannel, bitsPerColourChannel, bitsPerColourChannel, bitsPerColourChannel,
 	})
 
+	// Each shape is a block of the genome, so the number of shapes evolves along with them
+	shapeMater := goga.BlockMater{Width: largestShapeBits, MinBlocks: minShapes, MaxBlocks: maxShapes}
+
 	genAlgo := goga.NewGeneticAlgorithm()
-	genAlgo.Simulator = &imageMatcherSimulator{}
-	genAlgo.BitsetCreate = &myBitsetCreate{}
+	genAlgo.Simulator = &goga.LengthPenalty{
+		Simulator: &imageMatcherSimulator{},
+		Penalty: func(length int) float64 {
+			return shapePenalty * float64(length/largestShapeBits)
+		},
+	}
+	genAlgo.BitsetCreate = &goga.BlockBitsetCreate{Width: largestShapeBits, MinBlocks: minShapes, MaxBlocks: maxShapes}
 	genAlgo.EliteConsumer = &myEliteConsumer{}
 	genAlgo.Mater = goga.NewMater(
 		[]goga.MaterFunctionProbability{
+			{P: 0.5, F: shapeMater.CutAndSplice, UseElite: true},
 			{P: 1.0, F: goga.UniformCrossover, UseElite: true},
+			{P: 0.1, F: shapeMater.InsertMutate},
+			{P: 0.1, F: shapeMater.DeleteMutate},
 			{P: 1.0, F: goga.Mutate},
 			{P: 1.0, F: goga.Mutate},
 			{P: 1.0, F: goga.Mutate},
@@ -146,7 +143,6 @@ func main() {
 		},
 	)
 	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(parallelSimulations))
-	startTime := time.Now()
-	genAlgo.Simulate()
-	fmt.Println(time.Since(startTime))
+	result := genAlgo.Simulate()
+	fmt.Println(result.Duration)
 }
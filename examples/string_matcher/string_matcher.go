@@ -5,7 +5,6 @@ import (
 	"math/rand"
 	"os"
 	"runtime"
-	"time"
 
 	"github.com/tomcraven/goga"
 )
@@ -13,9 +12,10 @@ import (
 type stringMaterSimulator struct {
 }
 
-func (sms *stringMaterSimulator) OnBeginSimulation() {
+func (sms *stringMaterSimulator) OnBeginSimulation() []goga.Genome {
+	return nil
 }
-func (sms *stringMaterSimulator) OnEndSimulation() {
+func (sms *stringMaterSimulator) OnEndSimulation([]goga.Genome) {
 }
 func (sms *stringMaterSimulator) Simulate(g goga.Genome) {
 	bits := g.GetBits()
@@ -63,7 +63,7 @@ func (ec *myEliteConsumer) OnElite(g goga.Genome) {
 				c |= 1 << uint(j)
 			}
 		}
-		genomeString += string(c)
+		genomeString += string(rune(c))
 	}
 
 	fmt.Println(ec.currentIter, "\t", genomeString, "\t", g.GetFitness())
@@ -110,7 +110,6 @@ func main() {
 
 	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(numThreads))
 
-	startTime := time.Now()
-	genAlgo.Simulate()
-	fmt.Println(time.Since(startTime))
+	result := genAlgo.Simulate()
+	fmt.Println(result.Duration)
 }
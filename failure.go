@@ -0,0 +1,115 @@
+package goga
+
+import "fmt"
+
+// FailureAction - what the genetic algorithm does with a genome whose simulation failed,
+// see FailurePolicy
+type FailureAction int
+
+const (
+	// AbortRun - the run stops with the error of the simulation, the default
+	AbortRun FailureAction = iota
+	// PenaliseGenome - the genome is given the penalty fitness and the run carries on
+	PenaliseGenome
+	// ReplaceGenome - the genome is dropped and replaced with a new genome from the GenomeCreate,
+	// or BitsetCreate, which is simulated in its place. The run stops with the error once more
+	// genomes than the population size have been replaced in a generation
+	ReplaceGenome
+)
+
+// FailurePolicy - how the genetic algorithm handles simulations that fail, by returning
+// an error from an ErrorSimulator or by panicking
+// * Retries - the number of times a failed simulation is retried before 'Action' is taken
+// * Action - what happens to a genome whose simulation still fails, see FailureAction
+// * Penalty - the fitness given to penalised genomes, and to dropped genomes whose fitness
+// was reused by duplicates, see DuplicateGenomes. It should be worse than any real fitness
+// in the ObjectiveDirection of the run
+type FailurePolicy struct {
+	Retries int
+	Action  FailureAction
+	Penalty float64
+}
+
+// SimulationFailures sets how failed simulations are handled, see FailurePolicy
+// By default a failure aborts the run, Simulate, SimulateUntil and SimulateContext then return
+// a result with TerminationError whose Err is a *SimulationError
+// The policy applies to runs driven by the genetic algorithm or by Islands, genomes simulated
+// through Ask and Tell are handled by the caller
+func SimulationFailures(policy FailurePolicy) Option {
+	return func(o *Options) {
+		o.FailurePolicy = policy
+	}
+}
+
+// SimulationError - the error of a run aborted by a failed simulation, see SimulationFailures
+// Err is the error returned by the simulator, or a *PanicError if it panicked
+type SimulationError struct {
+	Genome Genome
+	Err    error
+}
+
+func (e *SimulationError) Error() string {
+	return fmt.Sprintf("simulation failed: %v", e.Err)
+}
+
+// Unwrap returns the error of the simulation
+func (e *SimulationError) Unwrap() error {
+	return e.Err
+}
+
+// settle tells the genetic algorithm the genomes of 'simulated' whose simulation succeeded,
+// and handles those in 'failures' according to the FailurePolicy
+// The simulation workers have already put the fitness of the genomes in the FitnessStore
+// A *SimulationError is returned if the run should be aborted, otherwise the error, if any,
+// returned by telling the genomes
+func (ga *GeneticAlgorithm) settle(simulated []Genome, failures map[Genome]error) error {
+	told := make([]Genome, 0, len(simulated))
+	for _, g := range simulated {
+		err, failed := failures[g]
+		if !failed {
+			told = append(told, g)
+			continue
+		}
+		switch {
+		case err == ErrSimulationTimeout:
+			g.SetFitness(ga.timeoutPenalty)
+			told = append(told, g)
+		case ga.failurePolicy.Action == PenaliseGenome:
+			g.SetFitness(ga.failurePolicy.Penalty)
+			told = append(told, g)
+		case ga.failurePolicy.Action == ReplaceGenome && ga.replaced < ga.populationSize:
+			ga.replace(g)
+		default:
+			return &SimulationError{Genome: g, Err: err}
+		}
+	}
+	return ga.tell(told, nil)
+}
+
+// replace drops 'g', which was handed out by Ask, from the generation in progress and queues
+// a new genome to be simulated in its place
+func (ga *GeneticAlgorithm) replace(g Genome) {
+	ga.asked[g]--
+	if ga.asked[g] == 0 {
+		delete(ga.asked, g)
+	}
+	ga.outstanding--
+	ga.replaced++
+
+	// Duplicates of 'g' that reuse its fitness are penalised, and further ones simulated
+	g.SetFitness(ga.failurePolicy.Penalty)
+	ga.lru.Remove(g.Key())
+
+	replacement := ga.createGenome()
+	genomes := ga.offspring
+	if ga.generation == 0 {
+		genomes = ga.population
+	}
+	for i := range genomes {
+		if genomes[i] == g {
+			genomes[i] = replacement
+			break
+		}
+	}
+	ga.queue = append(ga.queue, replacement)
+}
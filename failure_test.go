@@ -0,0 +1,207 @@
+package goga_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type FailureSuite struct {
+}
+
+var _ = Suite(&FailureSuite{})
+
+var errSolverFailed = errors.New("solver failed")
+
+// MySimulatorFlaky fails to simulate genomes whose first two bits are set, and every
+// genome for its first 'FailFirst' attempts, otherwise it scores the number of bits set
+type MySimulatorFlaky struct {
+	FailFirst int
+	attempts  map[goga.Genome]int
+	m         sync.Mutex
+}
+
+func (ms *MySimulatorFlaky) Simulate(goga.Genome) {
+	panic("Simulate should not be called on an ErrorSimulator")
+}
+func (ms *MySimulatorFlaky) SimulateErr(ctx context.Context, g goga.Genome) error {
+	ms.m.Lock()
+	if ms.attempts == nil {
+		ms.attempts = make(map[goga.Genome]int)
+	}
+	ms.attempts[g]++
+	attempts := ms.attempts[g]
+	ms.m.Unlock()
+
+	bits := g.GetBits()
+	if attempts <= ms.FailFirst || (bits.Get(0) == 1 && bits.Get(1) == 1) {
+		return errSolverFailed
+	}
+	fitness := 0
+	for i := 0; i < bits.GetSize(); i++ {
+		fitness += bits.Get(i)
+	}
+	g.SetFitness(float64(fitness))
+	return nil
+}
+func (ms *MySimulatorFlaky) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorFlaky) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorFlaky) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+type MySimulatorPanicking struct {
+	MySimulatorBitCount
+}
+
+func (ms *MySimulatorPanicking) Simulate(g goga.Genome) {
+	if g.GetBits().Get(0) == 1 {
+		panic("solver crashed")
+	}
+	ms.MySimulatorBitCount.Simulate(g)
+}
+
+// helperAssertFailureHandled asserts that no genome in the population failed to simulate
+func helperAssertFailureHandled(t *C, population []goga.Genome, penalty float64) {
+	for _, g := range population {
+		bits := g.GetBits()
+		if bits.Get(0) == 1 && bits.Get(1) == 1 {
+			t.Assert(g.GetFitness(), Equals, penalty)
+		}
+	}
+}
+
+func (s *FailureSuite) TestShouldAbortOnPanic(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(1), goga.ParallelSimulations(kNumThreads))
+	genAlgo.Simulator = &MySimulatorPanicking{}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationError)
+	t.Assert(result.Generations, Equals, 0)
+	t.Assert(result.Failures > 0, Equals, true)
+
+	var simulationErr *goga.SimulationError
+	t.Assert(errors.As(result.Err, &simulationErr), Equals, true)
+	t.Assert(simulationErr.Genome.GetBits().Get(0), Equals, 1)
+	var panicErr *goga.PanicError
+	t.Assert(errors.As(result.Err, &panicErr), Equals, true)
+	t.Assert(panicErr.Value, Equals, "solver crashed")
+	t.Assert(len(panicErr.Stack) > 0, Equals, true)
+}
+
+func (s *FailureSuite) TestShouldRetryFailedSimulations(t *C) {
+	ms := &MySimulatorFlaky{FailFirst: 2}
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(2), goga.MaxGenerations(3),
+		goga.SimulationFailures(goga.FailurePolicy{Retries: 2, Action: goga.PenaliseGenome, Penalty: -1}))
+	genAlgo.Simulator = ms
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	for g, attempts := range ms.attempts {
+		t.Assert(attempts, Equals, 3, Commentf("%v", g))
+	}
+	helperAssertFailureHandled(t, genAlgo.GetPopulation(), -1)
+}
+
+func (s *FailureSuite) TestShouldPenaliseFailedGenomes(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(3), goga.MaxGenerations(5),
+		goga.SimulationFailures(goga.FailurePolicy{Action: goga.PenaliseGenome, Penalty: -1}))
+	genAlgo.Simulator = &MySimulatorFlaky{}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Err, IsNil)
+	t.Assert(result.Failures > 0, Equals, true)
+	t.Assert(result.Elite.GetFitness() > 0, Equals, true)
+	helperAssertFailureHandled(t, genAlgo.GetPopulation(), -1)
+}
+
+func (s *FailureSuite) TestShouldPenaliseFailuresThroughLengthPenalty(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(3), goga.MaxGenerations(5),
+		goga.SimulationFailures(goga.FailurePolicy{Action: goga.PenaliseGenome, Penalty: -1}))
+	genAlgo.Simulator = &goga.LengthPenalty{
+		Simulator: &MySimulatorFlaky{},
+		Penalty: func(length int) float64 {
+			return 0.5
+		},
+	}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Failures > 0, Equals, true)
+	t.Assert(result.Elite.GetFitness() > 0, Equals, true)
+
+	// Failed genomes are given the penalty of the policy alone, the rest have their length penalised
+	helperAssertFailureHandled(t, genAlgo.GetPopulation(), -1)
+	for _, g := range genAlgo.GetPopulation() {
+		if g.GetFitness() != -1 {
+			t.Assert(g.GetFitness()-math.Floor(g.GetFitness()), Equals, 0.5)
+		}
+	}
+}
+
+func (s *FailureSuite) TestShouldReplaceFailedGenomes(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(4), goga.MaxGenerations(1),
+		goga.SimulationFailures(goga.FailurePolicy{Action: goga.ReplaceGenome}))
+	genAlgo.Simulator = &MySimulatorFlaky{}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Failures > 0, Equals, true)
+	t.Assert(result.Evaluations, Equals, 20+result.Failures)
+	for _, g := range genAlgo.GetPopulation() {
+		bits := g.GetBits()
+		t.Assert(bits.Get(0) == 1 && bits.Get(1) == 1, Equals, false)
+		t.Assert(g.GetFitness() > 0, Equals, true)
+	}
+}
+
+func (s *FailureSuite) TestShouldAbortOnceTooManyGenomesAreReplaced(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(5),
+		goga.SimulationFailures(goga.FailurePolicy{Action: goga.ReplaceGenome}))
+	genAlgo.Simulator = &MySimulatorFlaky{FailFirst: 1000}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationError)
+	t.Assert(errors.Is(result.Err, errSolverFailed), Equals, true)
+	t.Assert(result.Failures, Equals, 2*20)
+}
+
+func (s *FailureSuite) TestShouldReplaceFailedGenomesOnIslands(t *C) {
+	islands := goga.NewIslands(
+		helperGenerateFailingIsland(6),
+		helperGenerateFailingIsland(7),
+	)
+	islands.Init(goga.ParallelSimulations(kNumThreads), goga.MaxGenerations(4), goga.MigrationInterval(2))
+	result := islands.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Failures > 0, Equals, true)
+	for _, island := range islands.Islands {
+		t.Assert(island.Generation(), Equals, 4)
+	}
+}
+
+func helperGenerateFailingIsland(seed int64) *goga.GeneticAlgorithm {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(seed),
+		goga.SimulationFailures(goga.FailurePolicy{Action: goga.ReplaceGenome}))
+	genAlgo.Simulator = &MySimulatorFlaky{}
+	return &genAlgo
+}
+
+func (s *FailureSuite) TestShouldAdaptSimulators(t *C) {
+	g := goga.NewGenome(goga.Bitset{})
+	g.GetBits().Create(2)
+	g.GetBits().Set(0, 1)
+
+	t.Assert(goga.AsErrorSimulator(&MySimulatorBitCount{}).SimulateErr(context.Background(), g), IsNil)
+	t.Assert(g.GetFitness(), Equals, 1.)
+
+	err := goga.AsErrorSimulator(&MySimulatorPanicking{}).SimulateErr(context.Background(), g)
+	var panicErr *goga.PanicError
+	t.Assert(errors.As(err, &panicErr), Equals, true)
+	t.Assert(err, ErrorMatches, "simulation panicked: solver crashed")
+
+	g.GetBits().Set(1, 1)
+	t.Assert(goga.AsErrorSimulator(&MySimulatorFlaky{}).SimulateErr(context.Background(), g), Equals, errSolverFailed)
+}
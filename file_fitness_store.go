@@ -0,0 +1,196 @@
+package goga
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FileFitnessStore - a FitnessStore kept in an append-only file, one line per simulated genome
+// holding a SHA-256 hash of its key followed by its fitness, origin and objectives and ending
+// with a CRC-32 checksum of the rest of the line
+// The file is locked while it is read or written so that several runs, in the same or different
+// processes on one machine, can share it. Each store keeps every line it has read in memory and
+// reads lines appended by other runs whenever it is asked for a key it does not hold
+// A line left incomplete by a crashed run is skipped, as its checksum is missing or does not
+// match, as is any other line that can not be parsed, and when a key is stored more than once
+// the last line wins
+type FileFitnessStore struct {
+	m       sync.Mutex
+	file    *os.File
+	offset  int64
+	fitness map[string]StoredFitness
+}
+
+// OpenFileFitnessStore opens the store kept in the file 'path', creating the file if it does
+// not exist, the store is read lazily, or all at once by Warm
+func OpenFileFitnessStore(path string) (*FileFitnessStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileFitnessStore{
+		file:    file,
+		fitness: make(map[string]StoredFitness),
+	}, nil
+}
+
+// Warm reads every line of the file that has not been read yet into memory, Init calls it
+// when the store is passed to the StoreFitness option
+func (s *FileFitnessStore) Warm() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.read()
+}
+
+// Len returns the number of keys held in memory
+func (s *FileFitnessStore) Len() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return len(s.fitness)
+}
+
+// Get returns the fitness stored for 'key', reading any lines appended to the file since
+// it was last read when the key is not already held in memory
+// A key that can not be read from the file is not found
+func (s *FileFitnessStore) Get(key string) (StoredFitness, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	hash := hashKey(key)
+	if f, ok := s.fitness[hash]; ok {
+		return f, true
+	}
+	if s.read() != nil {
+		return StoredFitness{}, false
+	}
+	f, ok := s.fitness[hash]
+	return f, ok
+}
+
+// Put appends the fitness of the genome with key 'key' to the file
+func (s *FileFitnessStore) Put(key string, fitness StoredFitness) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if err := lockFile(s.file, true); err != nil {
+		return err
+	}
+	defer unlockFile(s.file)
+
+	hash := hashKey(key)
+	line := formatFitnessLine(hash, fitness)
+	// Start on a line of its own if a crashed run left the last line incomplete
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := s.file.ReadAt(last, info.Size()-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			line = "\n" + line
+		}
+	}
+	if _, err := s.file.WriteString(line); err != nil {
+		return err
+	}
+	s.fitness[hash] = fitness
+	return nil
+}
+
+// Close closes the file, the store can not be used afterwards
+func (s *FileFitnessStore) Close() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.file.Close()
+}
+
+// read reads the complete lines appended to the file since it was last read
+func (s *FileFitnessStore) read() error {
+	if err := lockFile(s.file, false); err != nil {
+		return err
+	}
+	defer unlockFile(s.file)
+
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() <= s.offset {
+		return nil
+	}
+	data := make([]byte, info.Size()-s.offset)
+	if _, err := s.file.ReadAt(data, s.offset); err != nil && err != io.EOF {
+		return err
+	}
+	for {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			return nil
+		}
+		if hash, fitness, ok := parseFitnessLine(string(data[:end])); ok {
+			s.fitness[hash] = fitness
+		}
+		data = data[end+1:]
+		s.offset += int64(end + 1)
+	}
+}
+
+// hashKey returns the hex encoded SHA-256 hash of 'key'
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatFitnessLine returns the line of a FileFitnessStore for 'fitness' of the key with hash 'hash'
+func formatFitnessLine(hash string, fitness StoredFitness) string {
+	fields := []string{
+		hash,
+		strconv.FormatFloat(fitness.Fitness, 'g', -1, 64),
+		strconv.FormatFloat(fitness.Origin, 'g', -1, 64),
+	}
+	for _, objective := range fitness.Objectives {
+		fields = append(fields, strconv.FormatFloat(objective, 'g', -1, 64))
+	}
+	line := strings.Join(fields, " ")
+	return line + " " + lineChecksum(line) + "\n"
+}
+
+// lineChecksum returns the hex encoded CRC-32 checksum ending a line that starts with 'line'
+func lineChecksum(line string) string {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(line)))
+}
+
+// parseFitnessLine parses a line of a FileFitnessStore, without its newline, and reports
+// whether it is well formed and ends with the checksum of the rest of the line
+func parseFitnessLine(line string) (string, StoredFitness, bool) {
+	end := strings.LastIndexByte(line, ' ')
+	if end < 0 || line[end+1:] != lineChecksum(line[:end]) {
+		return "", StoredFitness{}, false
+	}
+	fields := strings.Fields(line[:end])
+	if len(fields) < 3 || len(fields[0]) != sha256.Size*2 {
+		return "", StoredFitness{}, false
+	}
+	values := make([]float64, len(fields)-1)
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return "", StoredFitness{}, false
+		}
+		values[i] = v
+	}
+	fitness := StoredFitness{Fitness: values[0], Origin: values[1]}
+	if len(values) > 2 {
+		fitness.Objectives = values[2:]
+	}
+	return fields[0], fitness, true
+}
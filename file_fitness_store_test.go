@@ -0,0 +1,110 @@
+package goga_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type FileFitnessStoreSuite struct {
+}
+
+var _ = Suite(&FileFitnessStoreSuite{})
+
+func (s *FileFitnessStoreSuite) TestShouldKeepFitnessAcrossReopening(t *C) {
+	path := filepath.Join(t.MkDir(), "fitness")
+	store, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	_, ok := store.Get("0101")
+	t.Assert(ok, Equals, false)
+	t.Assert(store.Put("0101", goga.StoredFitness{Fitness: 1.5, Origin: 2}), IsNil)
+	t.Assert(store.Put("1111", goga.StoredFitness{Fitness: math.Inf(-1), Objectives: []float64{3, -0.25}}), IsNil)
+	t.Assert(store.Put("0101", goga.StoredFitness{Fitness: 4}), IsNil)
+	t.Assert(store.Close(), IsNil)
+
+	reopened, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	defer reopened.Close()
+	t.Assert(reopened.Len(), Equals, 0)
+	t.Assert(reopened.Warm(), IsNil)
+	t.Assert(reopened.Len(), Equals, 2)
+
+	f, ok := reopened.Get("0101")
+	t.Assert(ok, Equals, true)
+	t.Assert(f, DeepEquals, goga.StoredFitness{Fitness: 4})
+	f, ok = reopened.Get("1111")
+	t.Assert(ok, Equals, true)
+	t.Assert(f, DeepEquals, goga.StoredFitness{Fitness: math.Inf(-1), Objectives: []float64{3, -0.25}})
+}
+
+func (s *FileFitnessStoreSuite) TestShouldShareFitnessBetweenStores(t *C) {
+	path := filepath.Join(t.MkDir(), "fitness")
+	first, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	defer first.Close()
+	second, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	defer second.Close()
+
+	t.Assert(first.Warm(), IsNil)
+	t.Assert(second.Put("0011", goga.StoredFitness{Fitness: 3}), IsNil)
+	f, ok := first.Get("0011")
+	t.Assert(ok, Equals, true)
+	t.Assert(f.Fitness, Equals, 3.)
+}
+
+func (s *FileFitnessStoreSuite) TestShouldSkipIncompleteLines(t *C) {
+	path := filepath.Join(t.MkDir(), "fitness")
+	store, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	t.Assert(store.Put("0", goga.StoredFitness{Fitness: 1}), IsNil)
+	t.Assert(store.Close(), IsNil)
+
+	// A run that crashed part way through writing a line
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	t.Assert(err, IsNil)
+	_, err = file.WriteString("not a line of a fitness st")
+	t.Assert(err, IsNil)
+	t.Assert(file.Close(), IsNil)
+
+	reopened, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	defer reopened.Close()
+	t.Assert(reopened.Put("1", goga.StoredFitness{Fitness: 2}), IsNil)
+
+	other, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	defer other.Close()
+	t.Assert(other.Warm(), IsNil)
+	t.Assert(other.Len(), Equals, 2)
+	f, ok := other.Get("1")
+	t.Assert(ok, Equals, true)
+	t.Assert(f.Fitness, Equals, 2.)
+}
+
+func (s *FileFitnessStoreSuite) TestShouldSkipLinesCutShortAtAField(t *C) {
+	path := filepath.Join(t.MkDir(), "fitness")
+	store, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	t.Assert(store.Put("0", goga.StoredFitness{Fitness: 1.25, Origin: 0.5, Objectives: []float64{3, 4}}), IsNil)
+	t.Assert(store.Close(), IsNil)
+
+	// A run that crashed after writing the fitness and origin of a genome but before its
+	// objectives, the line still looks like one without objectives
+	data, err := os.ReadFile(path)
+	t.Assert(err, IsNil)
+	torn := strings.Fields(string(data))[:3]
+	t.Assert(os.WriteFile(path, []byte(strings.Join(torn, " ")+"\n"), 0644), IsNil)
+
+	reopened, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	defer reopened.Close()
+	t.Assert(reopened.Warm(), IsNil)
+	t.Assert(reopened.Len(), Equals, 0)
+	_, ok := reopened.Get("0")
+	t.Assert(ok, Equals, false)
+}
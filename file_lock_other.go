@@ -0,0 +1,17 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package goga
+
+import "os"
+
+// lockFile does nothing on platforms without flock, a FileFitnessStore should
+// then only be used by one run at a time
+func lockFile(file *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile does nothing on platforms without flock
+func unlockFile(file *os.File) error {
+	return nil
+}
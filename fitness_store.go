@@ -0,0 +1,114 @@
+package goga
+
+// StoredFitness - the outcome of simulating a genome as kept by a FitnessStore
+type StoredFitness struct {
+	Fitness    float64
+	Origin     float64
+	Objectives []float64
+}
+
+// FitnessStore - a store of the fitness of simulated genomes by key, see Genome.Key, which
+// outlives a run so that genomes simulated by earlier or concurrent runs are not simulated again
+// Get returns the fitness stored for 'key', if any, and Put stores the fitness of a genome that
+// has just been simulated, see the FitnessStore option
+// Both are called from the simulation workers, so a store must be safe for concurrent use
+type FitnessStore interface {
+	Get(key string) (StoredFitness, bool)
+	Put(key string, fitness StoredFitness) error
+}
+
+// FitnessStoreWarmer - an optional extension of the FitnessStore interface for stores that can
+// load everything they hold into memory up front, Init calls Warm when the store implements it
+type FitnessStoreWarmer interface {
+	Warm() error
+}
+
+// NullFitnessStore - a null implementation of the FitnessStore interface
+type NullFitnessStore struct {
+}
+
+// Get returns nothing
+func (s *NullFitnessStore) Get(string) (StoredFitness, bool) {
+	return StoredFitness{}, false
+}
+
+// Put does nothing
+func (s *NullFitnessStore) Put(string, StoredFitness) error {
+	return nil
+}
+
+// StoreFitness looks up every genome in 'store' before it is simulated, a genome that is found
+// is given the stored fitness, origin and objectives rather than being simulated, and stores the
+// fitness of every genome that is simulated
+// Genomes are looked up when they are bred and again by the simulation worker about to simulate
+// them, which puts their fitness in the store as soon as they are simulated, so that genomes
+// bred at the same time by islands or runs sharing the store are only simulated once
+// Genomes found in the store count as cache hits, see Result, whatever the DuplicatePolicy,
+// and an error from the store is returned by the next call to Tell and stops a run
+func StoreFitness(store FitnessStore) Option {
+	return func(o *Options) {
+		o.FitnessStore = store
+	}
+}
+
+// storedFitness returns the fitness, origin and objectives of 'g'
+func storedFitness(g Genome) StoredFitness {
+	f := StoredFitness{Fitness: g.GetFitness(), Origin: g.GetOrigin()}
+	if mog, ok := g.(MultiObjectiveGenome); ok {
+		f.Objectives = mog.GetObjectives()
+	}
+	return f
+}
+
+// setStoredFitness gives 'g' the fitness, origin and objectives of 'f'
+func setStoredFitness(g Genome, f StoredFitness) {
+	g.SetFitness(f.Fitness)
+	g.SetOrigin(f.Origin)
+	if mog, ok := g.(MultiObjectiveGenome); ok && f.Objectives != nil {
+		mog.SetObjectives(append([]float64{}, f.Objectives...))
+	}
+}
+
+// fitnessGenome returns a genome without genes holding 'f', which stands in for
+// a cached genome when only its fitness is known
+func fitnessGenome(f StoredFitness) Genome {
+	g := NewGenome(Bitset{})
+	setStoredFitness(g, f)
+	return g
+}
+
+// getFitness returns the fitness held for 'key' by the FitnessStore, if there is one and it holds it
+func (ga *GeneticAlgorithm) getFitness(key string) (StoredFitness, bool) {
+	if ga.store == nil {
+		return StoredFitness{}, false
+	}
+	return ga.store.Get(key)
+}
+
+// countStoreHits counts the genomes of 'simulated' that the simulation workers found in the
+// FitnessStore, see simulationPool.storeHits, as cache hits rather than the misses they were
+// counted as when bred
+func (ga *GeneticAlgorithm) countStoreHits(simulated []Genome, hits map[Genome]bool) {
+	for _, g := range simulated {
+		if !hits[g] {
+			continue
+		}
+		ga.cacheHits++
+		if ga.generation > 0 {
+			ga.cacheMisses--
+		}
+	}
+}
+
+// putFitness puts the fitness of the simulated genomes 'genomes' in the FitnessStore, if there
+// is one, the first error is kept to be returned by Tell
+func (ga *GeneticAlgorithm) putFitness(genomes []Genome) {
+	if ga.store == nil {
+		return
+	}
+	for _, g := range genomes {
+		if err := ga.store.Put(g.Key(), storedFitness(g)); err != nil && ga.storeErr == nil {
+			ga.storeErr = err
+		}
+	}
+}
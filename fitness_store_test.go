@@ -0,0 +1,94 @@
+package goga_test
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type FitnessStoreSuite struct {
+}
+
+var _ = Suite(&FitnessStoreSuite{})
+
+// MyFitnessStoreFailing fails to warm and to put
+type MyFitnessStoreFailing struct {
+	goga.NullFitnessStore
+}
+
+// MyFitnessStoreConstant holds a fitness of 7 for every genome
+type MyFitnessStoreConstant struct {
+	goga.NullFitnessStore
+}
+
+func (s *MyFitnessStoreConstant) Get(string) (goga.StoredFitness, bool) {
+	return goga.StoredFitness{Fitness: 7}, true
+}
+
+var errStoreFailed = errors.New("store failed")
+
+func (s *MyFitnessStoreFailing) Put(string, goga.StoredFitness) error {
+	return errStoreFailed
+}
+func (s *MyFitnessStoreFailing) Warm() error {
+	return errStoreFailed
+}
+
+func (s *FitnessStoreSuite) TestShouldNotSimulateStoredGenomes(t *C) {
+	path := filepath.Join(t.MkDir(), "fitness")
+	store, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	first := helperGenerateSeededGeneticAlgorithm(goga.Seed(1), goga.MaxGenerations(3), goga.StoreFitness(store))
+	first.Simulator = &MySimulatorBitCount{}
+	firstResult := first.Simulate()
+	t.Assert(firstResult.Evaluations, Equals, 20+2*39)
+	t.Assert(store.Close(), IsNil)
+
+	// A run with the same initial population finds all of it in the reopened store
+	reopened, err := goga.OpenFileFitnessStore(path)
+	t.Assert(err, IsNil)
+	defer reopened.Close()
+	ms := MySimulatorCounter{}
+	second := helperGenerateSeededGeneticAlgorithm(goga.Seed(1), goga.MaxGenerations(1), goga.StoreFitness(reopened))
+	second.Simulator = &ms
+	t.Assert(reopened.Len(), Equals, firstResult.Evaluations)
+	result := second.Simulate()
+	t.Assert(ms.NumCalls, Equals, 0)
+	t.Assert(result.Evaluations, Equals, 0)
+	t.Assert(result.CacheHits, Equals, 20)
+	t.Assert(result.EliteFitnessHistory[0], Equals, firstResult.EliteFitnessHistory[0])
+}
+
+func (s *FitnessStoreSuite) TestShouldReuseStoredFitnessOfOffspring(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	ms := MySimulatorCounter{}
+	genAlgo.Simulator = &ms
+	genAlgo.Init(goga.PopulationSize(20), goga.MaxGenerations(3), goga.StoreFitness(&MyFitnessStoreConstant{}))
+	result := genAlgo.Simulate()
+	t.Assert(ms.NumCalls, Equals, 0)
+	t.Assert(result.Evaluations, Equals, 0)
+	t.Assert(result.Generations, Equals, 3)
+	t.Assert(result.CacheMisses, Equals, 0)
+	t.Assert(result.CacheHits >= 20+2*39, Equals, true)
+	for _, g := range genAlgo.GetPopulation() {
+		t.Assert(g.GetFitness(), Equals, 7.)
+	}
+}
+
+func (s *FitnessStoreSuite) TestShouldStopOnStoreError(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.Seed(3), goga.MaxGenerations(3), goga.StoreFitness(&MyFitnessStoreFailing{}))
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationError)
+	t.Assert(result.Err, Equals, errStoreFailed)
+	t.Assert(result.Generations, Equals, 1)
+}
+
+func (s *FitnessStoreSuite) TestShouldReturnStoreErrorFromTell(t *C) {
+	genAlgo := helperGenerateSeededGeneticAlgorithm(goga.StoreFitness(&MyFitnessStoreFailing{}))
+	genomes := genAlgo.Ask(20)
+	t.Assert(genAlgo.Tell(genomes[:10]), Equals, errStoreFailed)
+	t.Assert(genAlgo.Tell(genomes[10:]), Equals, errStoreFailed)
+	t.Assert(genAlgo.Generation(), Equals, 1)
+}
@@ -36,7 +36,7 @@ func (sms *funcMaterSimulator) OnEndSimulation(a []goga.Genome) {
 }
 
 func (sms *funcMaterSimulator) Simulate(g goga.Genome) {
-	params := goga.ParseBitsToFloat64Arr(g.GetBits())
+	params := decodeParams(g)
 	g.SetOrigin(sms.function(params))
 	g.SetFitness(sms.transFunc(g.GetOrigin()))
 }
@@ -58,36 +58,13 @@ func (sms *funcMaterSimulator) ExitFunc(g goga.Genome) bool {
 	return false
 }
 
-type myBitsetCreate struct {
-	paramsSize  int
-	initValue   []float64
-	requirement *goga.Float64Requirement
-}
-
-func (bc *myBitsetCreate) Go() goga.Bitset {
-	b := goga.Bitset{}
-	b.Create(bc.paramsSize * 8)
-	for i := 0; i < bc.paramsSize; i++ {
-		var param float64
-		if require, ok := bc.requirement.Specific[i]; ok {
-			if i < len(bc.initValue) && bc.initValue[i] >= require.MinValue && bc.initValue[i] <= require.MaxValue {
-				param = bc.initValue[i]
-			} else {
-				param = goga.Round(rand.Float64()*(require.MaxValue-require.MinValue)+require.MinValue, require.Precision)
-			}
-		} else {
-			if i < len(bc.initValue) && bc.initValue[i] >= bc.requirement.MinValue && bc.initValue[i] <= bc.requirement.MaxValue {
-				param = bc.initValue[i]
-			} else {
-				param = goga.Round(rand.Float64()*(bc.requirement.MaxValue-bc.requirement.MinValue)+bc.requirement.MinValue, bc.requirement.Precision)
-			}
-		}
-		byteArr := goga.Float64ToByte(param)
-		for idx := 0; idx < 8; idx++ {
-			b.Set(i*8+idx, int(byteArr[idx]))
-		}
+// decodeParams returns the parameters held by 'g', a real-valued genome or
+// a bitset of float64s, see goga.ParseFloat64ArrToBits
+func decodeParams(g goga.Genome) []float64 {
+	if rg, ok := g.(goga.RealGenome); ok {
+		return rg.GetValues()
 	}
-	return b
+	return goga.ParseBitsToFloat64Arr(g.GetBits())
 }
 
 type myEliteConsumer struct {
@@ -101,12 +78,31 @@ func (ec *myEliteConsumer) OnElite(g goga.Genome) {
 		ec.onElite(g)
 		return
 	}
-	gBits := g.GetBits()
 	ec.currentIter++
-	params := goga.ParseBitsToFloat64Arr(gBits)
+	params := decodeParams(g)
 	fmt.Println(ec.currentIter, "\t", params, "\tfunc value: ", g.GetOrigin(), "\tfitness: ", g.GetFitness())
 }
 
+// Result is the outcome of a run of an algorithm created by NewFuncAlgo
+// along with the parameters decoded from its elite
+type Result struct {
+	*goga.Result
+	Params []float64
+}
+
+// DecodeResult decodes the parameters of the elite of a run of an algorithm
+// created by NewFuncAlgo, it returns nil if 'r' is nil
+func DecodeResult(r *goga.Result) *Result {
+	if r == nil {
+		return nil
+	}
+	ret := &Result{Result: r}
+	if r.Elite != nil {
+		ret.Params = append([]float64{}, decodeParams(r.Elite)...)
+	}
+	return ret
+}
+
 type Options struct {
 	requirement     *goga.Float64Requirement
 	paramSize       int
@@ -120,6 +116,8 @@ type Options struct {
 	materExtraRatio int
 	lruSize         int
 	randomRatio     float64
+	direction       goga.Direction
+	scaling         goga.Scaling
 	onBegin         func() []goga.Genome
 	onEnd           func([]goga.Genome)
 	onElite         func(g goga.Genome)
@@ -212,6 +210,21 @@ func TransFunc(n func(float64) float64) Option {
 		o.transFunc = n
 	}
 }
+
+// Direction sets whether the function is maximised, the default, or minimised
+func Direction(n goga.Direction) Option {
+	return func(o *Options) {
+		o.direction = n
+	}
+}
+
+// Scaling sets how the function value is scaled for selection, see goga.FitnessScaling
+// As selection copes with negative values a TransFunc offset is not needed
+func Scaling(n goga.Scaling) Option {
+	return func(o *Options) {
+		o.scaling = n
+	}
+}
 func Function(n func([]float64) float64) Option {
 	return func(o *Options) {
 		o.function = n
@@ -274,12 +287,12 @@ func NewFuncAlgo(o ...Option) goga.GeneticAlgorithm {
 		onStable: opts.onStable,
 	}
 	genAlgo.Simulator = &s
-	genAlgo.BitsetCreate = &myBitsetCreate{paramsSize: opts.paramSize, requirement: opts.requirement}
+	genAlgo.GenomeCreate = &goga.RealGenomeCreate{Float64Requirement: *opts.requirement, Size: opts.paramSize}
 	genAlgo.EliteConsumer = &myEliteConsumer{
 		onElite: opts.onElite,
 	}
 	genAlgo.Mater = opts.mater
 	genAlgo.Selector = opts.selector
-	genAlgo.Init(goga.LRUSize(opts.lruSize), goga.PopulationSize(opts.populationSize), goga.ParallelSimulations(opts.numThreads), goga.MaterExtraRatio(opts.materExtraRatio), goga.RandomRatio(opts.randomRatio))
+	genAlgo.Init(goga.LRUSize(opts.lruSize), goga.PopulationSize(opts.populationSize), goga.ParallelSimulations(opts.numThreads), goga.MaterExtraRatio(opts.materExtraRatio), goga.RandomRatio(opts.randomRatio), goga.ObjectiveDirection(opts.direction), goga.FitnessScaling(opts.scaling))
 	return genAlgo
 }
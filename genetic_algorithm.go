@@ -1,8 +1,9 @@
 package goga
 
 import (
-	"sort"
-	"sync"
+	"context"
+	"io"
+	"math/rand"
 	"time"
 )
 
@@ -12,31 +13,90 @@ import (
 // * EliteConsumer - an optional class that accepts the 'elite' of each population generation
 // * Simulator - a simulation component used to score each genome in each generation
 // * BitsetCreate - used to create the initial population of genomes
+// * GenomeCreate - an optional component used in place of BitsetCreate for genomes that are
+// not held in a bitset, such as real-valued genomes
 type GeneticAlgorithm struct {
 	Mater         Mater
 	EliteConsumer EliteConsumer
 	Simulator     Simulator
 	Selector      Selector
 	BitsetCreate  BitsetCreate
+	GenomeCreate  GenomeCreate
 
-	populationSize          int
-	LRUSize                 int
-	MaterExtraRatio         int
-	randomRatio             float64
-	population              []Genome
-	totalFitness            float64
-	genomeSimulationChannel chan Genome
-	exitFunc                func(Genome) bool
-	waitGroup               *sync.WaitGroup
-	parallelSimulations     int
+	populationSize         int
+	LRUSize                int
+	MaterExtraRatio        int
+	randomRatio            float64
+	population             []Genome
+	totalFitness           float64
+	pool                   *simulationPool
+	exitFunc               func(Genome) bool
+	result                 *Result
+	lru                    *Cache
+	generation             int
+	elite                  Genome
+	generationStarted      bool
+	offspring              []Genome
+	filled                 int
+	queue                  []Genome
+	asked                  map[Genome]int
+	outstanding            int
+	parallelSimulations    int
+	maxGenerations         int
+	stagnationLimit        int
+	resumed                bool
+	checkpointEvery        int
+	checkpointCreate       func(generation int) (io.Writer, error)
+	source                 rand.Source
+	rand                   *rand.Rand
+	multiObjective         bool
+	scores                 map[Genome]float64
+	objectiveCount         int
+	direction              Direction
+	scaling                Scaling
+	selectionPopulation    []Genome
+	parents                []Genome
+	duplicates             DuplicatePolicy
+	maxDuplicateRejections int
+	rejected               int
+	reused                 []reusedGenome
+	cacheHits              int
+	cacheMisses            int
+	store                  FitnessStore
+	storeErr               error
+	failurePolicy          FailurePolicy
+	replaced               int
+	maxEvaluations         int
+	maxDuration            time.Duration
+	simulationTimeout      time.Duration
+	timeoutPenalty         float64
 }
 
 type Options struct {
-	PopulationSize      int
-	MaterExtraRatio     int
-	ParallelSimulations int
-	randomRatio         float64
-	LRUSize             int
+	PopulationSize         int
+	MaterExtraRatio        int
+	ParallelSimulations    int
+	randomRatio            float64
+	LRUSize                int
+	MaxGenerations         int
+	StagnationLimit        int
+	CheckpointEvery        int
+	CheckpointCreate       func(generation int) (io.Writer, error)
+	RandSource             rand.Source
+	MigrationInterval      int
+	Migrants               int
+	MigrationTopology      Topology
+	MultiObjective         bool
+	Direction              Direction
+	Scaling                Scaling
+	DuplicateGenomes       DuplicatePolicy
+	MaxDuplicateRejections int
+	FitnessStore           FitnessStore
+	FailurePolicy          FailurePolicy
+	MaxEvaluations         int
+	MaxDuration            time.Duration
+	SimulationTimeout      time.Duration
+	TimeoutPenalty         float64
 }
 type Option func(*Options)
 
@@ -69,6 +129,50 @@ func ParallelSimulations(n int) Option {
 	}
 }
 
+// MaxGenerations stops a run once 'n' generations have been simulated, 0 means no limit
+func MaxGenerations(n int) Option {
+	return func(o *Options) {
+		o.MaxGenerations = n
+	}
+}
+
+// StagnationLimit stops a run once the elite fitness has not improved
+// for 'n' generations, 0 means no limit
+func StagnationLimit(n int) Option {
+	return func(o *Options) {
+		o.StagnationLimit = n
+	}
+}
+
+// CheckpointEvery writes a checkpoint, see WriteCheckpoint, every 'n' generations of a run
+// The checkpoint is written to the writer returned by 'create', which is passed the number of
+// generations simulated so far, and the writer is closed afterwards if it is an io.Closer
+func CheckpointEvery(n int, create func(generation int) (io.Writer, error)) Option {
+	return func(o *Options) {
+		o.CheckpointEvery = n
+		o.CheckpointCreate = create
+	}
+}
+
+// RandSource makes the genetic algorithm draw all of its random numbers from 'src'
+// The generator is passed to every Selector, Mater, BitsetCreate and GenomeCreate that implements RandSetter
+// and a generator derived from it is given to each simulation, see RandFromContext
+// Runs with the same configuration and source state are reproducible as long as
+// the selector and mater functions draw from the generator they are passed, see
+// SelectorFunctionProbability and MaterFunctionProbability
+// A Source, as created by Seed, also has its state saved by checkpoints
+func RandSource(src rand.Source) Option {
+	return func(o *Options) {
+		o.RandSource = src
+	}
+}
+
+// Seed makes the genetic algorithm draw all of its random numbers from a Source seeded
+// with 'seed', see RandSource
+func Seed(seed int64) Option {
+	return RandSource(NewSource(seed))
+}
+
 // NewGeneticAlgorithm returns a new GeneticAlgorithm structure with null implementations of
 // EliteConsumer, Mater, Simulator, Selector and BitsetCreate
 func NewGeneticAlgorithm() GeneticAlgorithm {
@@ -84,78 +188,117 @@ func NewGeneticAlgorithm() GeneticAlgorithm {
 func (ga *GeneticAlgorithm) createPopulation() []Genome {
 	ret := make([]Genome, ga.populationSize)
 	for i := 0; i < ga.populationSize; i++ {
-		ret[i] = NewGenome(ga.BitsetCreate.Go())
+		ret[i] = ga.createGenome()
 	}
 	return ret
 }
 
+// createGenome returns a new genome from the GenomeCreate, if there is one,
+// or the BitsetCreate
+func (ga *GeneticAlgorithm) createGenome() Genome {
+	if ga.GenomeCreate != nil {
+		return ga.GenomeCreate.Go()
+	}
+	return NewGenome(ga.BitsetCreate.Go())
+}
+
 // Init initialises internal components, sets up the population size
 // and number of parallel simulations
 func (ga *GeneticAlgorithm) Init(opt ...Option) {
 	opts := Options{
-		PopulationSize:      10,
-		MaterExtraRatio:     2,
-		ParallelSimulations: 1,
-		randomRatio:         0.1,
-		LRUSize:             100000,
+		PopulationSize:         10,
+		MaterExtraRatio:        2,
+		ParallelSimulations:    1,
+		randomRatio:            0.1,
+		LRUSize:                100000,
+		MaxDuplicateRejections: 1000,
 	}
 	for _, o := range opt {
 		o(&opts)
 	}
 	ga.LRUSize = opts.LRUSize
 	ga.populationSize = opts.PopulationSize
+	ga.source = opts.RandSource
+	ga.rand = globalRand
+	if ga.source != nil {
+		ga.rand = rand.New(ga.source)
+	}
+	ga.shareRand()
 	ga.population = ga.createPopulation()
 	ga.parallelSimulations = opts.ParallelSimulations
 	ga.MaterExtraRatio = opts.MaterExtraRatio
-	ga.waitGroup = new(sync.WaitGroup)
+	ga.maxGenerations = opts.MaxGenerations
+	ga.stagnationLimit = opts.StagnationLimit
+	ga.multiObjective = opts.MultiObjective
+	ga.direction = opts.Direction
+	ga.scaling = opts.Scaling
+	ga.duplicates = opts.DuplicateGenomes
+	ga.maxDuplicateRejections = opts.MaxDuplicateRejections
+	ga.failurePolicy = opts.FailurePolicy
+	ga.maxEvaluations = opts.MaxEvaluations
+	ga.maxDuration = opts.MaxDuration
+	ga.simulationTimeout = opts.SimulationTimeout
+	ga.timeoutPenalty = opts.TimeoutPenalty
+	ga.checkpointEvery = opts.CheckpointEvery
+	ga.checkpointCreate = opts.CheckpointCreate
+	ga.pool = &simulationPool{}
+	ga.reset()
+	ga.store = opts.FitnessStore
+	ga.storeErr = nil
+	if warmer, ok := ga.store.(FitnessStoreWarmer); ok {
+		ga.storeErr = warmer.Warm()
+	}
 }
 
-func (ga *GeneticAlgorithm) beginSimulation() []Genome {
-	res := ga.Simulator.OnBeginSimulation()
-	ga.totalFitness = 0
-	for i := 0; i < len(ga.population); i++ {
-		ga.totalFitness += ga.population[i].GetFitness()
+// shareRand passes the random number generator of the genetic algorithm, if it was given one,
+// on to the components that can use it
+func (ga *GeneticAlgorithm) shareRand() {
+	if ga.source == nil {
+		return
 	}
-	ga.genomeSimulationChannel = make(chan Genome)
-
-	// todo: make configurable
-	for i := 0; i < ga.parallelSimulations; i++ {
-		go func(genomeSimulationChannel chan Genome,
-			waitGroup *sync.WaitGroup, simulator Simulator) {
-
-			for genome := range genomeSimulationChannel {
-				defer waitGroup.Done()
-				simulator.Simulate(genome)
-			}
-		}(ga.genomeSimulationChannel, ga.waitGroup, ga.Simulator)
+	for _, component := range []interface{}{ga.Selector, ga.Mater, ga.BitsetCreate, ga.GenomeCreate} {
+		if setter, ok := component.(RandSetter); ok {
+			setter.SetRand(ga.rand)
+		}
 	}
-	return res
-}
-
-func (ga *GeneticAlgorithm) onNewGenomeToSimulate(g Genome) {
-	ga.waitGroup.Add(1)
-	ga.genomeSimulationChannel <- g
 }
 
-func (ga *GeneticAlgorithm) syncSimulatingGenomes() {
-	close(ga.genomeSimulationChannel)
-	ga.waitGroup.Wait()
+// newSimulation prepares 'g' for a simulation worker, deriving the random number
+// generator for its simulation from the genetic algorithm's one, if it was given one
+func (ga *GeneticAlgorithm) newSimulation(g Genome) simulation {
+	s := simulation{
+		genome:    g,
+		simulator: ga.Simulator,
+		retries:   ga.failurePolicy.Retries,
+		timeout:   ga.simulationTimeout,
+		store:     ga.store,
+	}
+	if ga.source != nil {
+		s.rand = rand.New(NewSource(ga.rand.Int63()))
+	}
+	return s
 }
 
 func (ga *GeneticAlgorithm) getElite() Genome {
 	var ret Genome
 	for i := 0; i < ga.populationSize; i++ {
-		if ret == nil || ga.population[i].GetFitness() > ret.GetFitness() || (ga.population[i].GetFitness() == ret.GetFitness() && ga.population[i].GetOrigin() > ret.GetOrigin()) {
+		if ret == nil || ga.fitter(ga.population[i], ret) || (ga.score(ga.population[i]) == ga.score(ret) && ga.population[i].GetOrigin() > ret.GetOrigin()) {
 			ret = ga.population[i]
 		}
 	}
 	return ret
 }
 
+// fitter reports whether 'a' is strictly fitter than 'b', see ObjectiveDirection, by Pareto
+// score in multi-objective mode
+func (ga *GeneticAlgorithm) fitter(a, b Genome) bool {
+	return ga.direction.better(ga.score(a), ga.score(b))
+}
+
 // SimulateUntil simulates a population until 'exitFunc' returns true
 // The 'exitFunc' is passed the elite of each population and should return true
 // if the elite reaches a certain criteria (e.g. fitness above a certain threshold)
-func (ga *GeneticAlgorithm) SimulateUntil(exitFunc func(Genome) bool) bool {
+func (ga *GeneticAlgorithm) SimulateUntil(exitFunc func(Genome) bool) *Result {
 	ga.exitFunc = exitFunc
 	return ga.Simulate()
 }
@@ -167,79 +310,139 @@ func (ga *GeneticAlgorithm) shouldExit(elite Genome) bool {
 	return ga.exitFunc(elite)
 }
 
-// Simulate runs the genetic algorithm
-func (ga *GeneticAlgorithm) Simulate() bool {
+// shouldTerminate reports whether, and why, the run should stop after
+// the generation that has just been recorded
+func (ga *GeneticAlgorithm) shouldTerminate(elite Genome) (TerminationReason, bool) {
+	if ga.shouldExit(elite) {
+		return TerminationExitFunc, true
+	}
+	return ga.result.limitReached(ga.maxGenerations, ga.stagnationLimit, ga.maxEvaluations)
+}
+
+func (ga *GeneticAlgorithm) checkpoint(startTime time.Time) error {
+	if ga.checkpointEvery <= 0 || ga.result.Generations%ga.checkpointEvery != 0 {
+		return nil
+	}
+	w, err := ga.checkpointCreate(ga.result.Generations)
+	if err != nil {
+		return err
+	}
+	duration := ga.result.Duration
+	ga.result.Duration += time.Since(startTime)
+	err = ga.WriteCheckpoint(w)
+	ga.result.Duration = duration
+	if c, ok := w.(io.Closer); ok {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Simulate runs the genetic algorithm until the exit function returns true or
+// one of the MaxGenerations or StagnationLimit options stops it
+// A run that is stopped by an error, such as failing to write a checkpoint, has the
+// error in its result's Err
+// It returns nil if there is no population to simulate
+func (ga *GeneticAlgorithm) Simulate() *Result {
 	if ga.populationSize == 0 {
-		return false
+		return nil
 	}
-	extraGenomes := ga.beginSimulation()
-	for i := 0; i < len(extraGenomes); i++ {
-		ga.population[i] = extraGenomes[i]
+	result, _ := ga.simulate(context.Background())
+	return result
+}
+
+// SimulateContext runs the genetic algorithm like Simulate but also stops when 'ctx' is done
+// When 'ctx' is done no further genomes are handed to the simulator, the genomes already being
+// simulated are waited for and the result, holding the elite of the last fully simulated
+// generation, is returned along with ctx.Err()
+// The result's elite is nil if the context is done before the first generation is simulated
+func (ga *GeneticAlgorithm) SimulateContext(ctx context.Context) (*Result, error) {
+	if ga.populationSize == 0 {
+		return nil, nil
 	}
-	for i := 0; i < ga.populationSize; i++ {
-		ga.onNewGenomeToSimulate(ga.population[i])
+	return ga.simulate(ctx)
+}
+
+func (ga *GeneticAlgorithm) simulate(ctx context.Context) (*Result, error) {
+	if ga.resumed {
+		ga.resumed = false
+	} else {
+		ga.result = &Result{}
+		ga.reset()
 	}
-	ga.syncSimulatingGenomes()
-	ga.Simulator.OnEndSimulation(ga.population)
-	lru := New(ga.LRUSize)
+	ga.pool.evaluations = ga.result.Evaluations
+	ga.pool.maxEvaluations = ga.maxEvaluations
+	startTime := time.Now()
+	budgetCtx, cancel := withTimeBudget(ctx, ga.maxDuration, ga.result.Duration)
+	reason, err := ga.generations(budgetCtx, startTime)
+	cancel()
+	reason, err = budgetReason(ctx, reason, err)
+	ga.result.Duration += time.Since(startTime)
+	ga.result.Reason = reason
+	ga.result.Err = err
+	return ga.result, err
+}
+
+// generations drives the Ask and Tell API, simulating each genome that is asked
+// for on the simulation workers until the run should stop
+func (ga *GeneticAlgorithm) generations(ctx context.Context, startTime time.Time) (TerminationReason, error) {
 	for {
-		elite := ga.getElite()
-		ga.Mater.OnElite(elite)
-		ga.EliteConsumer.OnElite(elite)
-		if ga.shouldExit(elite) {
-			break
-		}
-		time.Sleep(1 * time.Microsecond)
-		extraGenomes = ga.beginSimulation()
-		newPopulationSize := ga.populationSize * ga.MaterExtraRatio
-		newPopulation := make([]Genome, newPopulationSize) //ga.createPopulation()
-		newPopulation[0] = elite
-		pos := 1
-		for i := 0; i < len(extraGenomes) && pos < newPopulationSize/2; i++ {
-			k := extraGenomes[i].Key()
-			if _, ok := lru.Get(k); !ok {
-				newPopulation[pos] = extraGenomes[i]
-				pos++
-				lru.Add(k, nil)
+		// todo: make configurable
+		ga.pool.begin(ctx, ga.parallelSimulations)
+		var simulated []Genome
+		for ctx.Err() == nil && !ga.pool.exhausted() {
+			genomes := ga.Ask(1)
+			if len(genomes) == 0 || !ga.pool.submit(ctx, ga.newSimulation(genomes[0])) {
+				break
 			}
+			simulated = append(simulated, genomes[0])
 		}
-		for i := 1; i < newPopulationSize; {
-			g1 := ga.Selector.Go(ga.population, ga.totalFitness)
-			g2 := ga.Selector.Go(ga.population, ga.totalFitness)
-			g3, g4 := ga.Mater.Go(g1, g2)
-			k := g3.Key()
-			if _, ok := lru.Get(k); !ok {
-				lru.Add(k, nil)
-				newPopulation[i] = g3
-				ga.onNewGenomeToSimulate(newPopulation[i])
-				i += 1
-			}
-			if i < newPopulationSize {
-				k = g4.Key()
-				if _, ok := lru.Get(k); !ok {
-					lru.Add(k, nil)
-					newPopulation[i] = g4
-					ga.onNewGenomeToSimulate(newPopulation[i])
-					i += 1
-				}
-			}
+		ga.pool.sync()
+		ga.result.Evaluations = ga.pool.evaluations
+		if err := ctx.Err(); err != nil {
+			return TerminationContext, err
 		}
-		ga.syncSimulatingGenomes()
-		sort.SliceStable(newPopulation, func(i, j int) bool {
-			return newPopulation[i].GetFitness() > newPopulation[j].GetFitness()
-		})
-		ga.population = make([]Genome, ga.populationSize)
-		for i := 0; i < ga.populationSize; i++ {
-			if i < int(float64(ga.populationSize)*(1.-ga.randomRatio)) {
-				ga.population[i] = newPopulation[i]
-			} else {
-				ga.population[i] = NewGenome(ga.BitsetCreate.Go())
-			}
+		failures := ga.pool.failed()
+		failed, timedOut := countFailures(failures)
+		ga.result.Failures += failed
+		ga.result.Timeouts += timedOut
+		hits, putErr := ga.pool.storeHits()
+		ga.countStoreHits(simulated, hits)
+		storeErr := ga.settle(simulated, failures)
+		if storeErr == nil {
+			storeErr = putErr
 		}
-		ga.Simulator.OnEndSimulation(ga.population)
-	}
+		if _, aborted := storeErr.(*SimulationError); aborted || (storeErr != nil && ga.generationStarted) {
+			return TerminationError, storeErr
+		}
+		if ga.generationStarted && ga.pool.exhausted() {
+			return TerminationEvaluationBudget, nil
+		}
+		if ga.generationStarted {
+			// The genomes that replace failed ones are still to be simulated
+			continue
+		}
+		ga.result.CacheHits, ga.result.CacheMisses = ga.CacheStats()
 
-	return true
+		ga.result.record(ga.elite, ga.direction)
+		if ga.multiObjective {
+			ga.result.ParetoFront = ga.ParetoFront()
+		}
+		if storeErr != nil {
+			return TerminationError, storeErr
+		}
+		if err := ga.checkpoint(startTime); err != nil {
+			return TerminationError, err
+		}
+		if reason, ok := ga.shouldTerminate(ga.elite); ok {
+			return reason, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return TerminationContext, err
+		}
+		time.Sleep(1 * time.Microsecond)
+	}
 }
 
 // GetPopulation returns the population
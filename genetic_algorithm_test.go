@@ -1,6 +1,8 @@
 package goga_test
 
 import (
+	"context"
+
 	"github.com/tomcraven/goga"
 	. "gopkg.in/check.v1"
 
@@ -39,7 +41,7 @@ func (s *GeneticAlgorithmSuite) TestShouldSimulateUntil(t *C) {
 	genAlgo := goga.NewGeneticAlgorithm()
 	genAlgo.Init(goga.PopulationSize(1), goga.ParallelSimulations(kNumThreads))
 	ret := genAlgo.SimulateUntil(exitFunc)
-	t.Assert(ret, IsTrue)
+	t.Assert(ret, NotNil)
 	t.Assert(callCount, Equals, 1)
 
 	callCount = 0
@@ -51,7 +53,7 @@ func (s *GeneticAlgorithmSuite) TestShouldSimulateUntil(t *C) {
 		return false
 	}
 	ret = genAlgo.SimulateUntil(exitFunc2)
-	t.Assert(ret, IsTrue)
+	t.Assert(ret, NotNil)
 	t.Assert(callCount, Equals, 2)
 }
 
@@ -77,20 +79,23 @@ func (s *GeneticAlgorithmSuite) TestShouldCallMaterAppropriately_1(t *C) {
 	)
 
 	genAlgo := goga.NewGeneticAlgorithm()
-	genAlgo.Init(goga.PopulationSize(2), goga.ParallelSimulations(kNumThreads))
+	populationSize := 2
+	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads), goga.MaxDuplicateRejections(0))
 	genAlgo.Mater = m
 
 	numIterations := 1000
 	ret := genAlgo.SimulateUntil(helperGenerateExitFunction(numIterations))
-	t.Assert(ret, IsTrue)
+	t.Assert(ret, NotNil)
 
-	sixtyPercent := (numIterations / 100) * 60
-	fourtyPercent := (numIterations / 100) * 40
+	// Each generation after the first mates populationSize times, see TestShouldCallMaterAppropriately_2
+	numMatings := (numIterations - 1) * populationSize
+	sixtyPercent := (numMatings / 100) * 60
+	fourtyPercent := (numMatings / 100) * 40
 	t.Assert(numCalls1 < sixtyPercent, IsTrue, Commentf("Num calls [%v] percent [%v]", numCalls1, sixtyPercent))
 	t.Assert(numCalls1 > fourtyPercent, IsTrue, Commentf("Num calls [%v] percent [%v]", numCalls1, fourtyPercent))
 
-	sixtyFivePercent := (numIterations / 100) * 65
-	eightyFivePercent := (numIterations / 100) * 85
+	sixtyFivePercent := (numMatings / 100) * 65
+	eightyFivePercent := (numMatings / 100) * 85
 	t.Assert(numCalls2 < eightyFivePercent, IsTrue, Commentf("Num calls [%v] percent [%v]", numCalls2, sixtyPercent))
 	t.Assert(numCalls2 > sixtyFivePercent, IsTrue, Commentf("Num calls [%v] percent [%v]", numCalls2, fourtyPercent))
 }
@@ -109,16 +114,19 @@ func (s *GeneticAlgorithmSuite) TestShouldCallMaterAppropriately_2(t *C) {
 		},
 	)
 
+	// Every genome bred from the default empty bitsets is a duplicate, accept them all rather
+	// than rejecting up to MaxDuplicateRejections of them and mating again for each
 	genAlgo := goga.NewGeneticAlgorithm()
 	populationSize := 100
-	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads))
+	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads), goga.MaxDuplicateRejections(0))
 	genAlgo.Mater = m
 
 	numIterations := 1000
 	genAlgo.SimulateUntil(helperGenerateExitFunction(numIterations))
 
-	expectedNumIterations := (numIterations * (populationSize / 2))
-	expectedNumIterations -= (populationSize / 2)
+	// Each generation after the first breeds populationSize * MaterExtraRatio genomes, the elite
+	// carried over and two genomes from each mating, which is populationSize matings
+	expectedNumIterations := (numIterations - 1) * populationSize
 	t.Assert(numCalls, Equals, expectedNumIterations)
 }
 
@@ -165,7 +173,7 @@ func (s *GeneticAlgorithmSuite) TestShouldCallIntoEliteConsumer(t *C) {
 
 	numIterations := 42
 	ret := genAlgo.SimulateUntil(helperGenerateExitFunction(numIterations))
-	t.Assert(ret, IsTrue)
+	t.Assert(ret, NotNil)
 	t.Assert(ec.NumCalls, Equals, numIterations)
 }
 
@@ -180,17 +188,17 @@ func (s *GeneticAlgorithmSuite) TestShouldNotSimulateWithNoPopulation(t *C) {
 	}
 	ret := genAlgo.SimulateUntil(exitFunc)
 
-	t.Assert(ret, IsFalse)
+	t.Assert(ret, IsNil)
 	t.Assert(callCount, Equals, 0)
 
 	genAlgo.Init(goga.PopulationSize(0), goga.ParallelSimulations(kNumThreads))
 	ret = genAlgo.SimulateUntil(exitFunc)
-	t.Assert(ret, IsFalse)
+	t.Assert(ret, IsNil)
 	t.Assert(callCount, Equals, 0)
 
 	genAlgo.Init(goga.PopulationSize(1), goga.ParallelSimulations(kNumThreads))
 	ret = genAlgo.SimulateUntil(exitFunc)
-	t.Assert(ret, IsTrue)
+	t.Assert(ret, NotNil)
 	t.Assert(callCount, Equals, 1)
 }
 
@@ -249,7 +257,10 @@ func (s *GeneticAlgorithmSuite) TestShouldSimulatePopulatonCounter(t *C) {
 
 	numIterations := 10
 	genAlgo.SimulateUntil(helperGenerateExitFunction(numIterations))
-	t.Assert(ms.NumCalls, Equals, numIterations*populationSize)
+
+	// The first generation simulates the population, every other one the populationSize *
+	// MaterExtraRatio genomes it breeds other than the elite, which is carried over
+	t.Assert(ms.NumCalls, Equals, populationSize+(numIterations-1)*(populationSize*2-1))
 }
 
 type MySimulatorFitness struct {
@@ -302,7 +313,20 @@ func (s *GeneticAlgorithmSuite) TestShouldSimulatePopulationAndPassEliteToConsum
 
 	genAlgo.SimulateUntil(helperGenerateExitFunction(numIterations))
 
-	t.Assert(ec.EliteFitnesses, DeepEquals, ms.LargestFitnessess)
+	t.Assert(ec.EliteFitnesses, DeepEquals, helperRunningMax(ms.LargestFitnessess))
+}
+
+// helperRunningMax returns the largest of 'values' up to each index, the fitness of the elite
+// of each generation when it is carried over to the next
+func helperRunningMax(values []int) []int {
+	ret := make([]int, len(values))
+	for i, v := range values {
+		ret[i] = v
+		if i > 0 && ret[i-1] > v {
+			ret[i] = ret[i-1]
+		}
+	}
+	return ret
 }
 
 type MySimulatorOrder struct {
@@ -379,7 +403,7 @@ func (s *GeneticAlgorithmSuite) TestShouldPassEliteToExitFunc(t *C) {
 
 	genAlgo.SimulateUntil(exitFunc)
 
-	t.Assert(passedGenomeFitnesses, DeepEquals, ms.LargestFitnessess)
+	t.Assert(passedGenomeFitnesses, DeepEquals, helperRunningMax(ms.LargestFitnessess))
 }
 
 func (s *GeneticAlgorithmSuite) TestShouldNotCallMaterWithGenomesFromPopulation(t *C) {
@@ -437,12 +461,14 @@ func (s *GeneticAlgorithmSuite) TestShouldCallSelectorAppropriately(t *C) {
 	genAlgo.Selector = &selector
 
 	populationSize := 100
-	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads))
+	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads), goga.MaxDuplicateRejections(0))
 	t.Assert(selector.CallCount, Equals, 0)
 
+	// Two parents are selected for each of the populationSize matings of a generation after
+	// the first, see TestShouldCallMaterAppropriately_2
 	numIterations := 100
 	genAlgo.SimulateUntil(helperGenerateExitFunction(numIterations))
-	t.Assert(selector.CallCount, Equals, (populationSize*numIterations)-populationSize)
+	t.Assert(selector.CallCount, Equals, 2*((populationSize*numIterations)-populationSize))
 }
 
 type MySelectorPassCache struct {
@@ -536,16 +562,21 @@ func (s *GeneticAlgorithmSuite) TestShouldReplaceOldPopulationWithMatedOne(t *C)
 	genAlgo := goga.NewGeneticAlgorithm()
 	genAlgo.Mater = &mater
 	populationSize := 10
-	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads))
+	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads),
+		goga.MaxDuplicateRejections(0), goga.RandomRatio(0))
 	genAlgo.SimulateUntil(helperGenerateExitFunction(2))
 
+	// The mater is asked for populationSize * MaterExtraRatio genomes, the last of which is not
+	// needed as the elite is carried over, and the fittest of them, fittest first, replace the
+	// population
 	genAlgoPopulation := genAlgo.GetPopulation()
-	t.Assert(mater.PassedGenomes, HasLen, populationSize)
+	t.Assert(mater.PassedGenomes, HasLen, populationSize*2)
 	t.Assert(genAlgoPopulation, HasLen, populationSize)
 
 	for i := 0; i < populationSize; i++ {
-		t.Assert(mater.PassedGenomes[i].GetFitness(), Equals, genAlgoPopulation[i].GetFitness())
-		t.Assert(mater.PassedGenomes[i], Equals, genAlgoPopulation[i])
+		bred := mater.PassedGenomes[populationSize*2-2-i]
+		t.Assert(bred.GetFitness(), Equals, genAlgoPopulation[i].GetFitness())
+		t.Assert(bred, Equals, genAlgoPopulation[i])
 	}
 }
 
@@ -585,6 +616,182 @@ func (s *GeneticAlgorithmSuite) TestShouldSimulateUsingSimulatorExitFunction(t *
 	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads))
 	genAlgo.Simulate()
 
-	t.Assert(ms.NumSimulateCalls, Equals, ms.NumBeginSimulationsUntilExit*populationSize)
+	// The elite is carried over rather than simulated again, see TestShouldSimulatePopulatonCounter
+	t.Assert(ms.NumSimulateCalls, Equals, populationSize+(ms.NumBeginSimulationsUntilExit-1)*(populationSize*2-1))
 	t.Assert(ms.NumBeginSimulationCalls, Equals, ms.NumBeginSimulationsUntilExit)
 }
+
+type MyRandomBitsetCreate struct {
+}
+
+func (gc *MyRandomBitsetCreate) Go() goga.Bitset {
+	b := goga.Bitset{}
+	b.Create(64)
+	for i := 0; i < b.GetSize(); i++ {
+		b.Set(i, rand.Intn(2))
+	}
+	return b
+}
+
+// helperGenerateMutatingGeneticAlgorithm returns a genetic algorithm whose genomes
+// are random and whose children are always mutated so they are rarely duplicates
+func helperGenerateMutatingGeneticAlgorithm() goga.GeneticAlgorithm {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.BitsetCreate = &MyRandomBitsetCreate{}
+	genAlgo.Selector = goga.NewSelector(
+		[]goga.SelectorFunctionProbability{
+			{P: 1, F: goga.RandomSelect},
+		},
+	)
+	genAlgo.Mater = goga.NewMater(
+		[]goga.MaterFunctionProbability{
+			{P: 1, F: goga.Mutate},
+			{P: 1, F: goga.UniformCrossover},
+		},
+	)
+	return genAlgo
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldNotSimulateWhenContextIsAlreadyDone(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+
+	ms := MySimulatorCounter{}
+	genAlgo.Simulator = &ms
+	genAlgo.Init(goga.PopulationSize(10), goga.ParallelSimulations(kNumThreads))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := genAlgo.SimulateContext(ctx)
+	t.Assert(err, Equals, context.Canceled)
+	t.Assert(result.Elite, IsNil)
+	t.Assert(ms.NumCalls, Equals, 0)
+}
+
+type MyEliteConsumerCancel struct {
+	NumCalls    int
+	CancelAfter int
+	Cancel      func()
+	LastElite   goga.Genome
+}
+
+func (ec *MyEliteConsumerCancel) OnElite(g goga.Genome) {
+	ec.NumCalls++
+	ec.LastElite = g
+	if ec.NumCalls >= ec.CancelAfter {
+		ec.Cancel()
+	}
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldStopBetweenGenerationsWhenContextIsCancelled(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorFitness{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ec := MyEliteConsumerCancel{CancelAfter: 5, Cancel: cancel}
+	genAlgo.EliteConsumer = &ec
+	genAlgo.Init(goga.PopulationSize(10), goga.ParallelSimulations(kNumThreads))
+
+	result, err := genAlgo.SimulateContext(ctx)
+	t.Assert(err, Equals, context.Canceled)
+	t.Assert(ec.NumCalls, Equals, 5)
+	t.Assert(result.Elite, Equals, ec.LastElite)
+	t.Assert(result.Reason, Equals, goga.TerminationContext)
+	t.Assert(result.Generations, Equals, 5)
+}
+
+type MySimulatorContext struct {
+	NumCalls int
+	m        sync.Mutex
+}
+
+func (ms *MySimulatorContext) Simulate(goga.Genome) {
+	panic("Simulate should not be called on a ContextSimulator")
+}
+func (ms *MySimulatorContext) SimulateContext(ctx context.Context, g goga.Genome) {
+	ms.m.Lock()
+	ms.NumCalls++
+	ms.m.Unlock()
+	<-ctx.Done()
+}
+func (ms *MySimulatorContext) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorContext) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorContext) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldPassContextToContextSimulator(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	ms := MySimulatorContext{}
+	genAlgo.Simulator = &ms
+	genAlgo.Init(goga.PopulationSize(100), goga.ParallelSimulations(kNumThreads))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := genAlgo.SimulateContext(ctx)
+	t.Assert(err, Equals, context.DeadlineExceeded)
+	t.Assert(result.Elite, IsNil)
+	t.Assert(ms.NumCalls <= kNumThreads, IsTrue, Commentf("Num calls [%v]", ms.NumCalls))
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldReturnResult(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	ms := MySimulatorFitness{}
+	genAlgo.Simulator = &ms
+	ec := MyEliteConsumerFitness{}
+	genAlgo.EliteConsumer = &ec
+
+	populationSize := 10
+	genAlgo.Init(goga.PopulationSize(populationSize), goga.ParallelSimulations(kNumThreads))
+
+	numIterations := 10
+	result := genAlgo.SimulateUntil(helperGenerateExitFunction(numIterations))
+	t.Assert(result.Reason, Equals, goga.TerminationExitFunc)
+	t.Assert(result.Generations, Equals, numIterations)
+	t.Assert(result.EliteFitnessHistory, HasLen, numIterations)
+	for i, fitness := range result.EliteFitnessHistory {
+		t.Assert(int(fitness), Equals, ec.EliteFitnesses[i])
+	}
+	t.Assert(result.Elite.GetFitness(), Equals, result.EliteFitnessHistory[numIterations-1])
+	t.Assert(result.Evaluations, Equals, populationSize+(numIterations-1)*(populationSize*2-1))
+	t.Assert(result.Duration > 0, IsTrue)
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldStopAtMaxGenerations(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorFitness{}
+	genAlgo.Init(goga.PopulationSize(10), goga.ParallelSimulations(kNumThreads), goga.MaxGenerations(7))
+
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Generations, Equals, 7)
+}
+
+type MySimulatorConstantFitness struct {
+}
+
+func (ms *MySimulatorConstantFitness) Simulate(g goga.Genome) {
+	g.SetFitness(1)
+}
+func (ms *MySimulatorConstantFitness) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorConstantFitness) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorConstantFitness) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+func (s *GeneticAlgorithmSuite) TestShouldStopWhenStagnant(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorConstantFitness{}
+	genAlgo.Init(goga.PopulationSize(10), goga.ParallelSimulations(kNumThreads), goga.StagnationLimit(3))
+
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationStagnation)
+	t.Assert(result.Generations, Equals, 4)
+	t.Assert(result.EliteFitnessHistory, DeepEquals, []float64{1, 1, 1, 1})
+}
@@ -10,10 +10,19 @@ type Genome interface {
 	Key() string
 }
 
+// MultiObjectiveGenome - an optional extension of the Genome interface for genomes
+// that are scored on several objectives, see the MultiObjective option
+// Genomes created by NewGenome implement it
+type MultiObjectiveGenome interface {
+	GetObjectives() []float64
+	SetObjectives([]float64)
+}
+
 type genome struct {
-	fitness float64
-	bitset  Bitset
-	origin  float64
+	fitness    float64
+	bitset     Bitset
+	origin     float64
+	objectives []float64
 }
 
 // NewGenome creates a genome with a bitset and
@@ -27,7 +36,7 @@ func (g *genome) GetFitness() float64 {
 }
 
 func (g *genome) Key() string {
-	return string(g.bitset.GetAll())
+	return g.bitset.key()
 }
 
 func (g *genome) SetFitness(fitness float64) {
@@ -45,3 +54,54 @@ func (g *genome) SetOrigin(origin float64) {
 func (g *genome) GetOrigin() float64 {
 	return g.origin
 }
+
+func (g *genome) GetObjectives() []float64 {
+	return g.objectives
+}
+
+func (g *genome) SetObjectives(objectives []float64) {
+	g.objectives = objectives
+}
+
+// Objectives returns the objectives of 'g', or its fitness as the only
+// objective if it has none
+func Objectives(g Genome) []float64 {
+	if mog, ok := g.(MultiObjectiveGenome); ok && mog.GetObjectives() != nil {
+		return mog.GetObjectives()
+	}
+	return []float64{g.GetFitness()}
+}
+
+// ParentGenome - an optional extension of the Genome interface for genomes that are not
+// held in a bitset, such as real-valued genomes or the trees of the gp package, so that their
+// offspring and copies keep their type
+// Child returns a new genome of the same type holding a copy of the genes of the genome
+// and a zero'd fitness score
+type ParentGenome interface {
+	Child() Genome
+}
+
+// childGenome returns a new genome holding the genes of 'g', with a zero'd fitness score
+func childGenome(g Genome) Genome {
+	if p, ok := g.(ParentGenome); ok {
+		return p.Child()
+	}
+	return NewGenome(*g.GetBits())
+}
+
+// copyGenome returns a copy of 'g', with its own genes, that has the same fitness, origin
+// and objectives
+func copyGenome(g Genome) Genome {
+	var ret Genome
+	if p, ok := g.(ParentGenome); ok {
+		ret = p.Child()
+	} else {
+		ret = NewGenome(g.GetBits().CreateCopy())
+	}
+	ret.SetFitness(g.GetFitness())
+	ret.SetOrigin(g.GetOrigin())
+	if mog, ok := g.(MultiObjectiveGenome); ok && mog.GetObjectives() != nil {
+		ret.(MultiObjectiveGenome).SetObjectives(append([]float64{}, mog.GetObjectives()...))
+	}
+	return ret
+}
@@ -23,10 +23,10 @@ func (s *GenomeSuite) TestShouldInstantiate(t *C) {
 }
 
 func (s *GenomeSuite) TestShouldSetGetFitness(t *C) {
-	t.Assert(s.genome.GetFitness(), Equals, 0)
+	t.Assert(s.genome.GetFitness(), Equals, 0.)
 
 	s.genome.SetFitness(100)
-	t.Assert(s.genome.GetFitness(), Equals, 100)
+	t.Assert(s.genome.GetFitness(), Equals, 100.)
 }
 
 func (s *GenomeSuite) TestShouldGetBits(t *C) {
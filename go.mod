@@ -1,6 +1,6 @@
 module github.com/tomcraven/goga
 
-go 1.17
+go 1.18
 
 require gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c
 
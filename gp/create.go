@@ -0,0 +1,36 @@
+package gp
+
+import (
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+)
+
+// Create - creates tree genomes from 'Primitives' by ramped half-and-half, a goga.GenomeCreate
+// Successive trees are built to each depth from MinDepth to MaxDepth in turn, half of them full,
+// with functions down to that depth, and half grown, with functions and terminals picked evenly
+// until that depth, which starts the population with trees of many shapes and sizes
+type Create struct {
+	Primitives *PrimitiveSet
+	MinDepth   int
+	MaxDepth   int
+	rand       *rand.Rand
+	created    int
+}
+
+// Go returns a tree genome of the next depth and method of the ramp
+func (c *Create) Go() goga.Genome {
+	depths := c.MaxDepth - c.MinDepth + 1
+	if depths < 1 {
+		depths = 1
+	}
+	depth := c.MinDepth + (c.created/2)%depths
+	full := c.created%2 == 0
+	c.created++
+	return NewTree(c.Primitives.generate(goga.RandOrGlobal(c.rand), c.Primitives.root, depth, full))
+}
+
+// SetRand sets the random number generator the trees are drawn from
+func (c *Create) SetRand(rng *rand.Rand) {
+	c.rand = rng
+}
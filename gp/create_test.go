@@ -0,0 +1,67 @@
+package gp_test
+
+import (
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/gp"
+	. "gopkg.in/check.v1"
+)
+
+type CreateSuite struct {
+}
+
+var _ = Suite(&CreateSuite{})
+
+func (s *CreateSuite) TestShouldRampDepthsHalfAndHalf(t *C) {
+	create := &gp.Create{Primitives: helperArithmetic(t), MinDepth: 1, MaxDepth: 3}
+	create.SetRand(rand.New(goga.NewSource(1)))
+	grownShallower := false
+	for i := 0; i < 60; i++ {
+		tree := create.Go().(*gp.Tree)
+		helperAssertWellTyped(t, tree.Root)
+		depth := 1 + (i/2)%3
+		if i%2 == 0 {
+			// Every function of the set is binary, so a full tree has 2^(depth+1) - 1 nodes
+			t.Assert(tree.Depth(), Equals, depth)
+			t.Assert(tree.Size(), Equals, 1<<uint(depth+1)-1)
+		} else {
+			t.Assert(tree.Depth() <= depth, Equals, true)
+			grownShallower = grownShallower || tree.Depth() < depth
+		}
+	}
+	t.Assert(grownShallower, Equals, true)
+}
+
+func (s *CreateSuite) TestShouldCreateWellTypedTrees(t *C) {
+	create := &gp.Create{Primitives: helperTyped(t), MinDepth: 2, MaxDepth: 5}
+	create.SetRand(rand.New(goga.NewSource(2)))
+	for i := 0; i < 100; i++ {
+		tree := create.Go().(*gp.Tree)
+		t.Assert(helperAssertWellTyped(t, tree.Root), Equals, gp.Float)
+		t.Assert(tree.Depth() <= 5, Equals, true)
+		_, ok := tree.Evaluate(0.5).(float64)
+		t.Assert(ok, Equals, true)
+	}
+}
+
+func (s *CreateSuite) TestShouldDrawEphemeralConstantsPerNode(t *C) {
+	primitives, err := gp.NewPrimitiveSet(gp.Float, gp.Add, digit)
+	t.Assert(err, IsNil)
+	create := &gp.Create{Primitives: primitives, MinDepth: 4, MaxDepth: 4}
+	create.SetRand(rand.New(goga.NewSource(3)))
+	tree := create.Go().(*gp.Tree)
+
+	values := map[interface{}]bool{}
+	var walk func(n *gp.Node)
+	walk = func(n *gp.Node) {
+		if n.Primitive == digit {
+			values[n.Value] = true
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(tree.Root)
+	t.Assert(len(values) > 1, Equals, true)
+}
@@ -0,0 +1,260 @@
+// Package gp evolves programs, trees of typed functions and terminals, with the
+// goga.GeneticAlgorithm loop
+//
+// A PrimitiveSet holds the functions and terminals trees are built from. Each primitive
+// returns a Type and each function takes arguments of given Types, so every tree is well typed:
+//
+//	primitives, err := gp.NewPrimitiveSet(gp.Float,
+//		gp.Add, gp.Sub, gp.Mul, gp.Div,
+//		gp.Var("x", gp.Float, 0),
+//		gp.Ephemeral("c", gp.Float, func(rng *rand.Rand) interface{} {
+//			return float64(rng.Intn(10))
+//		}),
+//	)
+//
+// Create builds the initial population with ramped half-and-half, Mater provides subtree
+// crossover and point, subtree and hoist mutation that keep trees within a depth limit, and a
+// Tree evaluates and prints itself as a Go expression. They plug into a goga.GeneticAlgorithm
+// as its GenomeCreate and as mater functions, so its Selector, EliteConsumer and parallel
+// simulations work as they do for any other genome:
+//
+//	genAlgo.GenomeCreate = &gp.Create{Primitives: primitives, MinDepth: 2, MaxDepth: 6}
+//	mater := gp.Mater{Primitives: primitives}
+//	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+//		{P: 0.9, R: mater.SubtreeCrossoverRand},
+//		{P: 0.1, R: mater.PointMutateRand},
+//	})
+//
+// Regression is a simulator for symbolic regression of Float trees
+package gp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+var (
+	// ErrInvalidPrimitive is returned when a primitive set is given a primitive that cannot be evaluated
+	ErrInvalidPrimitive = errors.New("invalid primitive")
+
+	// ErrMissingTerminal is returned when a primitive set has no terminal of a type a tree may need,
+	// the type of its programs or that of an argument of one of its functions, so trees of that type
+	// could not be finished
+	ErrMissingTerminal = errors.New("no terminal of type")
+)
+
+// Type - the type of the value of a primitive
+type Type string
+
+const (
+	// Float - the type of float64 values
+	Float Type = "float64"
+	// Bool - the type of bool values
+	Bool Type = "bool"
+)
+
+type primitiveKind int
+
+const (
+	function primitiveKind = iota
+	variable
+	constant
+	ephemeral
+)
+
+// Primitive - a function or terminal that trees are built from, see Func, Operator, Var,
+// Const and Ephemeral
+type Primitive struct {
+	kind     primitiveKind
+	name     string
+	ret      Type
+	args     []Type
+	eval     func(args []interface{}) interface{}
+	operator bool
+	index    int
+	value    interface{}
+	random   func(*rand.Rand) interface{}
+}
+
+// Func returns a function named 'name' that takes arguments of the types 'args' and returns
+// a value of type 'ret', 'eval' is passed the values of the arguments and returns the value
+// It prints as a call, name(arg, ...)
+func Func(name string, ret Type, args []Type, eval func(args []interface{}) interface{}) *Primitive {
+	return &Primitive{kind: function, name: name, ret: ret, args: args, eval: eval}
+}
+
+// Operator returns a function like Func that prints as the unary or binary Go operator 'op',
+// (op arg) or (arg op arg), so it must take 1 or 2 arguments
+func Operator(op string, ret Type, args []Type, eval func(args []interface{}) interface{}) *Primitive {
+	return &Primitive{kind: function, name: op, ret: ret, args: args, eval: eval, operator: true}
+}
+
+// Var returns a terminal of type 't' whose value is the variable at 'index' of those a tree
+// is evaluated with, it prints as 'name'
+func Var(name string, t Type, index int) *Primitive {
+	return &Primitive{kind: variable, name: name, ret: t, index: index}
+}
+
+// Const returns a terminal of type 't' whose value is always 'value', it prints as the Go
+// syntax of 'value'
+func Const(value interface{}, t Type) *Primitive {
+	return &Primitive{kind: constant, name: fmt.Sprintf("%#v", value), ret: t, value: value}
+}
+
+// Ephemeral returns a terminal of type 't' that is a constant drawn by 'random' when it
+// is placed in a tree, each node holding its own value
+func Ephemeral(name string, t Type, random func(*rand.Rand) interface{}) *Primitive {
+	return &Primitive{kind: ephemeral, name: name, ret: t, random: random}
+}
+
+// Name returns the name of the primitive
+func (p *Primitive) Name() string {
+	return p.name
+}
+
+// Type returns the type of the value of the primitive
+func (p *Primitive) Type() Type {
+	return p.ret
+}
+
+// Args returns the types of the arguments of the primitive, none for a terminal
+func (p *Primitive) Args() []Type {
+	return p.args
+}
+
+// validate returns an error if the primitive cannot be evaluated
+func (p *Primitive) validate() error {
+	valid := p.name != "" && p.ret != ""
+	switch p.kind {
+	case function:
+		valid = valid && p.eval != nil && len(p.args) > 0 &&
+			(!p.operator || len(p.args) == 1 || len(p.args) == 2)
+	case variable:
+		valid = valid && p.index >= 0
+	case ephemeral:
+		valid = valid && p.random != nil
+	}
+	if !valid {
+		return fmt.Errorf("%q: %w", p.name, ErrInvalidPrimitive)
+	}
+	return nil
+}
+
+// sameShape reports whether 'p' can take the place of 'other' in a tree without
+// changing its children
+func (p *Primitive) sameShape(other *Primitive) bool {
+	if p.ret != other.ret || len(p.args) != len(other.args) {
+		return false
+	}
+	for i := range p.args {
+		if p.args[i] != other.args[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// PrimitiveSet - the functions and terminals trees are built from, and the type of the
+// value of a whole tree, see NewPrimitiveSet
+type PrimitiveSet struct {
+	root      Type
+	functions map[Type][]*Primitive
+	terminals map[Type][]*Primitive
+}
+
+// NewPrimitiveSet returns a set of 'primitives' that builds trees returning values of type 'root'
+// ErrInvalidPrimitive is returned if a primitive cannot be evaluated, such as a function with no
+// arguments, and ErrMissingTerminal if there is no terminal of 'root' or of a type taken by a function
+func NewPrimitiveSet(root Type, primitives ...*Primitive) (*PrimitiveSet, error) {
+	ps := &PrimitiveSet{
+		root:      root,
+		functions: map[Type][]*Primitive{},
+		terminals: map[Type][]*Primitive{},
+	}
+	needed := []Type{root}
+	for _, p := range primitives {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		if p.kind == function {
+			ps.functions[p.ret] = append(ps.functions[p.ret], p)
+			needed = append(needed, p.args...)
+		} else {
+			ps.terminals[p.ret] = append(ps.terminals[p.ret], p)
+		}
+	}
+	for _, t := range needed {
+		if len(ps.terminals[t]) == 0 {
+			return nil, fmt.Errorf("%w %v", ErrMissingTerminal, t)
+		}
+	}
+	return ps, nil
+}
+
+// Root returns the type of the value of the trees built from the set
+func (ps *PrimitiveSet) Root() Type {
+	return ps.root
+}
+
+// node returns a node of 'p' with room for its children, drawing its value if it is ephemeral
+func (ps *PrimitiveSet) node(rng *rand.Rand, p *Primitive) *Node {
+	n := &Node{Primitive: p}
+	if len(p.args) > 0 {
+		n.Children = make([]*Node, len(p.args))
+	}
+	if p.kind == ephemeral {
+		n.Value = p.random(rng)
+	}
+	return n
+}
+
+// generate returns a random tree of type 't' that is at most 'depth' deep
+// A full tree has functions down to 'depth' wherever there is a function of the type needed,
+// otherwise functions and terminals are picked evenly until 'depth' is reached
+func (ps *PrimitiveSet) generate(rng *rand.Rand, t Type, depth int, full bool) *Node {
+	functions, terminals := ps.functions[t], ps.terminals[t]
+	var p *Primitive
+	if depth > 0 && len(functions) > 0 && (full || rng.Intn(len(functions)+len(terminals)) < len(functions)) {
+		p = functions[rng.Intn(len(functions))]
+	} else {
+		p = terminals[rng.Intn(len(terminals))]
+	}
+	n := ps.node(rng, p)
+	for i, arg := range p.args {
+		n.Children[i] = ps.generate(rng, arg, depth-1, full)
+	}
+	return n
+}
+
+// The arithmetic functions on Float values, for symbolic regression
+var (
+	Add = Operator("+", Float, []Type{Float, Float}, func(args []interface{}) interface{} {
+		return args[0].(float64) + args[1].(float64)
+	})
+	Sub = Operator("-", Float, []Type{Float, Float}, func(args []interface{}) interface{} {
+		return args[0].(float64) - args[1].(float64)
+	})
+	Mul = Operator("*", Float, []Type{Float, Float}, func(args []interface{}) interface{} {
+		return args[0].(float64) * args[1].(float64)
+	})
+	// Div is protected division, it returns 1 when dividing by 0 and prints as div(a, b)
+	Div = Func("div", Float, []Type{Float, Float}, func(args []interface{}) interface{} {
+		if args[1].(float64) == 0 {
+			return 1.
+		}
+		return args[0].(float64) / args[1].(float64)
+	})
+	// Neg negates its argument
+	Neg = Operator("-", Float, []Type{Float}, func(args []interface{}) interface{} {
+		return -args[0].(float64)
+	})
+	// Sin and Cos are math.Sin and math.Cos
+	Sin = Func("math.Sin", Float, []Type{Float}, func(args []interface{}) interface{} {
+		return math.Sin(args[0].(float64))
+	})
+	Cos = Func("math.Cos", Float, []Type{Float}, func(args []interface{}) interface{} {
+		return math.Cos(args[0].(float64))
+	})
+)
@@ -0,0 +1,139 @@
+package gp_test
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/gp"
+	. "gopkg.in/check.v1"
+)
+
+type GPSuite struct {
+}
+
+var _ = Suite(&GPSuite{})
+
+var (
+	x = gp.Var("x", gp.Float, 0)
+	y = gp.Var("y", gp.Float, 1)
+
+	digit = gp.Ephemeral("digit", gp.Float, func(rng *rand.Rand) interface{} {
+		return float64(rng.Intn(10))
+	})
+
+	less = gp.Operator("<", gp.Bool, []gp.Type{gp.Float, gp.Float}, func(args []interface{}) interface{} {
+		return args[0].(float64) < args[1].(float64)
+	})
+	and = gp.Operator("&&", gp.Bool, []gp.Type{gp.Bool, gp.Bool}, func(args []interface{}) interface{} {
+		return args[0].(bool) && args[1].(bool)
+	})
+	not = gp.Operator("!", gp.Bool, []gp.Type{gp.Bool}, func(args []interface{}) interface{} {
+		return !args[0].(bool)
+	})
+	choose = gp.Func("choose", gp.Float, []gp.Type{gp.Bool, gp.Float, gp.Float}, func(args []interface{}) interface{} {
+		if args[0].(bool) {
+			return args[1]
+		}
+		return args[2]
+	})
+)
+
+// helperArithmetic returns a primitive set of Float trees of x, y and digits
+func helperArithmetic(t *C) *gp.PrimitiveSet {
+	primitives, err := gp.NewPrimitiveSet(gp.Float, gp.Add, gp.Sub, gp.Mul, gp.Div, x, y, digit)
+	t.Assert(err, IsNil)
+	return primitives
+}
+
+// helperTyped returns a primitive set of Float trees that branch on Bool subtrees
+func helperTyped(t *C) *gp.PrimitiveSet {
+	primitives, err := gp.NewPrimitiveSet(gp.Float, gp.Add, choose, less, and, not, x, digit, gp.Const(true, gp.Bool))
+	t.Assert(err, IsNil)
+	return primitives
+}
+
+// helperAssertWellTyped asserts that the children of each node of 'n' are of the types its
+// primitive takes and returns the type of 'n'
+func helperAssertWellTyped(t *C, n *gp.Node) gp.Type {
+	t.Assert(n.Children, HasLen, len(n.Primitive.Args()))
+	for i, child := range n.Children {
+		t.Assert(helperAssertWellTyped(t, child), Equals, n.Primitive.Args()[i], Commentf("%v", n))
+	}
+	return n.Primitive.Type()
+}
+
+func (s *GPSuite) TestShouldEvaluateAndPrintTrees(t *C) {
+	// (x + 2) * div(y, 0)
+	tree := gp.NewTree(&gp.Node{Primitive: gp.Mul, Children: []*gp.Node{
+		{Primitive: gp.Add, Children: []*gp.Node{{Primitive: x}, {Primitive: digit, Value: 2.}}},
+		{Primitive: gp.Div, Children: []*gp.Node{{Primitive: y}, {Primitive: gp.Const(0., gp.Float)}}},
+	}})
+	t.Assert(tree.Evaluate(3., 5.), Equals, 5.)
+	t.Assert(tree.String(), Equals, "((x + 2) * div(y, 0))")
+	t.Assert(tree.Key(), Equals, tree.String())
+	t.Assert(tree.Depth(), Equals, 2)
+	t.Assert(tree.Size(), Equals, 7)
+
+	typed := gp.NewTree(&gp.Node{Primitive: choose, Children: []*gp.Node{
+		{Primitive: not, Children: []*gp.Node{{Primitive: less, Children: []*gp.Node{{Primitive: x}, {Primitive: gp.Const(1.5, gp.Float)}}}}},
+		{Primitive: gp.Sin, Children: []*gp.Node{{Primitive: x}}},
+		{Primitive: gp.Neg, Children: []*gp.Node{{Primitive: x}}},
+	}})
+	t.Assert(typed.String(), Equals, "choose((!(x < 1.5)), math.Sin(x), (-x))")
+	t.Assert(typed.Evaluate(1.), Equals, -1.)
+	t.Assert(typed.Evaluate(0.), Equals, -0.)
+}
+
+func (s *GPSuite) TestShouldParenthesiseNegativeOperands(t *C) {
+	tree := gp.NewTree(&gp.Node{Primitive: gp.Neg, Children: []*gp.Node{{Primitive: gp.Const(-1.5, gp.Float)}}})
+	t.Assert(tree.String(), Equals, "(-(-1.5))")
+	t.Assert(tree.Evaluate(), Equals, 1.5)
+
+	nested := gp.NewTree(&gp.Node{Primitive: gp.Neg, Children: []*gp.Node{tree.Root}})
+	t.Assert(nested.String(), Equals, "(-(-(-1.5)))")
+
+	ephemeral := gp.NewTree(&gp.Node{Primitive: gp.Neg, Children: []*gp.Node{{Primitive: digit, Value: -2.}}})
+	t.Assert(ephemeral.String(), Equals, "(-(-2))")
+}
+
+func (s *GPSuite) TestShouldCopyTrees(t *C) {
+	tree := gp.NewTree(&gp.Node{Primitive: gp.Add, Children: []*gp.Node{{Primitive: x}, {Primitive: digit, Value: 2.}}})
+	tree.SetFitness(3)
+	child := tree.Child().(*gp.Tree)
+	t.Assert(child.String(), Equals, tree.String())
+	t.Assert(child.GetFitness(), Equals, 0.)
+
+	child.Root.Children[1].Value = 4.
+	t.Assert(tree.String(), Equals, "(x + 2)")
+
+	// Trees survive the mater as trees
+	mated, _ := (&goga.NullMater{}).Go(tree, tree)
+	t.Assert(mated.(*gp.Tree).String(), Equals, "(x + 2)")
+
+	tree.SetObjectives([]float64{1, 2})
+	t.Assert(goga.Objectives(tree), DeepEquals, []float64{1, 2})
+}
+
+func (s *GPSuite) TestShouldRejectInvalidPrimitiveSets(t *C) {
+	for _, p := range []*gp.Primitive{
+		gp.Func("", gp.Float, []gp.Type{gp.Float}, func([]interface{}) interface{} { return 0. }),
+		gp.Func("f", gp.Float, nil, func([]interface{}) interface{} { return 0. }),
+		gp.Func("f", gp.Float, []gp.Type{gp.Float}, nil),
+		gp.Operator("?", gp.Float, []gp.Type{gp.Float, gp.Float, gp.Float}, func([]interface{}) interface{} { return 0. }),
+		gp.Var("v", gp.Float, -1),
+		gp.Ephemeral("e", gp.Float, nil),
+		gp.Var("v", "", 0),
+	} {
+		_, err := gp.NewPrimitiveSet(gp.Float, x, p)
+		t.Assert(errors.Is(err, gp.ErrInvalidPrimitive), Equals, true, Commentf("%v", p.Name()))
+	}
+
+	_, err := gp.NewPrimitiveSet(gp.Bool, x, gp.Add)
+	t.Assert(errors.Is(err, gp.ErrMissingTerminal), Equals, true)
+	t.Assert(err, ErrorMatches, ".* bool")
+
+	_, err = gp.NewPrimitiveSet(gp.Float, x, choose)
+	t.Assert(errors.Is(err, gp.ErrMissingTerminal), Equals, true)
+	t.Assert(err, ErrorMatches, ".* bool")
+}
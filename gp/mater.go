@@ -0,0 +1,177 @@
+package gp
+
+import (
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+)
+
+// DefaultMaxDepth is the deepest offspring of a Mater with no MaxDepth may be
+const DefaultMaxDepth = 17
+
+// Mater - crosses and mutates tree genomes built from 'Primitives', keeping them well typed
+// Offspring deeper than MaxDepth, or DefaultMaxDepth if it is 0, are replaced by a copy
+// of their parent, which stops trees growing without bound. MutationDepth is the deepest
+// subtree SubtreeMutate grows, it defaults to 2
+type Mater struct {
+	Primitives    *PrimitiveSet
+	MaxDepth      int
+	MutationDepth int
+}
+
+// trees returns copies of the trees of 'g1' and 'g2', it panics if either is not a tree
+func trees(g1, g2 goga.Genome) (*Tree, *Tree) {
+	t1, ok1 := g1.(*Tree)
+	t2, ok2 := g2.(*Tree)
+	if !ok1 || !ok2 {
+		panic("genome is not a tree")
+	}
+	return t1.Child().(*Tree), t2.Child().(*Tree)
+}
+
+// limit returns 'child', or a copy of 'parent' if 'child' is too deep
+func (m *Mater) limit(child *Tree, parent goga.Genome) goga.Genome {
+	maxDepth := m.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if child.Depth() > maxDepth {
+		return parent.(*Tree).Child()
+	}
+	return child
+}
+
+// randomSite returns a random site of 'candidates' for which 'accept' is true, or false if there is none
+func randomSite(rng *rand.Rand, candidates []site, accept func(site) bool) (site, bool) {
+	var accepted []site
+	for _, s := range candidates {
+		if accept(s) {
+			accepted = append(accepted, s)
+		}
+	}
+	if len(accepted) == 0 {
+		return site{}, false
+	}
+	return accepted[rng.Intn(len(accepted))], true
+}
+
+// typeOf returns the type of the node at 's'
+func typeOf(s site) Type {
+	return (*s.node).Primitive.ret
+}
+
+// SubtreeCrossover -
+// Accepts 2 tree genomes and swaps a random subtree of the first with a random subtree
+// of the same type of the second
+// i.e.
+// input genomes of:
+// (x + 1) and (x * (x - 2))
+// could produce output genomes of:
+// (x + (x - 2)) and (x * 1)
+func (m *Mater) SubtreeCrossover(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	return m.SubtreeCrossoverRand(goga.RandOrGlobal(nil), g1, g2)
+}
+
+// SubtreeCrossoverRand is SubtreeCrossover drawing from the random number generator 'rng'
+func (m *Mater) SubtreeCrossoverRand(rng *rand.Rand, g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	t1, t2 := trees(g1, g2)
+	s1 := sites(&t1.Root, 0)
+	a := s1[rng.Intn(len(s1))]
+	b, ok := randomSite(rng, sites(&t2.Root, 0), func(s site) bool {
+		return typeOf(s) == typeOf(a)
+	})
+	if !ok {
+		return t1, t2
+	}
+	*a.node, *b.node = *b.node, *a.node
+	return m.limit(t1, g1), m.limit(t2, g2)
+}
+
+// PointMutate -
+// Accepts 2 tree genomes and replaces the primitive of a random node of the first with another
+// that takes and returns the same types, keeping its children, or draws a new value for an
+// ephemeral constant
+// i.e.
+// input genomes of:
+// (x + 1) and (x * x)
+// could produce output genomes of:
+// (x - 1) and (x * x)
+func (m *Mater) PointMutate(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	return m.PointMutateRand(goga.RandOrGlobal(nil), g1, g2)
+}
+
+// PointMutateRand is PointMutate drawing from the random number generator 'rng'
+func (m *Mater) PointMutateRand(rng *rand.Rand, g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	t1, t2 := trees(g1, g2)
+	s1 := sites(&t1.Root, 0)
+	n := *s1[rng.Intn(len(s1))].node
+	var alternatives []*Primitive
+	candidates := m.Primitives.terminals[n.Primitive.ret]
+	if n.Primitive.kind == function {
+		candidates = m.Primitives.functions[n.Primitive.ret]
+	}
+	for _, p := range candidates {
+		if p.sameShape(n.Primitive) && (p != n.Primitive || p.kind == ephemeral) {
+			alternatives = append(alternatives, p)
+		}
+	}
+	if len(alternatives) > 0 {
+		p := alternatives[rng.Intn(len(alternatives))]
+		n.Primitive, n.Value = p, nil
+		if p.kind == ephemeral {
+			n.Value = p.random(rng)
+		}
+	}
+	return t1, t2
+}
+
+// SubtreeMutate -
+// Accepts 2 tree genomes and replaces a random subtree of the first with a grown tree of
+// the same type that is at most MutationDepth deep
+// i.e.
+// input genomes of:
+// (x + 1) and (x * x)
+// could produce output genomes of:
+// (x + (x * 3)) and (x * x)
+func (m *Mater) SubtreeMutate(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	return m.SubtreeMutateRand(goga.RandOrGlobal(nil), g1, g2)
+}
+
+// SubtreeMutateRand is SubtreeMutate drawing from the random number generator 'rng'
+func (m *Mater) SubtreeMutateRand(rng *rand.Rand, g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	t1, t2 := trees(g1, g2)
+	depth := m.MutationDepth
+	if depth <= 0 {
+		depth = 2
+	}
+	s1 := sites(&t1.Root, 0)
+	s := s1[rng.Intn(len(s1))]
+	*s.node = m.Primitives.generate(rng, typeOf(s), depth, false)
+	return m.limit(t1, g1), t2
+}
+
+// HoistMutate -
+// Accepts 2 tree genomes and replaces a random subtree of the first with a random subtree of
+// the same type within it, which shrinks the tree
+// i.e.
+// input genomes of:
+// (x + (x * 3)) and (x * x)
+// could produce output genomes of:
+// (x + 3) and (x * x)
+func (m *Mater) HoistMutate(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	return m.HoistMutateRand(goga.RandOrGlobal(nil), g1, g2)
+}
+
+// HoistMutateRand is HoistMutate drawing from the random number generator 'rng'
+func (m *Mater) HoistMutateRand(rng *rand.Rand, g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+	t1, t2 := trees(g1, g2)
+	s1 := sites(&t1.Root, 0)
+	s := s1[rng.Intn(len(s1))]
+	within, ok := randomSite(rng, sites(s.node, s.depth)[1:], func(w site) bool {
+		return typeOf(w) == typeOf(s)
+	})
+	if ok {
+		*s.node = *within.node
+	}
+	return t1, t2
+}
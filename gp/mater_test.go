@@ -0,0 +1,127 @@
+package gp_test
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/gp"
+	. "gopkg.in/check.v1"
+)
+
+type MaterSuite struct {
+}
+
+var _ = Suite(&MaterSuite{})
+
+type treeOperator func(*rand.Rand, goga.Genome, goga.Genome) (goga.Genome, goga.Genome)
+
+func helperTreeOperators(mater *gp.Mater) []treeOperator {
+	return []treeOperator{
+		mater.SubtreeCrossoverRand,
+		mater.PointMutateRand,
+		mater.SubtreeMutateRand,
+		mater.HoistMutateRand,
+	}
+}
+
+func (s *MaterSuite) TestShouldKeepTreesWellTypedAndWithinDepth(t *C) {
+	rng := rand.New(goga.NewSource(1))
+	primitives := helperTyped(t)
+	create := &gp.Create{Primitives: primitives, MinDepth: 1, MaxDepth: 5}
+	create.SetRand(rng)
+	mater := &gp.Mater{Primitives: primitives, MaxDepth: 6, MutationDepth: 3}
+	for _, operator := range helperTreeOperators(mater) {
+		for i := 0; i < 100; i++ {
+			parent1, parent2 := create.Go().(*gp.Tree), create.Go().(*gp.Tree)
+			expected1, expected2 := parent1.String(), parent2.String()
+
+			child1, child2 := operator(rng, parent1, parent2)
+			for _, child := range []goga.Genome{child1, child2} {
+				tree := child.(*gp.Tree)
+				t.Assert(helperAssertWellTyped(t, tree.Root), Equals, gp.Float)
+				t.Assert(tree.Depth() <= 6, Equals, true)
+			}
+
+			// The parents are left untouched
+			t.Assert(parent1.String(), Equals, expected1)
+			t.Assert(parent2.String(), Equals, expected2)
+		}
+	}
+}
+
+func (s *MaterSuite) TestShouldSwapSubtrees(t *C) {
+	rng := rand.New(goga.NewSource(2))
+	mater := &gp.Mater{Primitives: helperArithmetic(t)}
+	// x + y and x * x hold 5 nodes between them, however subtrees are swapped
+	parent1 := gp.NewTree(&gp.Node{Primitive: gp.Add, Children: []*gp.Node{{Primitive: x}, {Primitive: y}}})
+	parent2 := gp.NewTree(&gp.Node{Primitive: gp.Mul, Children: []*gp.Node{{Primitive: x}, {Primitive: x}}})
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		child1, child2 := mater.SubtreeCrossoverRand(rng, parent1, parent2)
+		c1, c2 := child1.(*gp.Tree), child2.(*gp.Tree)
+		t.Assert(c1.Size()+c2.Size(), Equals, 6)
+		t.Assert(strings.Count(c1.String()+c2.String(), "x"), Equals, 3)
+		t.Assert(strings.Count(c1.String()+c2.String(), "y"), Equals, 1)
+		seen[c1.String()] = true
+	}
+	t.Assert(seen["(x * x)"], Equals, true)
+	t.Assert(seen["(y + y)"], Equals, false)
+	t.Assert(len(seen) > 3, Equals, true)
+}
+
+func (s *MaterSuite) TestShouldReplaceTooDeepOffspringWithParents(t *C) {
+	rng := rand.New(goga.NewSource(3))
+	mater := &gp.Mater{Primitives: helperArithmetic(t), MaxDepth: 1}
+	deep := gp.NewTree(&gp.Node{Primitive: gp.Add, Children: []*gp.Node{
+		{Primitive: gp.Add, Children: []*gp.Node{{Primitive: x}, {Primitive: x}}},
+		{Primitive: x},
+	}})
+	shallow := gp.NewTree(&gp.Node{Primitive: gp.Mul, Children: []*gp.Node{{Primitive: y}, {Primitive: y}}})
+	for i := 0; i < 50; i++ {
+		_, child2 := mater.SubtreeCrossoverRand(rng, deep, shallow)
+		tree := child2.(*gp.Tree)
+		t.Assert(tree.Depth() <= 1, Equals, true)
+	}
+}
+
+func (s *MaterSuite) TestShouldPointMutateInPlace(t *C) {
+	rng := rand.New(goga.NewSource(4))
+	mater := &gp.Mater{Primitives: helperArithmetic(t)}
+	parent := gp.NewTree(&gp.Node{Primitive: gp.Add, Children: []*gp.Node{{Primitive: x}, {Primitive: digit, Value: 3.}}})
+	seen := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		child, _ := mater.PointMutateRand(rng, parent, parent)
+		tree := child.(*gp.Tree)
+		t.Assert(tree.Size(), Equals, 3)
+		seen[tree.String()] = true
+	}
+	for _, expected := range []string{"(x - 3)", "(x * 3)", "div(x, 3)", "(y + 3)", "(x + x)", "(x + 7)"} {
+		t.Assert(seen[expected], Equals, true, Commentf("%v", expected))
+	}
+}
+
+func (s *MaterSuite) TestShouldHoistSubtrees(t *C) {
+	rng := rand.New(goga.NewSource(5))
+	mater := &gp.Mater{Primitives: helperArithmetic(t)}
+	parent := gp.NewTree(&gp.Node{Primitive: gp.Add, Children: []*gp.Node{
+		{Primitive: x},
+		{Primitive: gp.Mul, Children: []*gp.Node{{Primitive: y}, {Primitive: digit, Value: 3.}}},
+	}})
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		child, _ := mater.HoistMutateRand(rng, parent, parent)
+		tree := child.(*gp.Tree)
+		t.Assert(tree.Size() <= parent.Size(), Equals, true)
+		seen[tree.String()] = true
+	}
+	for _, expected := range []string{"x", "(y * 3)", "3", "(x + y)", "(x + 3)", "(x + (y * 3))"} {
+		t.Assert(seen[expected], Equals, true, Commentf("%v", expected))
+	}
+}
+
+func (s *MaterSuite) TestShouldPanicWithNonTreeGenomes(t *C) {
+	mater := &gp.Mater{Primitives: helperArithmetic(t)}
+	tree := gp.NewTree(&gp.Node{Primitive: x})
+	t.Assert(func() { mater.SubtreeCrossover(goga.NewGenome(goga.Bitset{}), tree) }, Panics, "genome is not a tree")
+}
@@ -0,0 +1,54 @@
+package gp
+
+import (
+	"math"
+
+	"github.com/tomcraven/goga"
+)
+
+// Regression - a goga.Simulator for symbolic regression, it scores trees of Float values by
+// how closely they fit 'Targets' when evaluated with each row of 'Inputs' as their variables
+// The fitness is the negative mean squared error, so the genetic algorithm should maximise it,
+// and trees that evaluate to NaN or infinity score -math.MaxFloat64. Such scores can not be
+// summed, which goga.Roulette and goga.SUS cope with by picking by rank, so pair Regression with
+// those, a rank or tournament selector, and with goga.NoScaling or goga.RankScaling
+// The run exits once the elite's mean squared error is within 'Tolerance'
+type Regression struct {
+	Inputs    [][]float64
+	Targets   []float64
+	Tolerance float64
+}
+
+// Simulate sets the fitness of the tree 'g'
+func (r *Regression) Simulate(g goga.Genome) {
+	tree := g.(*Tree)
+	vars := []interface{}{}
+	sum := 0.
+	for i, inputs := range r.Inputs {
+		vars = vars[:0]
+		for _, v := range inputs {
+			vars = append(vars, v)
+		}
+		diff := tree.Evaluate(vars...).(float64) - r.Targets[i]
+		sum += diff * diff
+	}
+	fitness := -sum / float64(len(r.Inputs))
+	if math.IsNaN(fitness) || math.IsInf(fitness, 0) {
+		fitness = -math.MaxFloat64
+	}
+	g.SetFitness(fitness)
+}
+
+// OnBeginSimulation - does nothing
+func (r *Regression) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+
+// OnEndSimulation - does nothing
+func (r *Regression) OnEndSimulation([]goga.Genome) {
+}
+
+// ExitFunc returns true once the mean squared error of 'g' is within Tolerance
+func (r *Regression) ExitFunc(g goga.Genome) bool {
+	return -g.GetFitness() <= r.Tolerance
+}
@@ -0,0 +1,114 @@
+package gp_test
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/gp"
+	. "gopkg.in/check.v1"
+)
+
+type RegressionSuite struct {
+}
+
+var _ = Suite(&RegressionSuite{})
+
+// myEliteConsumer records the fitness of each elite
+type myEliteConsumer struct {
+	fitness []float64
+}
+
+func (ec *myEliteConsumer) OnElite(g goga.Genome) {
+	ec.fitness = append(ec.fitness, g.GetFitness())
+}
+
+// helperQuadratic returns a regression of x * x + x over [-1, 1]
+func helperQuadratic() *gp.Regression {
+	regression := &gp.Regression{Tolerance: 1e-9}
+	for i := -10; i <= 10; i++ {
+		v := float64(i) / 10
+		regression.Inputs = append(regression.Inputs, []float64{v})
+		regression.Targets = append(regression.Targets, v*v+v)
+	}
+	return regression
+}
+
+func (s *RegressionSuite) TestShouldScoreByMeanSquaredError(t *C) {
+	regression := &gp.Regression{Inputs: [][]float64{{1, 2}, {3, 4}}, Targets: []float64{3, 5}}
+	sum := gp.NewTree(&gp.Node{Primitive: gp.Add, Children: []*gp.Node{{Primitive: x}, {Primitive: y}}})
+	regression.Simulate(sum)
+	// Errors of 0 and 2
+	t.Assert(sum.GetFitness(), Equals, -2.)
+	t.Assert(regression.ExitFunc(sum), Equals, false)
+
+	regression.Tolerance = 2
+	t.Assert(regression.ExitFunc(sum), Equals, true)
+
+	infinite := gp.NewTree(&gp.Node{Primitive: gp.Const(math.Inf(1), gp.Float)})
+	regression.Simulate(infinite)
+	t.Assert(infinite.GetFitness(), Equals, -math.MaxFloat64)
+}
+
+func (s *RegressionSuite) TestShouldEvolveFormula(t *C) {
+	primitives, err := gp.NewPrimitiveSet(gp.Float, gp.Add, gp.Sub, gp.Mul, gp.Div, x, gp.Const(1., gp.Float))
+	t.Assert(err, IsNil)
+	mater := gp.Mater{Primitives: primitives, MaxDepth: 8}
+	eliteConsumer := &myEliteConsumer{}
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = helperQuadratic()
+	genAlgo.GenomeCreate = &gp.Create{Primitives: primitives, MinDepth: 1, MaxDepth: 4}
+	genAlgo.EliteConsumer = eliteConsumer
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, R: goga.TournamentRand(4, 1)},
+	})
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 0.9, R: mater.SubtreeCrossoverRand},
+		{P: 0.1, R: mater.PointMutateRand},
+		{P: 0.1, R: mater.SubtreeMutateRand},
+		{P: 0.05, R: mater.HoistMutateRand},
+	})
+	genAlgo.Init(goga.PopulationSize(100), goga.ParallelSimulations(4), goga.Seed(1), goga.MaxGenerations(50))
+	result := genAlgo.Simulate()
+
+	elite := result.Elite.(*gp.Tree)
+	t.Assert(result.Reason, Equals, goga.TerminationExitFunc, Commentf("%v scores %v", elite, elite.GetFitness()))
+	for _, v := range []float64{-3, 0.5, 7} {
+		t.Assert(math.Abs(elite.Evaluate(v).(float64)-(v*v+v)) < 1e-9, Equals, true, Commentf("%v", elite))
+	}
+	t.Assert(eliteConsumer.fitness, HasLen, result.Generations)
+}
+
+func (s *RegressionSuite) TestShouldRouletteWithDegenerateTrees(t *C) {
+	primitives, err := gp.NewPrimitiveSet(gp.Float, gp.Add, x, gp.Const(math.Inf(1), gp.Float))
+	t.Assert(err, IsNil)
+	mater := gp.Mater{Primitives: primitives, MaxDepth: 4}
+
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = helperQuadratic()
+	genAlgo.GenomeCreate = &gp.Create{Primitives: primitives, MinDepth: 1, MaxDepth: 3}
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, R: mater.SubtreeCrossoverRand},
+	})
+	genAlgo.Init(goga.PopulationSize(20), goga.ParallelSimulations(1), goga.Seed(1), goga.MaxGenerations(5))
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Elite.GetFitness() > -math.MaxFloat64, Equals, true)
+}
+
+func (s *RegressionSuite) TestShouldNotCheckpointTrees(t *C) {
+	primitives, err := gp.NewPrimitiveSet(gp.Float, gp.Add, gp.Mul, x)
+	t.Assert(err, IsNil)
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = helperQuadratic()
+	genAlgo.GenomeCreate = &gp.Create{Primitives: primitives, MinDepth: 1, MaxDepth: 2}
+	genAlgo.Init(goga.PopulationSize(10), goga.ParallelSimulations(1), goga.MaxGenerations(1))
+	genAlgo.Simulate()
+
+	buffer := bytes.Buffer{}
+	t.Assert(genAlgo.WriteCheckpoint(&buffer), Equals, goga.ErrUnsupportedGenome)
+}
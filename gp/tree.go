@@ -0,0 +1,199 @@
+package gp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tomcraven/goga"
+)
+
+// Node - a primitive in a tree and the subtrees that are its arguments
+// Value holds the constant of an ephemeral primitive
+type Node struct {
+	Primitive *Primitive
+	Value     interface{}
+	Children  []*Node
+}
+
+// Evaluate returns the value of the subtree with the variables 'vars'
+func (n *Node) Evaluate(vars ...interface{}) interface{} {
+	return n.evaluate(vars)
+}
+
+func (n *Node) evaluate(vars []interface{}) interface{} {
+	p := n.Primitive
+	switch p.kind {
+	case variable:
+		return vars[p.index]
+	case constant:
+		return p.value
+	case ephemeral:
+		return n.Value
+	}
+	args := make([]interface{}, len(n.Children))
+	for i, child := range n.Children {
+		args[i] = child.evaluate(vars)
+	}
+	return p.eval(args)
+}
+
+// String returns the subtree as a Go expression
+func (n *Node) String() string {
+	sb := strings.Builder{}
+	n.write(&sb)
+	return sb.String()
+}
+
+func (n *Node) write(sb *strings.Builder) {
+	p := n.Primitive
+	switch {
+	case p.kind == ephemeral:
+		fmt.Fprintf(sb, "%#v", n.Value)
+	case p.kind != function:
+		sb.WriteString(p.name)
+	case p.operator && len(n.Children) == 1:
+		// A signed operand such as a negative constant is parenthesised so that the
+		// result still parses, -(-1.5) rather than --1.5
+		operand := n.Children[0].String()
+		if strings.HasPrefix(operand, "-") || strings.HasPrefix(operand, "+") {
+			operand = "(" + operand + ")"
+		}
+		sb.WriteString("(" + p.name + operand + ")")
+	case p.operator:
+		sb.WriteString("(")
+		n.Children[0].write(sb)
+		sb.WriteString(" " + p.name + " ")
+		n.Children[1].write(sb)
+		sb.WriteString(")")
+	default:
+		sb.WriteString(p.name + "(")
+		for i, child := range n.Children {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			child.write(sb)
+		}
+		sb.WriteString(")")
+	}
+}
+
+// Depth returns the depth of the subtree, 0 for a terminal
+func (n *Node) Depth() int {
+	depth := 0
+	for _, child := range n.Children {
+		if d := child.Depth() + 1; d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// Size returns the number of nodes in the subtree
+func (n *Node) Size() int {
+	size := 1
+	for _, child := range n.Children {
+		size += child.Size()
+	}
+	return size
+}
+
+// copy returns a deep copy of the subtree
+func (n *Node) copy() *Node {
+	ret := &Node{Primitive: n.Primitive, Value: n.Value}
+	if n.Children != nil {
+		ret.Children = make([]*Node, len(n.Children))
+		for i, child := range n.Children {
+			ret.Children[i] = child.copy()
+		}
+	}
+	return ret
+}
+
+// site - where a node is held in a tree, so that it can be replaced, and how deep it is
+type site struct {
+	node  **Node
+	depth int
+}
+
+// sites returns the site of every node of the subtree held at 'node', which is 'depth' deep
+func sites(node **Node, depth int) []site {
+	ret := []site{{node: node, depth: depth}}
+	for i := range (*node).Children {
+		ret = append(ret, sites(&(*node).Children[i], depth+1)...)
+	}
+	return ret
+}
+
+// Tree - a genome holding a program, a tree of primitives, see NewTree
+// It is not held in a bitset, GetBits returns an empty bitset, so bit operators should
+// not be used on it, see Mater, and it cannot be checkpointed, WriteCheckpoint returns
+// goga.ErrUnsupportedGenome
+type Tree struct {
+	Root       *Node
+	fitness    float64
+	origin     float64
+	objectives []float64
+}
+
+// NewTree creates a tree genome holding the tree at 'root' and a zero'd fitness score
+func NewTree(root *Node) *Tree {
+	return &Tree{Root: root}
+}
+
+// Evaluate returns the value of the tree with the variables 'vars'
+func (t *Tree) Evaluate(vars ...interface{}) interface{} {
+	return t.Root.evaluate(vars)
+}
+
+// String returns the tree as a Go expression
+func (t *Tree) String() string {
+	return t.Root.String()
+}
+
+// Depth returns the depth of the tree, 0 for a single terminal
+func (t *Tree) Depth() int {
+	return t.Root.Depth()
+}
+
+// Size returns the number of nodes in the tree
+func (t *Tree) Size() int {
+	return t.Root.Size()
+}
+
+func (t *Tree) GetFitness() float64 {
+	return t.fitness
+}
+
+func (t *Tree) SetFitness(fitness float64) {
+	t.fitness = fitness
+}
+
+func (t *Tree) GetBits() *goga.Bitset {
+	return &goga.Bitset{}
+}
+
+func (t *Tree) GetOrigin() float64 {
+	return t.origin
+}
+
+func (t *Tree) SetOrigin(origin float64) {
+	t.origin = origin
+}
+
+// Key returns the tree as a Go expression, equal trees print the same
+func (t *Tree) Key() string {
+	return t.String()
+}
+
+func (t *Tree) GetObjectives() []float64 {
+	return t.objectives
+}
+
+func (t *Tree) SetObjectives(objectives []float64) {
+	t.objectives = objectives
+}
+
+// Child returns a tree genome holding a copy of the tree
+func (t *Tree) Child() goga.Genome {
+	return NewTree(t.Root.copy())
+}
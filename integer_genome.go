@@ -0,0 +1,268 @@
+package goga
+
+import (
+	"math/rand"
+)
+
+// Domain - the values a gene of an integer genome may take, every integer from Min to
+// Max, inclusive, or, when Values is set, one of Values, see Range and OneOf
+type Domain struct {
+	Min    int
+	Max    int
+	Values []int
+}
+
+// Range returns a domain of every integer from 'min' to 'max', inclusive
+// A boolean flag is Range(0, 1)
+func Range(min, max int) Domain {
+	return Domain{Min: min, Max: max}
+}
+
+// OneOf returns a domain of the categories 'values', their order is the one creep
+// mutation steps through
+func OneOf(values ...int) Domain {
+	return Domain{Values: values}
+}
+
+// Contains returns whether 'v' is in the domain
+func (d Domain) Contains(v int) bool {
+	if d.Values == nil {
+		return v >= d.Min && v <= d.Max
+	}
+	return d.index(v) >= 0
+}
+
+// index returns the index of 'v' in the values of the domain, or -1 if it is not one of them
+func (d Domain) index(v int) int {
+	for i, value := range d.Values {
+		if value == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// random returns a value drawn evenly from the domain
+func (d Domain) random(rng *rand.Rand) int {
+	if d.Values == nil {
+		return d.Min + rng.Intn(d.Max-d.Min+1)
+	}
+	return d.Values[rng.Intn(len(d.Values))]
+}
+
+// creep returns 'v' moved up or down by 1 to 'size' steps, kept within the domain
+// Categories step to a neighbouring value, a value outside of the domain is replaced
+// by a random one
+func (d Domain) creep(rng *rand.Rand, v, size int) int {
+	step := rng.Intn(size) + 1
+	if rng.Intn(2) == 0 {
+		step = -step
+	}
+	if d.Values == nil {
+		if !d.Contains(v) {
+			return d.random(rng)
+		}
+		return clampInt(v+step, d.Min, d.Max)
+	}
+	i := d.index(v)
+	if i < 0 {
+		return d.random(rng)
+	}
+	return d.Values[clampInt(i+step, 0, len(d.Values)-1)]
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// IntegerGenome - a genome of integers, each taken from the domain of its gene, see NewIntegerGenome
+type IntegerGenome interface {
+	Genome
+	GetValues() []int
+}
+
+type integerGenome struct {
+	values     []int
+	fitness    float64
+	origin     float64
+	objectives []float64
+}
+
+// NewIntegerGenome creates an integer genome holding 'values' and a zero'd fitness score
+// Its bits, as returned by GetBits, are a copy of its values, 64 bits per value that
+// can be read with Bitset.Uint64, so bit operators should not be used on it, see
+// IntegerUniformCrossover and IntegerMater
+func NewIntegerGenome(values []int) IntegerGenome {
+	return &integerGenome{values: values}
+}
+
+func (g *integerGenome) GetValues() []int {
+	return g.values
+}
+
+func (g *integerGenome) GetFitness() float64 {
+	return g.fitness
+}
+
+func (g *integerGenome) SetFitness(fitness float64) {
+	g.fitness = fitness
+}
+
+func (g *integerGenome) GetBits() *Bitset {
+	return intsBits(g.values)
+}
+
+func (g *integerGenome) GetOrigin() float64 {
+	return g.origin
+}
+
+func (g *integerGenome) SetOrigin(origin float64) {
+	g.origin = origin
+}
+
+func (g *integerGenome) Key() string {
+	return intsKey(g.values)
+}
+
+func (g *integerGenome) GetObjectives() []float64 {
+	return g.objectives
+}
+
+func (g *integerGenome) SetObjectives(objectives []float64) {
+	g.objectives = objectives
+}
+
+// Child returns a genome holding a copy of the values of the genome
+func (g *integerGenome) Child() Genome {
+	return NewIntegerGenome(append([]int{}, g.values...))
+}
+
+// IntegerGenomeCreate - creates integer genomes of one gene per domain, each drawn
+// evenly from its domain
+type IntegerGenomeCreate struct {
+	Domains []Domain
+	rand    *rand.Rand
+}
+
+// Go returns an integer genome of random values
+func (igc *IntegerGenomeCreate) Go() Genome {
+	rng := RandOrGlobal(igc.rand)
+	values := make([]int, len(igc.Domains))
+	for i, d := range igc.Domains {
+		values[i] = d.random(rng)
+	}
+	return NewIntegerGenome(values)
+}
+
+// SetRand sets the random number generator the values are drawn from
+func (igc *IntegerGenomeCreate) SetRand(rng *rand.Rand) {
+	igc.rand = rng
+}
+
+// integers returns copies of the values of 'g1' and 'g2', it panics if either genome
+// is not an IntegerGenome or if they are of different lengths
+func integers(g1, g2 Genome) ([]int, []int) {
+	ig1, ok1 := g1.(IntegerGenome)
+	ig2, ok2 := g2.(IntegerGenome)
+	if !ok1 || !ok2 {
+		panic("genome is not an integer genome")
+	}
+	if len(ig1.GetValues()) != len(ig2.GetValues()) {
+		panic("integer genomes are of different lengths")
+	}
+	return append([]int{}, ig1.GetValues()...), append([]int{}, ig2.GetValues()...)
+}
+
+// IntegerUniformCrossover -
+// Accepts 2 integer genomes and swaps each of their genes with a probability of 0.5
+func IntegerUniformCrossover(g1, g2 Genome) (Genome, Genome) {
+	return IntegerUniformCrossoverRand(globalRand, g1, g2)
+}
+
+// IntegerUniformCrossoverRand is IntegerUniformCrossover drawing from the random number generator 'rng'
+func IntegerUniformCrossoverRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	v1, v2 := integers(g1, g2)
+	for i := range v1 {
+		if rng.Intn(2) == 0 {
+			v1[i], v2[i] = v2[i], v1[i]
+		}
+	}
+	return NewIntegerGenome(v1), NewIntegerGenome(v2)
+}
+
+// IntegerOnePointCrossover -
+// Accepts 2 integer genomes and swaps their genes after a random point, leaving at least
+// one gene of each genome in place
+func IntegerOnePointCrossover(g1, g2 Genome) (Genome, Genome) {
+	return IntegerOnePointCrossoverRand(globalRand, g1, g2)
+}
+
+// IntegerOnePointCrossoverRand is IntegerOnePointCrossover drawing from the random number generator 'rng'
+func IntegerOnePointCrossoverRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	v1, v2 := integers(g1, g2)
+	if len(v1) > 1 {
+		for i := rng.Intn(len(v1)-1) + 1; i < len(v1); i++ {
+			v1[i], v2[i] = v2[i], v1[i]
+		}
+	}
+	return NewIntegerGenome(v1), NewIntegerGenome(v2)
+}
+
+// IntegerMater - mutates integer genomes within the domain of each gene
+// There must be a domain for each gene, CreepSize is the most steps a creep mutation
+// moves a gene and defaults to 1
+type IntegerMater struct {
+	Domains   []Domain
+	CreepSize int
+}
+
+// mutate returns a child of 'g1', with the value of a random gene changed by 'mutate', and of 'g2'
+func (im *IntegerMater) mutate(rng *rand.Rand, g1, g2 Genome, mutate func(d Domain, v int) int) (Genome, Genome) {
+	v1, v2 := integers(g1, g2)
+	if len(v1) != len(im.Domains) {
+		panic("genes and domains are of different lengths")
+	}
+	if len(v1) > 0 {
+		i := rng.Intn(len(v1))
+		v1[i] = mutate(im.Domains[i], v1[i])
+	}
+	return NewIntegerGenome(v1), NewIntegerGenome(v2)
+}
+
+// CreepMutate -
+// Accepts 2 integer genomes and moves a random gene of the first up or down by up to
+// CreepSize steps, within its domain
+func (im *IntegerMater) CreepMutate(g1, g2 Genome) (Genome, Genome) {
+	return im.CreepMutateRand(globalRand, g1, g2)
+}
+
+// CreepMutateRand is CreepMutate drawing from the random number generator 'rng'
+func (im *IntegerMater) CreepMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	size := im.CreepSize
+	if size < 1 {
+		size = 1
+	}
+	return im.mutate(rng, g1, g2, func(d Domain, v int) int {
+		return d.creep(rng, v, size)
+	})
+}
+
+// RandomResetMutate -
+// Accepts 2 integer genomes and sets a random gene of the first to a value drawn
+// evenly from its domain
+func (im *IntegerMater) RandomResetMutate(g1, g2 Genome) (Genome, Genome) {
+	return im.RandomResetMutateRand(globalRand, g1, g2)
+}
+
+// RandomResetMutateRand is RandomResetMutate drawing from the random number generator 'rng'
+func (im *IntegerMater) RandomResetMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	return im.mutate(rng, g1, g2, func(d Domain, v int) int {
+		return d.random(rng)
+	})
+}
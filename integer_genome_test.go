@@ -0,0 +1,255 @@
+package goga_test
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type IntegerGenomeSuite struct {
+}
+
+var _ = Suite(&IntegerGenomeSuite{})
+
+// integerDomains - a count, a flag and one of a few categories
+var integerDomains = []goga.Domain{
+	goga.Range(1, 64),
+	goga.Range(0, 1),
+	goga.OneOf(3, 7, 11, 13, 17),
+}
+
+// MySimulatorIntegerTarget scores integer genomes by how close they are to {50, 1, 13}
+type MySimulatorIntegerTarget struct {
+}
+
+func (ms *MySimulatorIntegerTarget) Simulate(g goga.Genome) {
+	v := g.(goga.IntegerGenome).GetValues()
+	fitness := -abs(v[0]-50) - abs(v[1]-1)*10 - abs(v[2]-13)
+	g.SetFitness(float64(fitness))
+}
+func (ms *MySimulatorIntegerTarget) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorIntegerTarget) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorIntegerTarget) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// helperGenerateIntegerGeneticAlgorithm keeps the duplicate genome cache smaller than the 640
+// genomes the domains allow, so a converged run can still find offspring it has not seen
+func helperGenerateIntegerGeneticAlgorithm(opt ...goga.Option) goga.GeneticAlgorithm {
+	integerMater := goga.IntegerMater{Domains: integerDomains, CreepSize: 4}
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorIntegerTarget{}
+	genAlgo.GenomeCreate = &goga.IntegerGenomeCreate{Domains: integerDomains}
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, R: goga.TournamentRand(3, 1)},
+	})
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, R: goga.IntegerUniformCrossoverRand},
+		{P: 0.5, R: integerMater.CreepMutateRand},
+		{P: 0.2, R: integerMater.RandomResetMutateRand},
+	})
+	genAlgo.Init(append([]goga.Option{goga.PopulationSize(30), goga.ParallelSimulations(kNumThreads), goga.Seed(7), goga.LRUSize(200)}, opt...)...)
+	return genAlgo
+}
+
+func helperAssertIntegersInDomains(t *C, g goga.Genome) []int {
+	values := g.(goga.IntegerGenome).GetValues()
+	t.Assert(values, HasLen, len(integerDomains))
+	for i, v := range values {
+		t.Assert(integerDomains[i].Contains(v), IsTrue, Commentf("value %v of %v", i, v))
+	}
+	return values
+}
+
+func (s *IntegerGenomeSuite) TestShouldHoldValues(t *C) {
+	g := goga.NewIntegerGenome([]int{5, -1, 0})
+	t.Assert(g.GetValues(), DeepEquals, []int{5, -1, 0})
+
+	bits := g.GetBits()
+	t.Assert(bits.GetSize(), Equals, 3*64)
+	first, _ := bits.Uint64(0, 64)
+	t.Assert(first, Equals, uint64(5))
+
+	t.Assert(g.Key(), Equals, goga.NewIntegerGenome([]int{5, -1, 0}).Key())
+	t.Assert(g.Key() == goga.NewIntegerGenome([]int{5, -1, 1}).Key(), IsFalse)
+
+	g.(goga.MultiObjectiveGenome).SetObjectives([]float64{1, 2})
+	t.Assert(goga.Objectives(g), DeepEquals, []float64{1, 2})
+}
+
+func (s *IntegerGenomeSuite) TestShouldContainDomainValues(t *C) {
+	t.Assert(goga.Range(1, 3).Contains(1), IsTrue)
+	t.Assert(goga.Range(1, 3).Contains(3), IsTrue)
+	t.Assert(goga.Range(1, 3).Contains(4), IsFalse)
+	t.Assert(goga.Range(1, 3).Contains(0), IsFalse)
+	t.Assert(goga.OneOf(2, 4).Contains(4), IsTrue)
+	t.Assert(goga.OneOf(2, 4).Contains(3), IsFalse)
+}
+
+func (s *IntegerGenomeSuite) TestShouldCreateValuesWithinDomains(t *C) {
+	create := &goga.IntegerGenomeCreate{Domains: integerDomains}
+	create.SetRand(rand.New(goga.NewSource(1)))
+	seen := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		values := helperAssertIntegersInDomains(t, create.Go())
+		seen[values[2]] = true
+	}
+	t.Assert(seen, HasLen, 5)
+
+	create1 := &goga.IntegerGenomeCreate{Domains: integerDomains}
+	create1.SetRand(rand.New(goga.NewSource(2)))
+	create2 := &goga.IntegerGenomeCreate{Domains: integerDomains}
+	create2.SetRand(rand.New(goga.NewSource(2)))
+	t.Assert(create1.Go().Key(), Equals, create2.Go().Key())
+}
+
+func (s *IntegerGenomeSuite) TestShouldCrossGenesInPlace(t *C) {
+	rng := rand.New(goga.NewSource(1))
+	parent1 := goga.NewIntegerGenome([]int{1, 2, 3, 4, 5})
+	parent2 := goga.NewIntegerGenome([]int{-1, -2, -3, -4, -5})
+	for _, crossover := range []func(*rand.Rand, goga.Genome, goga.Genome) (goga.Genome, goga.Genome){
+		goga.IntegerUniformCrossoverRand,
+		goga.IntegerOnePointCrossoverRand,
+	} {
+		for i := 0; i < 20; i++ {
+			child1, child2 := crossover(rng, parent1, parent2)
+			c1, c2 := child1.(goga.IntegerGenome).GetValues(), child2.(goga.IntegerGenome).GetValues()
+			for j := range c1 {
+				// Each gene stays at its index and goes to one child or the other
+				t.Assert(c1[j] == j+1 && c2[j] == -j-1 || c1[j] == -j-1 && c2[j] == j+1, IsTrue)
+			}
+		}
+	}
+
+	// A one point crossover swaps a tail and leaves the first gene in place
+	for i := 0; i < 20; i++ {
+		child1, _ := goga.IntegerOnePointCrossoverRand(rng, parent1, parent2)
+		c1 := child1.(goga.IntegerGenome).GetValues()
+		t.Assert(c1[0], Equals, 1)
+		t.Assert(c1[4], Equals, -5)
+		for j := 1; j < len(c1); j++ {
+			t.Assert(c1[j-1] < 0 && c1[j] > 0, IsFalse)
+		}
+	}
+
+	// The parents are left untouched
+	t.Assert(parent1.GetValues(), DeepEquals, []int{1, 2, 3, 4, 5})
+	t.Assert(parent2.GetValues(), DeepEquals, []int{-1, -2, -3, -4, -5})
+}
+
+func (s *IntegerGenomeSuite) TestShouldMutateWithinDomains(t *C) {
+	rng := rand.New(goga.NewSource(2))
+	integerMater := goga.IntegerMater{Domains: integerDomains}
+	for _, mutation := range []func(*rand.Rand, goga.Genome, goga.Genome) (goga.Genome, goga.Genome){
+		integerMater.CreepMutateRand,
+		integerMater.RandomResetMutateRand,
+	} {
+		for i := 0; i < 200; i++ {
+			parent1 := goga.NewIntegerGenome([]int{64, 0, 17})
+			parent2 := goga.NewIntegerGenome([]int{1, 1, 3})
+			child1, child2 := mutation(rng, parent1, parent2)
+			helperAssertIntegersInDomains(t, child1)
+			t.Assert(child2.(goga.IntegerGenome).GetValues(), DeepEquals, []int{1, 1, 3})
+			t.Assert(parent1.GetValues(), DeepEquals, []int{64, 0, 17})
+		}
+	}
+}
+
+func (s *IntegerGenomeSuite) TestShouldCreepByAtMostCreepSize(t *C) {
+	rng := rand.New(goga.NewSource(3))
+	domains := []goga.Domain{goga.Range(0, 100), goga.OneOf(10, 20, 30, 40, 50, 60, 70)}
+	integerMater := goga.IntegerMater{Domains: domains, CreepSize: 2}
+	categories := map[int]int{10: 0, 20: 1, 30: 2, 40: 3, 50: 4, 60: 5, 70: 6}
+	moved := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		child, _ := integerMater.CreepMutateRand(rng, goga.NewIntegerGenome([]int{50, 40}), goga.NewIntegerGenome([]int{0, 10}))
+		c := child.(goga.IntegerGenome).GetValues()
+		step := abs(c[0]-50) + abs(categories[c[1]]-3)
+		t.Assert(step >= 1 && step <= 2, IsTrue, Commentf("%v", c))
+		moved[c[0]-50+10*(categories[c[1]]-3)] = true
+	}
+	t.Assert(moved, HasLen, 8)
+
+	// The default creep size steps by one and stays within the range
+	integerMater = goga.IntegerMater{Domains: []goga.Domain{goga.Range(0, 1)}}
+	for i := 0; i < 20; i++ {
+		child, _ := integerMater.CreepMutateRand(rng, goga.NewIntegerGenome([]int{1}), goga.NewIntegerGenome([]int{1}))
+		t.Assert(child.(goga.IntegerGenome).GetValues()[0] >= 0, IsTrue)
+	}
+}
+
+func (s *IntegerGenomeSuite) TestShouldPanicWithMismatchedGenomes(t *C) {
+	b := goga.Bitset{}
+	b.Create(3)
+	t.Assert(func() {
+		goga.IntegerUniformCrossover(goga.NewGenome(b), goga.NewIntegerGenome([]int{0, 1, 2}))
+	}, Panics, "genome is not an integer genome")
+	t.Assert(func() {
+		goga.IntegerOnePointCrossover(goga.NewIntegerGenome([]int{0, 1}), goga.NewIntegerGenome([]int{0, 1, 2}))
+	}, Panics, "integer genomes are of different lengths")
+
+	integerMater := goga.IntegerMater{Domains: integerDomains}
+	t.Assert(func() {
+		integerMater.CreepMutate(goga.NewIntegerGenome([]int{0}), goga.NewIntegerGenome([]int{0}))
+	}, Panics, "genes and domains are of different lengths")
+}
+
+func (s *IntegerGenomeSuite) TestShouldKeepIntegerGenomesThroughMater(t *C) {
+	for _, m := range []goga.Mater{
+		&goga.NullMater{},
+		goga.NewMater([]goga.MaterFunctionProbability{{P: 1, F: goga.IntegerOnePointCrossover}}),
+	} {
+		child1, child2 := m.Go(goga.NewIntegerGenome([]int{1, 0, 3}), goga.NewIntegerGenome([]int{64, 1, 17}))
+		helperAssertIntegersInDomains(t, child1)
+		helperAssertIntegersInDomains(t, child2)
+	}
+}
+
+func (s *IntegerGenomeSuite) TestShouldEvolveIntegerGenomes(t *C) {
+	genAlgo := helperGenerateIntegerGeneticAlgorithm(goga.MaxGenerations(40))
+	result := genAlgo.Simulate()
+	for _, g := range genAlgo.GetPopulation() {
+		helperAssertIntegersInDomains(t, g)
+	}
+	// The fittest possible genome scores 0, {50, 1, 13}
+	t.Assert(result.Elite.GetFitness() >= -2, IsTrue, Commentf("%v", result.Elite.GetFitness()))
+}
+
+func (s *IntegerGenomeSuite) TestShouldRestoreIntegerGenomesFromCheckpoint(t *C) {
+	genAlgo := helperGenerateIntegerGeneticAlgorithm(goga.MaxGenerations(3))
+	genAlgo.Simulate()
+
+	buffer := bytes.Buffer{}
+	t.Assert(genAlgo.WriteCheckpoint(&buffer), IsNil)
+	restored := helperGenerateIntegerGeneticAlgorithm()
+	t.Assert(restored.RestoreCheckpoint(&buffer), IsNil)
+
+	expected, obtained := genAlgo.GetPopulation(), restored.GetPopulation()
+	t.Assert(obtained, HasLen, len(expected))
+	for i := range expected {
+		t.Assert(obtained[i].(goga.IntegerGenome).GetValues(), DeepEquals, expected[i].(goga.IntegerGenome).GetValues())
+		t.Assert(obtained[i].GetFitness(), Equals, expected[i].GetFitness())
+	}
+}
+
+func (s *IntegerGenomeSuite) TestShouldMarshalIntegerGenome(t *C) {
+	data, err := goga.MarshalGenome(goga.NewIntegerGenome([]int{4, 0, 11}))
+	t.Assert(err, IsNil)
+	t.Assert(string(data), Equals, `{"fitness":0,"origin":0,"integers":[4,0,11]}`)
+
+	decoded, err := goga.UnmarshalGenome(data)
+	t.Assert(err, IsNil)
+	t.Assert(decoded.(goga.IntegerGenome).GetValues(), DeepEquals, []int{4, 0, 11})
+}
@@ -0,0 +1,339 @@
+package goga
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Topology - which islands the migrants of each island are sent to
+type Topology int
+
+const (
+	// TopologyRing - each island sends its migrants to the next island, the last to the first
+	TopologyRing Topology = iota
+	// TopologyFullyConnected - each island sends its migrants to every other island
+	TopologyFullyConnected
+	// TopologyRandom - each island sends its migrants to another island picked at random
+	TopologyRandom
+)
+
+// MigrationInterval migrates genomes between islands every 'n' generations, 0 means never
+func MigrationInterval(n int) Option {
+	return func(o *Options) {
+		o.MigrationInterval = n
+	}
+}
+
+// Migrants sets the number of the fittest genomes of each island that migrate
+func Migrants(n int) Option {
+	return func(o *Options) {
+		o.Migrants = n
+	}
+}
+
+// MigrationTopology sets which islands migrants are sent to
+func MigrationTopology(t Topology) Option {
+	return func(o *Options) {
+		o.MigrationTopology = t
+	}
+}
+
+// IslandEliteConsumer - an optional extension of the EliteConsumer interface
+// When the EliteConsumer of Islands implements it, OnIslandElite is called in place of
+// OnElite and is passed the index of the island the elite belongs to
+type IslandEliteConsumer interface {
+	OnIslandElite(island int, elite Genome)
+}
+
+// Islands - the island model, evolves several genetic algorithms side by side and every
+// so often migrates copies of the fittest genomes of each island to other islands
+// * Islands - the genetic algorithms, each with its own population, Selector, Mater,
+// Simulator and BitsetCreate, which should have been initialised with Init
+// * EliteConsumer - an optional class that accepts the elite of each island every generation
+// The genomes of every island are simulated on one pool of ParallelSimulations workers and
+// every island should have the same ObjectiveDirection
+type Islands struct {
+	Islands       []*GeneticAlgorithm
+	EliteConsumer EliteConsumer
+
+	parallelSimulations int
+	maxGenerations      int
+	stagnationLimit     int
+	maxEvaluations      int
+	maxDuration         time.Duration
+	migrationInterval   int
+	migrants            int
+	topology            Topology
+	rand                *rand.Rand
+	pool                *simulationPool
+	exitFunc            func(Genome) bool
+	result              *Result
+}
+
+// NewIslands returns a new Islands structure that evolves 'islands', with a null
+// implementation of EliteConsumer
+func NewIslands(islands ...*GeneticAlgorithm) Islands {
+	return Islands{
+		Islands:       islands,
+		EliteConsumer: &NullEliteConsumer{},
+	}
+}
+
+// Init sets up the number of parallel simulations shared by the islands, when and how genomes
+// migrate and when a run stops
+// The ParallelSimulations, MaxGenerations, StagnationLimit, MaxEvaluations, MaxDuration, RandSource,
+// Seed, MigrationInterval, Migrants and MigrationTopology options are used, the evaluations of every
+// island count towards MaxEvaluations, RandSource and Seed only drive the random topology and each
+// island has its own random number generator
+func (is *Islands) Init(opt ...Option) {
+	opts := Options{
+		ParallelSimulations: 1,
+		MigrationInterval:   10,
+		Migrants:            1,
+	}
+	for _, o := range opt {
+		o(&opts)
+	}
+	is.parallelSimulations = opts.ParallelSimulations
+	is.maxGenerations = opts.MaxGenerations
+	is.stagnationLimit = opts.StagnationLimit
+	is.maxEvaluations = opts.MaxEvaluations
+	is.maxDuration = opts.MaxDuration
+	is.migrationInterval = opts.MigrationInterval
+	is.migrants = opts.Migrants
+	is.topology = opts.MigrationTopology
+	is.rand = globalRand
+	if opts.RandSource != nil {
+		is.rand = rand.New(opts.RandSource)
+	}
+	is.pool = &simulationPool{}
+}
+
+// SimulateUntil simulates the islands until 'exitFunc' returns true
+// The 'exitFunc' is passed the fittest of the island elites each generation
+func (is *Islands) SimulateUntil(exitFunc func(Genome) bool) *Result {
+	is.exitFunc = exitFunc
+	return is.Simulate()
+}
+
+// Simulate runs the islands until the exit function of an island's Simulator returns true
+// for its elite or one of the MaxGenerations or StagnationLimit options stops it
+// The result holds the fittest of the island elites and counts the generations and
+// evaluations of every island
+// It returns nil if there are no islands to simulate
+func (is *Islands) Simulate() *Result {
+	if len(is.Islands) == 0 {
+		return nil
+	}
+	result, _ := is.simulate(context.Background())
+	return result
+}
+
+// SimulateContext runs the islands like Simulate but also stops when 'ctx' is done,
+// see GeneticAlgorithm.SimulateContext
+func (is *Islands) SimulateContext(ctx context.Context) (*Result, error) {
+	if len(is.Islands) == 0 {
+		return nil, nil
+	}
+	return is.simulate(ctx)
+}
+
+func (is *Islands) simulate(ctx context.Context) (*Result, error) {
+	is.result = &Result{}
+	for _, island := range is.Islands {
+		island.reset()
+	}
+	is.pool.evaluations = 0
+	is.pool.maxEvaluations = is.maxEvaluations
+	startTime := time.Now()
+	budgetCtx, cancel := withTimeBudget(ctx, is.maxDuration, 0)
+	reason, err := is.generations(budgetCtx)
+	cancel()
+	reason, err = budgetReason(ctx, reason, err)
+	is.result.Duration = time.Since(startTime)
+	is.result.Reason = reason
+	is.result.Err = err
+	return is.result, err
+}
+
+// generations simulates a generation of every island at a time, asking each island for one
+// genome in turn so that the islands share the simulation workers evenly
+func (is *Islands) generations(ctx context.Context) (TerminationReason, error) {
+	for {
+		var storeErr error
+		simulating := make([]bool, len(is.Islands))
+		for i := range simulating {
+			simulating[i] = true
+		}
+		for rounds := true; rounds; {
+			// todo: make configurable
+			is.pool.begin(ctx, is.parallelSimulations)
+			simulated := make([][]Genome, len(is.Islands))
+			for asking := true; asking && ctx.Err() == nil && !is.pool.exhausted(); {
+				asking = false
+				for i, island := range is.Islands {
+					if !simulating[i] || is.pool.exhausted() {
+						continue
+					}
+					genomes := island.Ask(1)
+					if len(genomes) == 0 {
+						continue
+					}
+					if !is.pool.submit(ctx, island.newSimulation(genomes[0])) {
+						break
+					}
+					simulated[i] = append(simulated[i], genomes[0])
+					asking = true
+				}
+			}
+			is.pool.sync()
+			is.result.Evaluations = is.pool.evaluations
+			if err := ctx.Err(); err != nil {
+				return TerminationContext, err
+			}
+
+			// Islands that replaced failed genomes simulate their replacements in another round
+			failures := is.pool.failed()
+			failed, timedOut := countFailures(failures)
+			is.result.Failures += failed
+			is.result.Timeouts += timedOut
+			hits, putErr := is.pool.storeHits()
+			if putErr != nil && storeErr == nil {
+				storeErr = putErr
+			}
+			rounds = false
+			for i, island := range is.Islands {
+				if !simulating[i] {
+					continue
+				}
+				island.countStoreHits(simulated[i], hits)
+				err := island.settle(simulated[i], failures)
+				if _, aborted := err.(*SimulationError); aborted || (err != nil && island.generationStarted) {
+					return TerminationError, err
+				}
+				if err != nil && storeErr == nil {
+					storeErr = err
+				}
+				simulating[i] = island.generationStarted
+				rounds = rounds || simulating[i]
+			}
+			if rounds && is.pool.exhausted() {
+				return TerminationEvaluationBudget, nil
+			}
+		}
+
+		var best Genome
+		exit := false
+		for i, island := range is.Islands {
+			elite := island.Elite()
+			is.onIslandElite(i, elite)
+			if best == nil || island.fitter(elite, best) {
+				best = elite
+			}
+			exit = exit || (is.exitFunc == nil && island.Simulator.ExitFunc(elite))
+		}
+		is.result.record(best, is.Islands[0].direction)
+		if storeErr != nil {
+			return TerminationError, storeErr
+		}
+
+		if exit || (is.exitFunc != nil && is.exitFunc(best)) {
+			return TerminationExitFunc, nil
+		}
+		if reason, ok := is.result.limitReached(is.maxGenerations, is.stagnationLimit, is.maxEvaluations); ok {
+			return reason, nil
+		}
+		if is.migrationInterval > 0 && is.result.Generations%is.migrationInterval == 0 {
+			is.migrate()
+		}
+		if err := ctx.Err(); err != nil {
+			return TerminationContext, err
+		}
+	}
+}
+
+func (is *Islands) onIslandElite(island int, elite Genome) {
+	if consumer, ok := is.EliteConsumer.(IslandEliteConsumer); ok {
+		consumer.OnIslandElite(island, elite)
+		return
+	}
+	is.EliteConsumer.OnElite(elite)
+}
+
+// destinations returns the islands that the migrants of island 'i' are sent to
+func (is *Islands) destinations(i int) []int {
+	n := len(is.Islands)
+	if n < 2 {
+		return nil
+	}
+	switch is.topology {
+	case TopologyFullyConnected:
+		ret := make([]int, 0, n-1)
+		for j := 0; j < n; j++ {
+			if j != i {
+				ret = append(ret, j)
+			}
+		}
+		return ret
+	case TopologyRandom:
+		return []int{(i + 1 + is.rand.Intn(n-1)) % n}
+	}
+	return []int{(i + 1) % n}
+}
+
+// migrate sends copies of the fittest genomes of every island to its destinations, the
+// migrants are all chosen before any island takes in immigrants
+func (is *Islands) migrate() {
+	immigrants := make([][]Genome, len(is.Islands))
+	for i, island := range is.Islands {
+		emigrants := island.fittest(is.migrants)
+		for _, j := range is.destinations(i) {
+			for _, g := range emigrants {
+				immigrants[j] = append(immigrants[j], copyGenome(g))
+			}
+		}
+	}
+	for i, island := range is.Islands {
+		island.Immigrate(immigrants[i])
+	}
+}
+
+// fittest returns up to 'n' of the fittest genomes of the population
+func (ga *GeneticAlgorithm) fittest(n int) []Genome {
+	sorted := append([]Genome{}, ga.population...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return ga.fitter(sorted[i], sorted[j])
+	})
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	return sorted[:n]
+}
+
+// Immigrate replaces the least fit genomes of the population with 'genomes', which should
+// already have had their fitness set, and passes on the elite if it changes
+// The population is left sorted by fitness, fittest first, followed by the immigrants
+// ErrGenerationInProgress is returned if genomes of a generation are still being asked
+// for or told
+func (ga *GeneticAlgorithm) Immigrate(genomes []Genome) error {
+	if ga.generationStarted {
+		return ErrGenerationInProgress
+	}
+	if len(genomes) == 0 {
+		return nil
+	}
+
+	ga.population = ga.fittest(len(ga.population))
+	for i := 0; i < len(genomes) && i < len(ga.population); i++ {
+		ga.population[len(ga.population)-1-i] = genomes[i]
+		ga.remember(genomes[i].Key(), genomes[i])
+	}
+	ga.rescore()
+	if elite := ga.getElite(); elite != ga.elite {
+		ga.elite = elite
+		ga.Mater.OnElite(ga.elite)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package goga_test
+
+import (
+	"context"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type IslandsSuite struct {
+}
+
+var _ = Suite(&IslandsSuite{})
+
+func helperGenerateIsland(simulator goga.Simulator) *goga.GeneticAlgorithm {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	genAlgo.Simulator = simulator
+	genAlgo.Init(goga.PopulationSize(10))
+	return &genAlgo
+}
+
+type MyIslandEliteConsumer struct {
+	elites map[int]int
+}
+
+func (ec *MyIslandEliteConsumer) OnElite(goga.Genome) {
+	panic("OnElite should not be called on an IslandEliteConsumer")
+}
+func (ec *MyIslandEliteConsumer) OnIslandElite(island int, elite goga.Genome) {
+	ec.elites[island]++
+}
+
+func (s *IslandsSuite) TestShouldSimulateEveryIsland(t *C) {
+	islands := goga.NewIslands(
+		helperGenerateIsland(&MySimulatorBitCount{}),
+		helperGenerateIsland(&MySimulatorBitCount{}),
+		helperGenerateIsland(&MySimulatorBitCount{}),
+	)
+	consumer := &MyIslandEliteConsumer{elites: map[int]int{}}
+	islands.EliteConsumer = consumer
+	islands.Init(goga.ParallelSimulations(kNumThreads), goga.MaxGenerations(5), goga.MigrationInterval(2))
+
+	result := islands.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationGenerationBudget)
+	t.Assert(result.Generations, Equals, 5)
+	t.Assert(result.Evaluations > 3*10, IsTrue)
+	t.Assert(consumer.elites, DeepEquals, map[int]int{0: 5, 1: 5, 2: 5})
+
+	for _, island := range islands.Islands {
+		t.Assert(island.Generation(), Equals, 5)
+		t.Assert(result.Elite.GetFitness() >= island.Elite().GetFitness(), IsTrue)
+	}
+}
+
+func (s *IslandsSuite) TestShouldMigrateFittestGenomes(t *C) {
+	topologies := []goga.Topology{goga.TopologyRing, goga.TopologyFullyConnected, goga.TopologyRandom}
+	for _, topology := range topologies {
+		source := helperGenerateIsland(&MySimulatorBitCount{})
+		destination := helperGenerateIsland(&MySimulatorConstantFitness{})
+		islands := goga.NewIslands(source, destination)
+		islands.Init(goga.MaxGenerations(2), goga.MigrationInterval(1), goga.Migrants(2), goga.MigrationTopology(topology))
+		islands.Simulate()
+
+		// The simulator of the destination never scores above 1 so its elite can only have migrated
+		t.Assert(destination.Elite().GetFitness() > 1, IsTrue)
+	}
+}
+
+func (s *IslandsSuite) TestShouldNotMigrateWithoutInterval(t *C) {
+	destination := helperGenerateIsland(&MySimulatorConstantFitness{})
+	islands := goga.NewIslands(helperGenerateIsland(&MySimulatorBitCount{}), destination)
+	islands.Init(goga.MaxGenerations(3), goga.MigrationInterval(0))
+	islands.Simulate()
+	t.Assert(destination.Elite().GetFitness(), Equals, float64(1))
+}
+
+func (s *IslandsSuite) TestShouldStopIslandsWhenContextIsDone(t *C) {
+	islands := goga.NewIslands(helperGenerateIsland(&MySimulatorBitCount{}))
+	islands.Init()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := islands.SimulateContext(ctx)
+	t.Assert(err, Equals, context.Canceled)
+	t.Assert(result.Reason, Equals, goga.TerminationContext)
+	t.Assert(result.Generations, Equals, 0)
+}
+
+func (s *IslandsSuite) TestShouldReturnNilWithoutIslands(t *C) {
+	islands := goga.NewIslands()
+	islands.Init()
+	t.Assert(islands.Simulate(), IsNil)
+}
+
+func (s *IslandsSuite) TestShouldImmigrateOverLeastFit(t *C) {
+	genAlgo := helperGenerateIsland(&MySimulatorBitCount{})
+	genAlgo.Ask(1)
+	t.Assert(genAlgo.Immigrate(nil), Equals, goga.ErrGenerationInProgress)
+
+	genAlgo = helperGenerateIsland(&MySimulatorBitCount{})
+	genAlgo.SimulateUntil(func(goga.Genome) bool { return true })
+	worst := genAlgo.GetPopulation()[0].GetFitness()
+	for _, g := range genAlgo.GetPopulation() {
+		if g.GetFitness() < worst {
+			worst = g.GetFitness()
+		}
+	}
+
+	immigrant := goga.NewGenome(goga.Bitset{})
+	immigrant.SetFitness(1000)
+	t.Assert(genAlgo.Immigrate([]goga.Genome{immigrant}), IsNil)
+	t.Assert(genAlgo.Elite(), Equals, immigrant)
+
+	population := genAlgo.GetPopulation()
+	t.Assert(population, HasLen, 10)
+	t.Assert(population[len(population)-1], Equals, immigrant)
+	for _, g := range population[:len(population)-1] {
+		t.Assert(g.GetFitness() >= worst, IsTrue)
+	}
+}
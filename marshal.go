@@ -0,0 +1,264 @@
+package goga
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+)
+
+// ErrMalformedData is returned when unmarshalling a bitset or genome from data
+// that was not produced by the matching marshal function
+var ErrMalformedData = errors.New("malformed bitset or genome data")
+
+// String returns the bits of the bitset as a string of 0s and 1s, bit 0 first
+func (b Bitset) String() string {
+	var sb strings.Builder
+	sb.Grow(b.size)
+	for i := 0; i < b.size; i++ {
+		sb.WriteByte(byte('0' + b.Get(i)))
+	}
+	return sb.String()
+}
+
+// MarshalText implements encoding.TextMarshaler, the text is the same as String's
+// As encoding/json uses it, a bitset is marshalled to JSON as a string of 0s and 1s
+func (b Bitset) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, it re-creates the bitset from a string
+// of 0s and 1s as returned by MarshalText
+func (b *Bitset) UnmarshalText(text []byte) error {
+	ret := Bitset{}
+	ret.Create(len(text))
+	for i, c := range text {
+		switch c {
+		case '0':
+		case '1':
+			ret.setImpl(i, 1)
+		default:
+			return ErrMalformedData
+		}
+	}
+	*b = ret
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, the bits are packed 8 to a byte,
+// bit 'i' being bit 'i % 8' of byte 'i / 8', after the size of the bitset as a uvarint
+func (b Bitset) MarshalBinary() ([]byte, error) {
+	return b.appendBinary(nil), nil
+}
+
+func (b *Bitset) appendBinary(buf []byte) []byte {
+	buf = appendUvarint(buf, uint64(b.size))
+	for i := 0; i < (b.size+7)/8; i++ {
+		buf = append(buf, byte(b.words[i/8]>>uint(8*(i%8))))
+	}
+	return buf
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, it re-creates the bitset from
+// data returned by MarshalBinary
+func (b *Bitset) UnmarshalBinary(data []byte) error {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > math.MaxInt32 {
+		return ErrMalformedData
+	}
+	data = data[n:]
+	numBytes := (int(size) + 7) / 8
+	if len(data) != numBytes {
+		return ErrMalformedData
+	}
+	if tail := uint(size % 8); tail != 0 && data[numBytes-1]>>tail != 0 {
+		return ErrMalformedData
+	}
+
+	ret := Bitset{}
+	ret.Create(int(size))
+	for i, v := range data {
+		ret.words[i/8] |= uint64(v) << uint(8*(i%8))
+	}
+	*b = ret
+	return nil
+}
+
+// genomeJSON - a genome as marshalled to JSON
+type genomeJSON struct {
+	Fitness     float64   `json:"fitness"`
+	Origin      float64   `json:"origin"`
+	Objectives  []float64 `json:"objectives,omitempty"`
+	Bits        *Bitset   `json:"bits,omitempty"`
+	Values      []float64 `json:"values,omitempty"`
+	Permutation []int     `json:"permutation,omitempty"`
+	Integers    []int     `json:"integers,omitempty"`
+}
+
+// MarshalGenome marshals the fitness, origin, objectives and bits of 'g' to JSON, or its
+// values or permutation in place of its bits when it is a RealGenome, a PermutationGenome
+// or an IntegerGenome
+// It works for any Genome, genomes created by NewGenome, NewRealGenome, NewPermutationGenome
+// and NewIntegerGenome also implement json.Marshaler
+func MarshalGenome(g Genome) ([]byte, error) {
+	j := genomeJSON{
+		Fitness: g.GetFitness(),
+		Origin:  g.GetOrigin(),
+	}
+	if rg, ok := g.(RealGenome); ok {
+		j.Values = rg.GetValues()
+		if j.Values == nil {
+			j.Values = []float64{}
+		}
+	} else if pg, ok := g.(PermutationGenome); ok {
+		j.Permutation = pg.GetPermutation()
+		if j.Permutation == nil {
+			j.Permutation = []int{}
+		}
+	} else if ig, ok := g.(IntegerGenome); ok {
+		j.Integers = ig.GetValues()
+		if j.Integers == nil {
+			j.Integers = []int{}
+		}
+	} else {
+		j.Bits = g.GetBits()
+	}
+	if mog, ok := g.(MultiObjectiveGenome); ok {
+		j.Objectives = mog.GetObjectives()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalGenome creates a genome from JSON returned by MarshalGenome, as NewRealGenome,
+// NewPermutationGenome or NewIntegerGenome do when it holds values, a permutation or
+// integers and as NewGenome does otherwise
+func UnmarshalGenome(data []byte) (Genome, error) {
+	j := genomeJSON{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	if j.Values != nil {
+		return &realGenome{fitness: j.Fitness, origin: j.Origin, objectives: j.Objectives, values: j.Values}, nil
+	}
+	if j.Permutation != nil {
+		return &permutationGenome{fitness: j.Fitness, origin: j.Origin, objectives: j.Objectives, permutation: j.Permutation}, nil
+	}
+	if j.Integers != nil {
+		return &integerGenome{fitness: j.Fitness, origin: j.Origin, objectives: j.Objectives, values: j.Integers}, nil
+	}
+	return j.genome(), nil
+}
+
+// genome returns the genome 'j' holds, as NewGenome does
+func (j *genomeJSON) genome() *genome {
+	g := &genome{fitness: j.Fitness, origin: j.Origin, objectives: j.Objectives}
+	if j.Bits != nil {
+		g.bitset = *j.Bits
+	}
+	return g
+}
+
+func (g *genome) MarshalJSON() ([]byte, error) {
+	return MarshalGenome(g)
+}
+
+func (g *genome) UnmarshalJSON(data []byte) error {
+	j := genomeJSON{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*g = *j.genome()
+	return nil
+}
+
+func (g *realGenome) MarshalJSON() ([]byte, error) {
+	return MarshalGenome(g)
+}
+
+func (g *realGenome) UnmarshalJSON(data []byte) error {
+	j := genomeJSON{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*g = realGenome{fitness: j.Fitness, origin: j.Origin, objectives: j.Objectives, values: j.Values}
+	return nil
+}
+
+func (g *permutationGenome) MarshalJSON() ([]byte, error) {
+	return MarshalGenome(g)
+}
+
+func (g *permutationGenome) UnmarshalJSON(data []byte) error {
+	j := genomeJSON{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*g = permutationGenome{fitness: j.Fitness, origin: j.Origin, objectives: j.Objectives, permutation: j.Permutation}
+	return nil
+}
+
+func (g *integerGenome) MarshalJSON() ([]byte, error) {
+	return MarshalGenome(g)
+}
+
+func (g *integerGenome) UnmarshalJSON(data []byte) error {
+	j := genomeJSON{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*g = integerGenome{fitness: j.Fitness, origin: j.Origin, objectives: j.Objectives, values: j.Integers}
+	return nil
+}
+
+// MarshalBinary writes the fitness, origin and objectives of the genome as little endian
+// float64s, the objectives after their count as a uvarint, followed by its bits as
+// written by Bitset.MarshalBinary
+func (g *genome) MarshalBinary() ([]byte, error) {
+	buf := appendFloat64(nil, g.fitness)
+	buf = appendFloat64(buf, g.origin)
+	buf = appendUvarint(buf, uint64(len(g.objectives)))
+	for _, o := range g.objectives {
+		buf = appendFloat64(buf, o)
+	}
+	return g.bitset.appendBinary(buf), nil
+}
+
+func (g *genome) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return ErrMalformedData
+	}
+	ret := genome{
+		fitness: math.Float64frombits(binary.LittleEndian.Uint64(data)),
+		origin:  math.Float64frombits(binary.LittleEndian.Uint64(data[8:])),
+	}
+	data = data[16:]
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > uint64(len(data)-n)/8 {
+		return ErrMalformedData
+	}
+	data = data[n:]
+	if count > 0 {
+		ret.objectives = make([]float64, count)
+		for i := range ret.objectives {
+			ret.objectives[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+		}
+		data = data[8*count:]
+	}
+	if err := ret.bitset.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	*g = ret
+	return nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutUvarint(tmp[:], v)]...)
+}
+
+func appendFloat64(buf []byte, f float64) []byte {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(f))
+	return append(buf, tmp[:]...)
+}
@@ -0,0 +1,146 @@
+package goga_test
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type MarshalSuite struct {
+}
+
+var _ = Suite(&MarshalSuite{})
+
+func helperGenomesEqual(t *C, g1, g2 goga.Genome) {
+	t.Assert(g1.GetFitness(), Equals, g2.GetFitness())
+	t.Assert(g1.GetOrigin(), Equals, g2.GetOrigin())
+	t.Assert(goga.Objectives(g1), DeepEquals, goga.Objectives(g2))
+	t.Assert(g1.GetBits().Equal(g2.GetBits()), IsTrue)
+}
+
+func (s *MarshalSuite) TestShouldPrintBitset(t *C) {
+	b := helperBitsetFromString("0110")
+	t.Assert(b.String(), Equals, "0110")
+	t.Assert(fmt.Sprint(b), Equals, "0110")
+	t.Assert(fmt.Sprint(&b), Equals, "0110")
+	t.Assert(goga.Bitset{}.String(), Equals, "")
+}
+
+func (s *MarshalSuite) TestShouldMarshalBitsetToText(t *C) {
+	b := goga.Bitset{}
+	b.Create(130)
+	b.Set(0, 1)
+	b.Set(64, 1)
+	b.Set(129, 1)
+
+	text, err := b.MarshalText()
+	t.Assert(err, IsNil)
+	t.Assert(len(text), Equals, 130)
+
+	b2 := goga.Bitset{}
+	t.Assert(b2.UnmarshalText(text), IsNil)
+	t.Assert(b2.Equal(&b), IsTrue)
+
+	t.Assert(b2.UnmarshalText([]byte("0120")), Equals, goga.ErrMalformedData)
+	t.Assert(b2.Equal(&b), IsTrue)
+}
+
+func (s *MarshalSuite) TestShouldMarshalBitsetToJSON(t *C) {
+	b := helperBitsetFromString("1011")
+	data, err := json.Marshal(struct{ Bits goga.Bitset }{b})
+	t.Assert(err, IsNil)
+	t.Assert(string(data), Equals, `{"Bits":"1011"}`)
+
+	decoded := struct{ Bits goga.Bitset }{}
+	t.Assert(json.Unmarshal(data, &decoded), IsNil)
+	t.Assert(decoded.Bits.Equal(&b), IsTrue)
+}
+
+func (s *MarshalSuite) TestShouldMarshalBitsetToPackedBinary(t *C) {
+	for _, size := range []int{0, 1, 8, 9, 64, 100, 1000} {
+		b := goga.Bitset{}
+		b.Create(size)
+		for i := 0; i < size; i += 3 {
+			b.Set(i, 1)
+		}
+
+		var marshaler encoding.BinaryMarshaler = b
+		data, err := marshaler.MarshalBinary()
+		t.Assert(err, IsNil)
+		t.Assert(len(data) <= (size+7)/8+2, IsTrue)
+
+		b2 := goga.Bitset{}
+		t.Assert(b2.UnmarshalBinary(data), IsNil)
+		t.Assert(b2.Equal(&b), IsTrue)
+	}
+}
+
+func (s *MarshalSuite) TestShouldRejectMalformedBinaryBitset(t *C) {
+	b := goga.Bitset{}
+	t.Assert(b.UnmarshalBinary(nil), Equals, goga.ErrMalformedData)
+	// Too few bytes
+	t.Assert(b.UnmarshalBinary([]byte{9, 0xff}), Equals, goga.ErrMalformedData)
+	// Too many bytes
+	t.Assert(b.UnmarshalBinary([]byte{1, 0x1, 0x0}), Equals, goga.ErrMalformedData)
+	// Bits set beyond the size
+	t.Assert(b.UnmarshalBinary([]byte{2, 0x7}), Equals, goga.ErrMalformedData)
+	t.Assert(b.UnmarshalBinary([]byte{2, 0x3}), IsNil)
+	t.Assert(b.String(), Equals, "11")
+}
+
+func (s *MarshalSuite) TestShouldMarshalGenomeToJSON(t *C) {
+	g := goga.NewGenome(helperBitsetFromString("0101"))
+	g.SetFitness(1.5)
+	g.SetOrigin(-2)
+
+	data, err := json.Marshal(g)
+	t.Assert(err, IsNil)
+	t.Assert(string(data), Equals, `{"fitness":1.5,"origin":-2,"bits":"0101"}`)
+
+	decoded, err := goga.UnmarshalGenome(data)
+	t.Assert(err, IsNil)
+	helperGenomesEqual(t, decoded, g)
+
+	into := goga.NewGenome(goga.Bitset{})
+	t.Assert(json.Unmarshal(data, into), IsNil)
+	helperGenomesEqual(t, into, g)
+
+	_, err = goga.UnmarshalGenome([]byte(`{"bits":"01x"}`))
+	t.Assert(err, NotNil)
+}
+
+func (s *MarshalSuite) TestShouldMarshalGenomesWithObjectives(t *C) {
+	g := helperGenomeWithObjectives(1, 2, 3)
+	g.GetBits().Create(70)
+	g.GetBits().Set(69, 1)
+
+	data, err := goga.MarshalGenome(g)
+	t.Assert(err, IsNil)
+	decoded, err := goga.UnmarshalGenome(data)
+	t.Assert(err, IsNil)
+	helperGenomesEqual(t, decoded, g)
+
+	data, err = g.(encoding.BinaryMarshaler).MarshalBinary()
+	t.Assert(err, IsNil)
+	decoded = goga.NewGenome(goga.Bitset{})
+	t.Assert(decoded.(encoding.BinaryUnmarshaler).UnmarshalBinary(data), IsNil)
+	helperGenomesEqual(t, decoded, g)
+}
+
+func (s *MarshalSuite) TestShouldMarshalGenomeToBinary(t *C) {
+	g := goga.NewGenome(helperBitsetFromString("110"))
+	g.SetFitness(3)
+	g.SetOrigin(4)
+
+	data, err := g.(encoding.BinaryMarshaler).MarshalBinary()
+	t.Assert(err, IsNil)
+	decoded := goga.NewGenome(goga.Bitset{})
+	t.Assert(decoded.(encoding.BinaryUnmarshaler).UnmarshalBinary(data), IsNil)
+	helperGenomesEqual(t, decoded, g)
+
+	t.Assert(decoded.(encoding.BinaryUnmarshaler).UnmarshalBinary(data[:10]), Equals, goga.ErrMalformedData)
+	t.Assert(decoded.(encoding.BinaryUnmarshaler).UnmarshalBinary(data[:len(data)-1]), Equals, goga.ErrMalformedData)
+}
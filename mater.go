@@ -16,7 +16,7 @@ type NullMater struct {
 
 // Go - null implementation of the IMater go func
 func (nm *NullMater) Go(a, b Genome) (Genome, Genome) {
-	return NewGenome(*a.GetBits()), NewGenome(*b.GetBits())
+	return childGenome(a), childGenome(b)
 }
 
 // OnElite - null implementation of the IMater OnElite func
@@ -28,15 +28,19 @@ func (nm *NullMater) OnElite(a Genome) {
 // where mater function 'F' is called with a probability of 'P'
 // where 'P' is a value between 0 and 1
 // 0 = never called, 1 = called for every genome
+// 'R' can be given in place of 'F', it is also passed the mater's random number generator
+// so that mating can be reproduced, see OnePointCrossoverRand
 type MaterFunctionProbability struct {
 	P        float32
 	F        func(Genome, Genome) (Genome, Genome)
+	R        func(*rand.Rand, Genome, Genome) (Genome, Genome)
 	UseElite bool
 }
 
 type mater struct {
 	materConfig []MaterFunctionProbability
 	elite       Genome
+	rand        *rand.Rand
 }
 
 // NewMater returns an instance of an IMater with several MaterFuncProbabilities
@@ -50,14 +54,19 @@ func NewMater(materConfig []MaterFunctionProbability) Mater {
 // MaterFunctionProbability array
 func (m *mater) Go(g1, g2 Genome) (Genome, Genome) {
 
-	newG1 := NewGenome(*g1.GetBits())
-	newG2 := NewGenome(*g2.GetBits())
+	newG1 := childGenome(g1)
+	newG2 := childGenome(g2)
+	rng := RandOrGlobal(m.rand)
 	for _, config := range m.materConfig {
-		if rand.Float32() < config.P {
+		if rng.Float32() < config.P {
+			other := newG2
 			if config.UseElite {
-				newG1, newG2 = config.F(newG1, m.elite)
+				other = m.elite
+			}
+			if config.R != nil {
+				newG1, newG2 = config.R(rng, newG1, other)
 			} else {
-				newG1, newG2 = config.F(newG1, newG2)
+				newG1, newG2 = config.F(newG1, other)
 			}
 		}
 	}
@@ -65,18 +74,16 @@ func (m *mater) Go(g1, g2 Genome) (Genome, Genome) {
 	return newG1, newG2
 }
 
+// SetRand - sets the random number generator used by the mater and passed to each 'R'
+func (m *mater) SetRand(rng *rand.Rand) {
+	m.rand = rng
+}
+
 // OnElite -
 func (m *mater) OnElite(elite Genome) {
 	m.elite = elite
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -92,42 +99,33 @@ func min(a, b int) int {
 // could produce output genomes of:
 // 000111 and 111000
 func OnePointCrossover(g1, g2 Genome) (Genome, Genome) {
+	return OnePointCrossoverRand(globalRand, g1, g2)
+}
 
-	g1Bits, g2Bits := g1.GetBits(), g2.GetBits()
-
-	b1, b2 := Bitset{}, Bitset{}
-	g1Size := g1Bits.GetSize()
-	g2Size := g2Bits.GetSize()
-	b1.Create(g1Size)
-	b2.Create(g2Size)
-
-	maxSize := max(g1Size, g2Size)
-	minSize := min(g1Size, g2Size)
-	randIndex := rand.Intn(minSize-1) + 1
-
-	for i := 0; i < randIndex; i++ {
-		b1.Set(i, g1Bits.Get(i))
-		b2.Set(i, g2Bits.Get(i))
-	}
-
-	for i := randIndex; i < minSize; i++ {
-		b2.Set(i, g1Bits.Get(i))
-		b1.Set(i, g2Bits.Get(i))
-	}
+// OnePointCrossoverRand is OnePointCrossover drawing from the random number generator 'rng'
+func OnePointCrossoverRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
 
-	if g1Size > g2Size {
-		for i := minSize; i < maxSize; i++ {
-			b2.Set(i, g1Bits.Get(i))
-		}
-	} else {
-		for i := minSize; i < maxSize; i++ {
-			b1.Set(i, g2Bits.Get(i))
-		}
-	}
+	g1Bits, g2Bits := g1.GetBits(), g2.GetBits()
+	minSize := min(g1Bits.GetSize(), g2Bits.GetSize())
+	randIndex := rng.Intn(minSize-1) + 1
 
+	b1, b2 := crossoverHeads(g1Bits, g2Bits, minSize)
+	copyRange(&b1, randIndex, g2Bits, randIndex, minSize-randIndex)
+	copyRange(&b2, randIndex, g1Bits, randIndex, minSize-randIndex)
 	return NewGenome(b1), NewGenome(b2)
 }
 
+// crossoverHeads returns a child of the size of each of 'g1Bits' and 'g2Bits' holding the first
+// 'minSize' bits of that parent, the bits of the longer child beyond 'minSize' are left zeroed
+func crossoverHeads(g1Bits, g2Bits *Bitset, minSize int) (Bitset, Bitset) {
+	b1, b2 := Bitset{}, Bitset{}
+	b1.Create(g1Bits.GetSize())
+	b2.Create(g2Bits.GetSize())
+	copyRange(&b1, 0, g1Bits, 0, minSize)
+	copyRange(&b2, 0, g2Bits, 0, minSize)
+	return b1, b2
+}
+
 // TwoPointCrossover -
 // Accepts 2 genomes and combines them to create 2 new genomes using two point crossover
 // i.e.
@@ -136,22 +134,19 @@ func OnePointCrossover(g1, g2 Genome) (Genome, Genome) {
 // could produce output genomes of:
 // 001100 and 110011
 func TwoPointCrossover(g1, g2 Genome) (Genome, Genome) {
+	return TwoPointCrossoverRand(globalRand, g1, g2)
+}
 
-	g1Bits, g2Bits := g1.GetBits(), g2.GetBits()
+// TwoPointCrossoverRand is TwoPointCrossover drawing from the random number generator 'rng'
+func TwoPointCrossoverRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
 
-	b1, b2 := Bitset{}, Bitset{}
-	g1Size := g1Bits.GetSize()
-	g2Size := g2Bits.GetSize()
-	b1.Create(g1Size)
-	b2.Create(g2Size)
-
-	maxSize := max(g1Size, g2Size)
-	minSize := min(g1Size, g2Size)
-	randIndex1 := rand.Intn(minSize-1) + 1
+	g1Bits, g2Bits := g1.GetBits(), g2.GetBits()
+	minSize := min(g1Bits.GetSize(), g2Bits.GetSize())
+	randIndex1 := rng.Intn(minSize-1) + 1
 	randIndex2 := randIndex1
 
 	for randIndex1 == randIndex2 {
-		randIndex2 = rand.Intn(minSize-1) + 1
+		randIndex2 = rng.Intn(minSize-1) + 1
 	}
 
 	// Note: cannot be same value
@@ -159,30 +154,9 @@ func TwoPointCrossover(g1, g2 Genome) (Genome, Genome) {
 		randIndex1, randIndex2 = randIndex2, randIndex1
 	}
 
-	for i := 0; i < randIndex1; i++ {
-		b1.Set(i, g1Bits.Get(i))
-		b2.Set(i, g2Bits.Get(i))
-	}
-
-	for i := randIndex1; i < randIndex2; i++ {
-		b2.Set(i, g1Bits.Get(i))
-		b1.Set(i, g2Bits.Get(i))
-	}
-
-	for i := randIndex2; i < minSize; i++ {
-		b1.Set(i, g1Bits.Get(i))
-		b2.Set(i, g2Bits.Get(i))
-	}
-
-	if g1Size > g2Size {
-		for i := minSize; i < maxSize; i++ {
-			b2.Set(i, g1Bits.Get(i))
-		}
-	} else {
-		for i := minSize; i < maxSize; i++ {
-			b1.Set(i, g2Bits.Get(i))
-		}
-	}
+	b1, b2 := crossoverHeads(g1Bits, g2Bits, minSize)
+	copyRange(&b1, randIndex1, g2Bits, randIndex1, randIndex2-randIndex1)
+	copyRange(&b2, randIndex1, g1Bits, randIndex1, randIndex2-randIndex1)
 	return NewGenome(b1), NewGenome(b2)
 }
 
@@ -194,38 +168,28 @@ func TwoPointCrossover(g1, g2 Genome) (Genome, Genome) {
 // could produce output genomes of:
 // 101010 and 010101
 func UniformCrossover(g1, g2 Genome) (Genome, Genome) {
+	return UniformCrossoverRand(globalRand, g1, g2)
+}
+
+// UniformCrossoverRand is UniformCrossover drawing from the random number generator 'rng'
+func UniformCrossoverRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
 
 	g1Bits, g2Bits := g1.GetBits(), g2.GetBits()
+	minSize := min(g1Bits.GetSize(), g2Bits.GetSize())
 
 	b1, b2 := Bitset{}, Bitset{}
-	g1Size := g1Bits.GetSize()
-	g2Size := g2Bits.GetSize()
-	b1.Create(g1Size)
-	b2.Create(g2Size)
-
-	maxSize := max(g1Size, g2Size)
-	minSize := min(g1Size, g2Size)
-
-	for i := 0; i < minSize; i++ {
-		if rand.Float32() > 0.5 {
-			b1.Set(i, g1Bits.Get(i))
-			b2.Set(i, g2Bits.Get(i))
-		} else {
-			b2.Set(i, g1Bits.Get(i))
-			b1.Set(i, g2Bits.Get(i))
-		}
+	b1.Create(g1Bits.GetSize())
+	b2.Create(g2Bits.GetSize())
+	for i := 0; i < minSize; i += wordSize {
+		width := min(wordSize, minSize-i)
+		w1, _ := g1Bits.Uint64(i, width)
+		w2, _ := g2Bits.Uint64(i, width)
+
+		// Each set bit of the mask swaps the bits of the parents
+		mask := rng.Uint64()
+		b1.SetUint64(i, width, w1&^mask|w2&mask)
+		b2.SetUint64(i, width, w2&^mask|w1&mask)
 	}
-
-	if g1Size > g2Size {
-		for i := minSize; i < maxSize; i++ {
-			b2.Set(i, g1Bits.Get(i))
-		}
-	} else {
-		for i := minSize; i < maxSize; i++ {
-			b1.Set(i, g2Bits.Get(i))
-		}
-	}
-
 	return NewGenome(b1), NewGenome(b2)
 }
 
@@ -238,70 +202,97 @@ func UniformCrossover(g1, g2 Genome) (Genome, Genome) {
 // could produce output genomes of:
 // 001000 and 111111
 func Mutate(g1, g2 Genome) (Genome, Genome) {
+	return MutateRand(globalRand, g1, g2)
+}
+
+// MutateRand is Mutate drawing from the random number generator 'rng'
+func MutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
 
 	g1BitsOrig := g1.GetBits()
 	g1Bits := g1BitsOrig.CreateCopy()
-	randomBit := rand.Intn(g1Bits.GetSize())
+	randomBit := rng.Intn(g1Bits.GetSize())
 	g1Bits.Set(randomBit, 1-g1Bits.Get(randomBit))
 
 	return NewGenome(g1Bits), NewGenome(*g2.GetBits())
 }
 
+// FloatMater - mates genomes of float64s, either real-valued genomes, see NewRealGenome, or
+// genomes whose bits were encoded by ParseFloat64ArrToBits, keeping their values within the
+// Float64Requirement
+// Real-valued genomes are mated on their values and their offspring are real-valued genomes
 type FloatMater struct {
 	Float64Requirement
 }
 
+// floatValues returns a copy of the values of 'g', decoded from its bits unless it is a RealGenome
+func floatValues(g Genome) []float64 {
+	if rg, ok := g.(RealGenome); ok {
+		return append([]float64{}, rg.GetValues()...)
+	}
+	return ParseBitsToFloat64Arr(g.GetBits())
+}
+
+// floatGenome returns a genome holding 'values' of the same kind as 'like'
+func floatGenome(like Genome, values []float64) Genome {
+	if _, ok := like.(RealGenome); ok {
+		return NewRealGenome(values)
+	}
+	return NewGenome(*ParseFloat64ArrToBits(values))
+}
+
 // ArithmeticCrossover -
 // Accepts 2 genomes and parse float function
 func (f *FloatMater) ArithmeticExchange(g1, g2 Genome) (Genome, Genome) {
-	floatArr1 := ParseBitsToFloat64Arr(g1.GetBits())
-	floatArr2 := ParseBitsToFloat64Arr(g2.GetBits())
-	newArr1 := floatArr1[:]
-	newArr2 := floatArr2[:]
+	return f.ArithmeticExchangeRand(globalRand, g1, g2)
+}
 
-	for i := 0; i < len(floatArr1) && i < len(floatArr2); i++ {
-		alpha := rand.Float64()
+// ArithmeticExchangeRand is ArithmeticExchange drawing from the random number generator 'rng'
+func (f *FloatMater) ArithmeticExchangeRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	newArr1 := floatValues(g1)
+	newArr2 := floatValues(g2)
+
+	for i := 0; i < len(newArr1) && i < len(newArr2); i++ {
+		alpha := rng.Float64()
 		if alpha < 0.5 {
-			tmp := newArr1[i]
-			newArr1[i] = newArr2[i]
-			newArr2[i] = tmp
+			newArr1[i], newArr2[i] = newArr2[i], newArr1[i]
 		}
 	}
-	return NewGenome(*ParseFloat64ArrToBits(newArr1)), NewGenome(*ParseFloat64ArrToBits(newArr2))
+	return floatGenome(g1, newArr1), floatGenome(g2, newArr2)
 }
 
 // ArithmeticCrossover -
 // Accepts 2 genomes and parse float function
 func (f *FloatMater) ArithmeticCrossover(g1, g2 Genome) (Genome, Genome) {
-	floatArr1 := ParseBitsToFloat64Arr(g1.GetBits())
-	floatArr2 := ParseBitsToFloat64Arr(g2.GetBits())
-	newArr1 := floatArr1[:]
-	newArr2 := floatArr2[:]
+	return f.ArithmeticCrossoverRand(globalRand, g1, g2)
+}
+
+// ArithmeticCrossoverRand is ArithmeticCrossover drawing from the random number generator 'rng'
+func (f *FloatMater) ArithmeticCrossoverRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	floatArr1 := floatValues(g1)
+	floatArr2 := floatValues(g2)
+	newArr1 := append([]float64{}, floatArr1...)
+	newArr2 := append([]float64{}, floatArr2...)
 
 	for i := 0; i < len(floatArr1) && i < len(floatArr2); i++ {
-		precision := f.Precision
-		if v, ok := f.Specific[i]; ok {
-			precision = v.Precision
-		}
-		alpha := rand.Float64()
+		_, _, precision := f.bounds(i)
+		alpha := rng.Float64()
 		newArr1[i] = Round(alpha*floatArr1[i]+(1-alpha)*floatArr2[i], precision)
 		newArr2[i] = Round(alpha*floatArr2[i]+(1-alpha)*floatArr1[i], precision)
 	}
-	return NewGenome(*ParseFloat64ArrToBits(newArr1)), NewGenome(*ParseFloat64ArrToBits(newArr2))
+	return floatGenome(g1, newArr1), floatGenome(g2, newArr2)
 }
 
 // ArithmeticMutate -
 // Accepts 2 genomes and parse float function
 func (f *FloatMater) ArithmeticMutate(g1, g2 Genome) (Genome, Genome) {
-	floatArr1 := ParseBitsToFloat64Arr(g1.GetBits())
-	floatArr2 := ParseBitsToFloat64Arr(g2.GetBits())
-	newArr1 := floatArr1[:]
-	newArr2 := floatArr2[:]
-	randomBit := rand.Intn(len(newArr1))
-	if require, ok := f.Specific[randomBit]; ok {
-		newArr1[randomBit] = Round(rand.Float64()*(require.MaxValue-require.MinValue)+require.MinValue, require.Precision)
-	} else {
-		newArr1[randomBit] = Round(rand.Float64()*(f.MaxValue-f.MinValue)+f.MinValue, f.Precision)
-	}
-	return NewGenome(*ParseFloat64ArrToBits(newArr1)), NewGenome(*ParseFloat64ArrToBits(newArr2))
+	return f.ArithmeticMutateRand(globalRand, g1, g2)
+}
+
+// ArithmeticMutateRand is ArithmeticMutate drawing from the random number generator 'rng'
+func (f *FloatMater) ArithmeticMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	newArr1 := floatValues(g1)
+	newArr2 := floatValues(g2)
+	randomBit := rng.Intn(len(newArr1))
+	newArr1[randomBit] = f.random(rng, randomBit)
+	return floatGenome(g1, newArr1), floatGenome(g2, newArr2)
 }
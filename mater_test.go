@@ -1,6 +1,8 @@
 package goga_test
 
 import (
+	"math/rand"
+
 	. "gopkg.in/check.v1"
 	// "fmt"
 	"github.com/tomcraven/goga"
@@ -400,3 +402,49 @@ func (s *MaterSuite) TestShouldUseEliteFromConfigSettings(t *C) {
 	g1, g2 := goga.NewGenome(goga.Bitset{}), goga.NewGenome(goga.Bitset{})
 	m.Go(g1, g2)
 }
+
+func (s *MaterSuite) TestShouldMateReproduciblyWithSameRand(t *C) {
+	b1, b2 := goga.Bitset{}, goga.Bitset{}
+	b1.Create(32)
+	b2.Create(32)
+	b1.SetAll(0)
+	b2.SetAll(1)
+	g1, g2 := goga.NewGenome(b1), goga.NewGenome(b2)
+
+	mate := func(seed int64) []string {
+		m := goga.NewMater(
+			[]goga.MaterFunctionProbability{
+				{P: 0.5, R: goga.TwoPointCrossoverRand},
+				{P: 0.5, R: goga.UniformCrossoverRand},
+				{P: 0.5, R: goga.MutateRand},
+			},
+		)
+		m.(goga.RandSetter).SetRand(rand.New(goga.NewSource(seed)))
+		var keys []string
+		for i := 0; i < 20; i++ {
+			c1, c2 := m.Go(g1, g2)
+			keys = append(keys, c1.Key(), c2.Key())
+		}
+		return keys
+	}
+	t.Assert(mate(5), DeepEquals, mate(5))
+	t.Assert(mate(5), Not(DeepEquals), mate(6))
+}
+
+func (s *MaterSuite) TestShouldZeroTailWhenCrossingDifferentSizes(t *C) {
+	for _, crossover := range []func(goga.Genome, goga.Genome) (goga.Genome, goga.Genome){
+		goga.OnePointCrossover, goga.TwoPointCrossover, goga.UniformCrossover,
+	} {
+		b1, b2 := goga.Bitset{}, goga.Bitset{}
+		b1.Create(150)
+		b2.Create(100)
+		b1.SetAll(1)
+
+		c1, _ := crossover(goga.NewGenome(b1), goga.NewGenome(b2))
+		c1Bits := c1.GetBits()
+		t.Assert(c1Bits.GetSize(), Equals, 150)
+		for i := 100; i < 150; i++ {
+			t.Assert(c1Bits.Get(i), Equals, 0)
+		}
+	}
+}
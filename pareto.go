@@ -0,0 +1,216 @@
+package goga
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrObjectiveCount is returned by Tell in multi-objective mode when it is passed a genome
+// with a different number of objectives than the genomes told before it, see Objectives
+var ErrObjectiveCount = errors.New("genome has a different number of objectives")
+
+// MultiObjective ranks genomes NSGA-II style on the objectives set by the simulator, see
+// MultiObjectiveGenome, rather than on their fitness
+// At the end of each generation the population and its offspring are sorted into
+// non-dominated fronts and the population is filled front by front, favouring the least
+// crowded genomes of the last front that fits. Each genome is then given a score that orders
+// genomes by front and then by crowding distance, which is passed to the Selector in place of
+// its fitness and picks the elite. The fitness itself is left as the simulator set it, so
+// EliteFitnessHistory, StagnationLimit, the exit function and the EliteConsumer all see the
+// fitness of the elite rather than its score
+// Every objective is maximised, or minimised along with the fitness when the ObjectiveDirection
+// is Minimise, the elite is the least crowded genome of the first front and no random genomes
+// are added to the population
+// Every genome must have the same number of objectives, including those given a penalty by the
+// FailurePolicy, otherwise the run stops with ErrObjectiveCount
+func MultiObjective() Option {
+	return func(o *Options) {
+		o.MultiObjective = true
+	}
+}
+
+// Dominates reports whether 'a' is at least as good as 'b' on every objective
+// and better on at least one, see Objectives
+func Dominates(a, b Genome) bool {
+	return dominates(a, b, Maximise)
+}
+
+// dominates is Dominates for objectives that all follow 'direction'
+func dominates(a, b Genome, direction Direction) bool {
+	oa, ob := Objectives(a), Objectives(b)
+	if direction == Minimise {
+		oa, ob = ob, oa
+	}
+	better := false
+	for i := 0; i < len(oa) && i < len(ob); i++ {
+		if oa[i] < ob[i] {
+			return false
+		}
+		if oa[i] > ob[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// nonDominatedSort splits 'genomes' into fronts, the first front holds the genomes that no
+// other genome dominates, the second those only dominated by the first, and so on
+func nonDominatedSort(genomes []Genome, direction Direction) [][]Genome {
+	dominatedBy := make([]int, len(genomes))
+	dominated := make([][]int, len(genomes))
+	var current []int
+	for i := range genomes {
+		for j := range genomes {
+			if i == j {
+				continue
+			}
+			if dominates(genomes[i], genomes[j], direction) {
+				dominated[i] = append(dominated[i], j)
+			} else if dominates(genomes[j], genomes[i], direction) {
+				dominatedBy[i]++
+			}
+		}
+		if dominatedBy[i] == 0 {
+			current = append(current, i)
+		}
+	}
+
+	var fronts [][]Genome
+	for len(current) > 0 {
+		front := make([]Genome, len(current))
+		var next []int
+		for k, i := range current {
+			front[k] = genomes[i]
+			for _, j := range dominated[i] {
+				dominatedBy[j]--
+				if dominatedBy[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		sort.Ints(next)
+		fronts = append(fronts, front)
+		current = next
+	}
+	return fronts
+}
+
+// crowdingDistance returns how far each genome of 'front' is from its neighbours in objective
+// space, the genomes at either end of any objective are infinitely far
+func crowdingDistance(front []Genome) []float64 {
+	distance := make([]float64, len(front))
+	if len(front) == 0 {
+		return distance
+	}
+	indices := make([]int, len(front))
+	for objective := 0; objective < len(Objectives(front[0])); objective++ {
+		value := func(i int) float64 {
+			return Objectives(front[indices[i]])[objective]
+		}
+		for i := range indices {
+			indices[i] = i
+		}
+		sort.SliceStable(indices, func(i, j int) bool {
+			return value(i) < value(j)
+		})
+
+		first, last := 0, len(indices)-1
+		distance[indices[first]] = math.Inf(1)
+		distance[indices[last]] = math.Inf(1)
+		span := value(last) - value(first)
+		if span == 0 {
+			continue
+		}
+		for i := 1; i < last; i++ {
+			distance[indices[i]] += (value(i+1) - value(i-1)) / span
+		}
+	}
+	return distance
+}
+
+// paretoScores scores every genome of 'genomes' by front and then crowding distance
+// The score of a genome in front 'r' of 'f' fronts lies in [f-r+0.5, f-r+1], growing with its
+// crowding distance, so genomes of better fronts always score higher, the score is negated
+// when minimising so that it compares like fitness
+func paretoScores(genomes []Genome, direction Direction) map[Genome]float64 {
+	fronts := nonDominatedSort(genomes, direction)
+	scores := make(map[Genome]float64, len(genomes))
+	for rank, front := range fronts {
+		distance := crowdingDistance(front)
+		for i, g := range front {
+			scores[g] = direction.directed(float64(len(fronts)-rank) + 1 - 1/(2+distance[i]))
+		}
+	}
+	return scores
+}
+
+// paretoSelect returns the 'n' best of 'genomes' by their 'scores', best first
+func paretoSelect(genomes []Genome, n int, scores map[Genome]float64, direction Direction) []Genome {
+	sorted := append([]Genome{}, genomes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return direction.better(scores[sorted[i]], scores[sorted[j]])
+	})
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	return sorted[:n]
+}
+
+// scored returns 'genomes' carrying their Pareto scores in place of their fitness, to be
+// passed to the Selector, or 'genomes' itself when not in multi-objective mode
+func (ga *GeneticAlgorithm) scored(genomes []Genome) []Genome {
+	if ga.scores == nil {
+		return genomes
+	}
+	ret := make([]Genome, len(genomes))
+	for i, g := range genomes {
+		ret[i] = &scaledGenome{Genome: g, fitness: ga.score(g)}
+	}
+	return ret
+}
+
+// score returns the Pareto score of 'g' in multi-objective mode, otherwise its fitness
+func (ga *GeneticAlgorithm) score(g Genome) float64 {
+	if score, ok := ga.scores[g]; ok {
+		return score
+	}
+	return g.GetFitness()
+}
+
+// checkObjectives returns ErrObjectiveCount in multi-objective mode if the genomes of
+// 'genomes' do not all have as many objectives as the genomes told before them
+func (ga *GeneticAlgorithm) checkObjectives(genomes []Genome) error {
+	if !ga.multiObjective {
+		return nil
+	}
+	count := ga.objectiveCount
+	for _, g := range genomes {
+		n := len(Objectives(g))
+		if count == 0 {
+			count = n
+		} else if n != count {
+			return ErrObjectiveCount
+		}
+	}
+	ga.objectiveCount = count
+	return nil
+}
+
+// rescore scores the population afresh in multi-objective mode, after it changes other than
+// at the end of a generation
+func (ga *GeneticAlgorithm) rescore() {
+	if ga.multiObjective {
+		ga.scores = paretoScores(ga.population, ga.direction)
+	}
+}
+
+// ParetoFront returns the genomes of the population that no other genome of the population
+// dominates, see Dominates and ObjectiveDirection
+func (ga *GeneticAlgorithm) ParetoFront() []Genome {
+	fronts := nonDominatedSort(ga.population, ga.direction)
+	if len(fronts) == 0 {
+		return nil
+	}
+	return fronts[0]
+}
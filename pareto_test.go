@@ -0,0 +1,211 @@
+package goga_test
+
+import (
+	"bytes"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type ParetoSuite struct {
+}
+
+var _ = Suite(&ParetoSuite{})
+
+func helperGenomeWithObjectives(objectives ...float64) goga.Genome {
+	g := goga.NewGenome(goga.Bitset{})
+	g.(goga.MultiObjectiveGenome).SetObjectives(objectives)
+	return g
+}
+
+// MySimulatorTradeOff scores the ones of the first half of the bits against the
+// zeros of the first half, the ones of the second half count towards both
+type MySimulatorTradeOff struct {
+}
+
+func (ms *MySimulatorTradeOff) Simulate(g goga.Genome) {
+	bits := g.GetBits()
+	half := bits.GetSize() / 2
+	ones, zeros, shared := 0, 0, 0
+	for i := 0; i < half; i++ {
+		ones += bits.Get(i)
+		zeros += 1 - bits.Get(i)
+		shared += bits.Get(half + i)
+	}
+	g.(goga.MultiObjectiveGenome).SetObjectives([]float64{float64(ones + shared), float64(zeros + shared)})
+}
+func (ms *MySimulatorTradeOff) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorTradeOff) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorTradeOff) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+func helperGenerateMultiObjectiveGeneticAlgorithm(opt ...goga.Option) goga.GeneticAlgorithm {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorTradeOff{}
+	genAlgo.Selector = goga.NewSelector(
+		[]goga.SelectorFunctionProbability{
+			{P: 1, F: goga.Roulette},
+		},
+	)
+	genAlgo.Init(append([]goga.Option{goga.PopulationSize(20), goga.ParallelSimulations(kNumThreads), goga.MultiObjective()}, opt...)...)
+	return genAlgo
+}
+
+func (s *ParetoSuite) TestShouldDominate(t *C) {
+	t.Assert(goga.Dominates(helperGenomeWithObjectives(2, 2), helperGenomeWithObjectives(1, 2)), IsTrue)
+	t.Assert(goga.Dominates(helperGenomeWithObjectives(1, 2), helperGenomeWithObjectives(2, 2)), IsFalse)
+	t.Assert(goga.Dominates(helperGenomeWithObjectives(2, 1), helperGenomeWithObjectives(1, 2)), IsFalse)
+	t.Assert(goga.Dominates(helperGenomeWithObjectives(1, 2), helperGenomeWithObjectives(2, 1)), IsFalse)
+	t.Assert(goga.Dominates(helperGenomeWithObjectives(1, 1), helperGenomeWithObjectives(1, 1)), IsFalse)
+}
+
+func (s *ParetoSuite) TestShouldFallBackToFitnessAsObjective(t *C) {
+	g := goga.NewGenome(goga.Bitset{})
+	g.SetFitness(3)
+	t.Assert(goga.Objectives(g), DeepEquals, []float64{3})
+	t.Assert(goga.Objectives(helperGenomeWithObjectives(1, 2)), DeepEquals, []float64{1, 2})
+}
+
+func (s *ParetoSuite) TestShouldReturnParetoFront(t *C) {
+	genAlgo := helperGenerateMultiObjectiveGeneticAlgorithm(goga.MaxGenerations(30))
+	result := genAlgo.Simulate()
+	t.Assert(result.ParetoFront, Not(HasLen), 0)
+
+	population := genAlgo.GetPopulation()
+	t.Assert(population, HasLen, 20)
+	onFront := map[goga.Genome]bool{}
+	for _, g := range result.ParetoFront {
+		onFront[g] = true
+		t.Assert(goga.Objectives(g), HasLen, 2)
+		for _, other := range population {
+			t.Assert(goga.Dominates(other, g), IsFalse)
+		}
+	}
+
+	// The population is ordered by front, so genomes on the front come first
+	for i, g := range population {
+		t.Assert(onFront[g], Equals, i < len(result.ParetoFront))
+	}
+	t.Assert(onFront[result.Elite], IsTrue)
+}
+
+func (s *ParetoSuite) TestShouldSpreadFrontAcrossTradeOff(t *C) {
+	genAlgo := helperGenerateMultiObjectiveGeneticAlgorithm(goga.MaxGenerations(60))
+	result := genAlgo.Simulate()
+
+	// The extremes of the front are never crowded out
+	best := []float64{0, 0}
+	for _, g := range result.ParetoFront {
+		for i, o := range goga.Objectives(g) {
+			if o > best[i] {
+				best[i] = o
+			}
+		}
+	}
+	for i := range best {
+		kept := false
+		for _, g := range result.ParetoFront {
+			kept = kept || goga.Objectives(g)[i] == best[i]
+		}
+		t.Assert(kept, IsTrue)
+	}
+
+	// The least crowded genomes are the extremes, one of which is the elite
+	extreme := false
+	for i, o := range goga.Objectives(result.Elite) {
+		extreme = extreme || o == best[i]
+	}
+	t.Assert(extreme, IsTrue)
+}
+
+// MySimulatorTradeOffTotal is MySimulatorTradeOff with the total of the objectives as fitness
+type MySimulatorTradeOffTotal struct {
+	MySimulatorTradeOff
+}
+
+func (ms *MySimulatorTradeOffTotal) Simulate(g goga.Genome) {
+	ms.MySimulatorTradeOff.Simulate(g)
+	total := 0.
+	for _, o := range goga.Objectives(g) {
+		total += o
+	}
+	g.SetFitness(total)
+}
+
+func (s *ParetoSuite) TestShouldKeepFitnessOfGenomes(t *C) {
+	genAlgo := helperGenerateMultiObjectiveGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorTradeOffTotal{}
+	genAlgo.Init(goga.PopulationSize(20), goga.ParallelSimulations(kNumThreads), goga.MultiObjective(), goga.MaxGenerations(10))
+	elites := []goga.Genome{}
+	result := genAlgo.SimulateUntil(func(g goga.Genome) bool {
+		elites = append(elites, g)
+		return false
+	})
+
+	// Fitness is left as simulated rather than replaced with the Pareto score
+	for _, g := range genAlgo.GetPopulation() {
+		objectives := goga.Objectives(g)
+		t.Assert(g.GetFitness(), Equals, objectives[0]+objectives[1])
+	}
+	t.Assert(result.EliteFitnessHistory, HasLen, len(elites))
+	for i, elite := range elites {
+		t.Assert(result.EliteFitnessHistory[i], Equals, elite.GetFitness())
+	}
+}
+
+func (s *ParetoSuite) TestShouldCheckpointObjectives(t *C) {
+	genAlgo := helperGenerateMultiObjectiveGeneticAlgorithm(goga.MaxGenerations(3))
+	genAlgo.Simulate()
+
+	buffer := bytes.Buffer{}
+	t.Assert(genAlgo.WriteCheckpoint(&buffer), IsNil)
+	restored := helperGenerateMultiObjectiveGeneticAlgorithm()
+	t.Assert(restored.RestoreCheckpoint(&buffer), IsNil)
+
+	expected, obtained := genAlgo.GetPopulation(), restored.GetPopulation()
+	for i := range expected {
+		t.Assert(goga.Objectives(obtained[i]), DeepEquals, goga.Objectives(expected[i]))
+	}
+	t.Assert(restored.ParetoFront(), HasLen, len(genAlgo.ParetoFront()))
+}
+
+// MySimulatorTradeOffRagged is MySimulatorTradeOff with a third objective for
+// genomes whose first bit is set
+type MySimulatorTradeOffRagged struct {
+	MySimulatorTradeOff
+}
+
+func (ms *MySimulatorTradeOffRagged) Simulate(g goga.Genome) {
+	ms.MySimulatorTradeOff.Simulate(g)
+	if g.GetBits().Get(0) == 1 {
+		mog := g.(goga.MultiObjectiveGenome)
+		mog.SetObjectives(append(mog.GetObjectives(), 0))
+	}
+}
+
+func (s *ParetoSuite) TestShouldStopWhenObjectiveCountsDiffer(t *C) {
+	genAlgo := helperGenerateMultiObjectiveGeneticAlgorithm(goga.MaxGenerations(10))
+	genAlgo.Simulator = &MySimulatorTradeOffRagged{}
+	result := genAlgo.Simulate()
+	t.Assert(result.Reason, Equals, goga.TerminationError)
+	t.Assert(result.Err, Equals, goga.ErrObjectiveCount)
+}
+
+func (s *ParetoSuite) TestShouldNotTellGenomesWithDifferentObjectiveCounts(t *C) {
+	genAlgo := helperGenerateMultiObjectiveGeneticAlgorithm()
+	genomes := genAlgo.Ask(20)
+	for _, g := range genomes {
+		g.(goga.MultiObjectiveGenome).SetObjectives([]float64{1, 2})
+	}
+	genomes[5].(goga.MultiObjectiveGenome).SetObjectives([]float64{1, 2, 3})
+	t.Assert(genAlgo.Tell(genomes), Equals, goga.ErrObjectiveCount)
+	t.Assert(genAlgo.Generation(), Equals, 0)
+
+	genomes[5].(goga.MultiObjectiveGenome).SetObjectives([]float64{3, 4})
+	t.Assert(genAlgo.Tell(genomes), IsNil)
+	t.Assert(genAlgo.Generation(), Equals, 1)
+}
@@ -0,0 +1,401 @@
+package goga
+
+import (
+	"encoding/binary"
+	"math/rand"
+)
+
+// PermutationGenome - a genome that orders a set of distinct integers, such as the
+// stops of a route or the jobs of a schedule, see NewPermutationGenome
+type PermutationGenome interface {
+	Genome
+	GetPermutation() []int
+}
+
+type permutationGenome struct {
+	permutation []int
+	fitness     float64
+	origin      float64
+	objectives  []float64
+}
+
+// NewPermutationGenome creates a genome holding 'permutation' and a zero'd fitness score
+// Its bits, as returned by GetBits, are a copy of its permutation, 64 bits per element
+// that can be read with Bitset.Uint64, so bit operators should not be used on it, the
+// order-based operators, such as PMX and SwapMutate, keep it a permutation
+func NewPermutationGenome(permutation []int) PermutationGenome {
+	return &permutationGenome{permutation: permutation}
+}
+
+func (g *permutationGenome) GetPermutation() []int {
+	return g.permutation
+}
+
+func (g *permutationGenome) GetFitness() float64 {
+	return g.fitness
+}
+
+func (g *permutationGenome) SetFitness(fitness float64) {
+	g.fitness = fitness
+}
+
+func (g *permutationGenome) GetBits() *Bitset {
+	return intsBits(g.permutation)
+}
+
+func (g *permutationGenome) GetOrigin() float64 {
+	return g.origin
+}
+
+func (g *permutationGenome) SetOrigin(origin float64) {
+	g.origin = origin
+}
+
+func (g *permutationGenome) Key() string {
+	return intsKey(g.permutation)
+}
+
+func (g *permutationGenome) GetObjectives() []float64 {
+	return g.objectives
+}
+
+func (g *permutationGenome) SetObjectives(objectives []float64) {
+	g.objectives = objectives
+}
+
+// Child returns a genome holding a copy of the permutation of the genome
+func (g *permutationGenome) Child() Genome {
+	return NewPermutationGenome(append([]int{}, g.permutation...))
+}
+
+// intsBits returns a bitset holding 'values', 64 bits per value
+func intsBits(values []int) *Bitset {
+	b := Bitset{}
+	b.Create(len(values) * wordSize)
+	for i, v := range values {
+		b.SetUint64(i*wordSize, wordSize, uint64(v))
+	}
+	return &b
+}
+
+// intsKey returns a string that is equal for equal slices of 'values'
+func intsKey(values []int) string {
+	buf := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(buf[8*i:], uint64(v))
+	}
+	return string(buf)
+}
+
+// PermutationGenomeCreate - creates permutation genomes holding a random order
+// of the integers 0 to 'Size' - 1
+type PermutationGenomeCreate struct {
+	Size int
+	rand *rand.Rand
+}
+
+// Go returns a permutation genome of a random order
+func (pgc *PermutationGenomeCreate) Go() Genome {
+	return NewPermutationGenome(RandOrGlobal(pgc.rand).Perm(pgc.Size))
+}
+
+// SetRand sets the random number generator the orders are drawn from
+func (pgc *PermutationGenomeCreate) SetRand(rng *rand.Rand) {
+	pgc.rand = rng
+}
+
+// permutations returns copies of the permutations of 'g1' and 'g2', it panics if either
+// genome is not a PermutationGenome or if they are of different lengths
+func permutations(g1, g2 Genome) ([]int, []int) {
+	pg1, ok1 := g1.(PermutationGenome)
+	pg2, ok2 := g2.(PermutationGenome)
+	if !ok1 || !ok2 {
+		panic("genome is not a permutation")
+	}
+	if len(pg1.GetPermutation()) != len(pg2.GetPermutation()) {
+		panic("permutations are of different lengths")
+	}
+	return append([]int{}, pg1.GetPermutation()...), append([]int{}, pg2.GetPermutation()...)
+}
+
+// positions returns the index of each element of 'permutation'
+func positions(permutation []int) map[int]int {
+	ret := make(map[int]int, len(permutation))
+	for i, v := range permutation {
+		ret[v] = i
+	}
+	return ret
+}
+
+// cutPoints returns two random indices 'a' <= 'b' in [0, n]
+func cutPoints(rng *rand.Rand, n int) (int, int) {
+	a, b := rng.Intn(n+1), rng.Intn(n+1)
+	if a > b {
+		a, b = b, a
+	}
+	return a, b
+}
+
+// PMX - partially mapped crossover
+// Accepts 2 permutation genomes, each child takes the elements between two random cut
+// points from one parent and as many of the others as keep their position from the other
+// parent, the remaining elements are placed by following the mapping between the two
+// segments
+func PMX(g1, g2 Genome) (Genome, Genome) {
+	return PMXRand(globalRand, g1, g2)
+}
+
+// PMXRand is PMX drawing from the random number generator 'rng'
+func PMXRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	p1, p2 := permutations(g1, g2)
+	a, b := cutPoints(rng, len(p1))
+	return NewPermutationGenome(pmx(p1, p2, a, b)), NewPermutationGenome(pmx(p2, p1, a, b))
+}
+
+func pmx(p1, p2 []int, a, b int) []int {
+	child := make([]int, len(p1))
+	filled := make([]bool, len(p1))
+	inSegment := make(map[int]bool, b-a)
+	for i := a; i < b; i++ {
+		child[i] = p1[i]
+		filled[i] = true
+		inSegment[p1[i]] = true
+	}
+	p2Positions := positions(p2)
+	for i := a; i < b; i++ {
+		v := p2[i]
+		if inSegment[v] {
+			continue
+		}
+		j := i
+		for j >= a && j < b {
+			j = p2Positions[p1[j]]
+		}
+		child[j] = v
+		filled[j] = true
+	}
+	for i := range child {
+		if !filled[i] {
+			child[i] = p2[i]
+		}
+	}
+	return child
+}
+
+// OrderCrossover - order crossover (OX1)
+// Accepts 2 permutation genomes, each child takes the elements between two random cut
+// points from one parent and the remaining elements in the order they appear in the other
+// parent, starting after the second cut point
+func OrderCrossover(g1, g2 Genome) (Genome, Genome) {
+	return OrderCrossoverRand(globalRand, g1, g2)
+}
+
+// OrderCrossoverRand is OrderCrossover drawing from the random number generator 'rng'
+func OrderCrossoverRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	p1, p2 := permutations(g1, g2)
+	a, b := cutPoints(rng, len(p1))
+	return NewPermutationGenome(orderCrossover(p1, p2, a, b)), NewPermutationGenome(orderCrossover(p2, p1, a, b))
+}
+
+func orderCrossover(p1, p2 []int, a, b int) []int {
+	n := len(p1)
+	child := make([]int, n)
+	inSegment := make(map[int]bool, b-a)
+	for i := a; i < b; i++ {
+		child[i] = p1[i]
+		inSegment[p1[i]] = true
+	}
+	j := b
+	for k := 0; k < n; k++ {
+		v := p2[(b+k)%n]
+		if inSegment[v] {
+			continue
+		}
+		child[j%n] = v
+		j++
+	}
+	return child
+}
+
+// CycleCrossover - cycle crossover
+// Accepts 2 permutation genomes, the positions are split into the cycles that map one
+// parent onto the other and each child takes alternate cycles from each parent, so every
+// element keeps the position it has in one of the parents
+func CycleCrossover(g1, g2 Genome) (Genome, Genome) {
+	p1, p2 := permutations(g1, g2)
+	child1, child2 := make([]int, len(p1)), make([]int, len(p1))
+	visited := make([]bool, len(p1))
+	p1Positions := positions(p1)
+	cycle := 0
+	for start := range p1 {
+		if visited[start] {
+			continue
+		}
+		for i := start; !visited[i]; i = p1Positions[p2[i]] {
+			visited[i] = true
+			if cycle%2 == 0 {
+				child1[i], child2[i] = p1[i], p2[i]
+			} else {
+				child1[i], child2[i] = p2[i], p1[i]
+			}
+		}
+		cycle++
+	}
+	return NewPermutationGenome(child1), NewPermutationGenome(child2)
+}
+
+// EdgeRecombination - edge recombination crossover
+// Accepts 2 permutation genomes, each child is built by stepping from element to element
+// along the edges, the neighbours of an element, found in either parent, favouring the
+// neighbour with the fewest edges left and only jumping to a random element when there is
+// none. The first child starts with the first element of the first parent, the second
+// child with that of the second parent
+func EdgeRecombination(g1, g2 Genome) (Genome, Genome) {
+	return EdgeRecombinationRand(globalRand, g1, g2)
+}
+
+// EdgeRecombinationRand is EdgeRecombination drawing from the random number generator 'rng'
+func EdgeRecombinationRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	p1, p2 := permutations(g1, g2)
+	if len(p1) == 0 {
+		return NewPermutationGenome(p1), NewPermutationGenome(p2)
+	}
+	return NewPermutationGenome(edgeRecombination(rng, p1, p2, p1[0])), NewPermutationGenome(edgeRecombination(rng, p1, p2, p2[0]))
+}
+
+func edgeRecombination(rng *rand.Rand, p1, p2 []int, current int) []int {
+	n := len(p1)
+	edges := make(map[int][]int, n)
+	addEdge := func(from, to int) {
+		for _, e := range edges[from] {
+			if e == to {
+				return
+			}
+		}
+		edges[from] = append(edges[from], to)
+	}
+	for _, p := range [][]int{p1, p2} {
+		for i, v := range p {
+			addEdge(v, p[(i+n-1)%n])
+			addEdge(v, p[(i+1)%n])
+		}
+	}
+
+	// 'unvisited' holds the elements not yet in the child, in the order of the first parent
+	unvisited := append([]int{}, p1...)
+	child := make([]int, 0, n)
+	for {
+		child = append(child, current)
+		for i, v := range unvisited {
+			if v == current {
+				unvisited = append(unvisited[:i], unvisited[i+1:]...)
+				break
+			}
+		}
+		if len(unvisited) == 0 {
+			return child
+		}
+		for _, neighbour := range edges[current] {
+			remaining := edges[neighbour][:0]
+			for _, e := range edges[neighbour] {
+				if e != current {
+					remaining = append(remaining, e)
+				}
+			}
+			edges[neighbour] = remaining
+		}
+
+		var candidates []int
+		fewest := n + 1
+		for _, neighbour := range edges[current] {
+			switch count := len(edges[neighbour]); {
+			case count < fewest:
+				candidates, fewest = []int{neighbour}, count
+			case count == fewest:
+				candidates = append(candidates, neighbour)
+			}
+		}
+		if len(candidates) == 0 {
+			candidates = unvisited
+		}
+		current = candidates[rng.Intn(len(candidates))]
+	}
+}
+
+// mutatePermutation returns a child of 'g1', whose permutation is changed by 'mutate', and of 'g2'
+func mutatePermutation(g1, g2 Genome, mutate func(p []int)) (Genome, Genome) {
+	p1, p2 := permutations(g1, g2)
+	if len(p1) > 1 {
+		mutate(p1)
+	}
+	return NewPermutationGenome(p1), NewPermutationGenome(p2)
+}
+
+// SwapMutate -
+// Accepts 2 permutation genomes and swaps two random elements of the first
+func SwapMutate(g1, g2 Genome) (Genome, Genome) {
+	return SwapMutateRand(globalRand, g1, g2)
+}
+
+// SwapMutateRand is SwapMutate drawing from the random number generator 'rng'
+func SwapMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	return mutatePermutation(g1, g2, func(p []int) {
+		i, j := rng.Intn(len(p)), rng.Intn(len(p))
+		p[i], p[j] = p[j], p[i]
+	})
+}
+
+// InsertMutate -
+// Accepts 2 permutation genomes and moves a random element of the first to a random position
+func InsertMutate(g1, g2 Genome) (Genome, Genome) {
+	return InsertMutateRand(globalRand, g1, g2)
+}
+
+// InsertMutateRand is InsertMutate drawing from the random number generator 'rng'
+func InsertMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	return mutatePermutation(g1, g2, func(p []int) {
+		from, to := rng.Intn(len(p)), rng.Intn(len(p))
+		v := p[from]
+		if from < to {
+			copy(p[from:to], p[from+1:to+1])
+		} else {
+			copy(p[to+1:from+1], p[to:from])
+		}
+		p[to] = v
+	})
+}
+
+// InversionMutate -
+// Accepts 2 permutation genomes and reverses the order of the elements of the first
+// between two random cut points
+func InversionMutate(g1, g2 Genome) (Genome, Genome) {
+	return InversionMutateRand(globalRand, g1, g2)
+}
+
+// InversionMutateRand is InversionMutate drawing from the random number generator 'rng'
+func InversionMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	return mutatePermutation(g1, g2, func(p []int) {
+		a, b := cutPoints(rng, len(p))
+		for i, j := a, b-1; i < j; i, j = i+1, j-1 {
+			p[i], p[j] = p[j], p[i]
+		}
+	})
+}
+
+// ScrambleMutate -
+// Accepts 2 permutation genomes and shuffles the elements of the first between two
+// random cut points
+func ScrambleMutate(g1, g2 Genome) (Genome, Genome) {
+	return ScrambleMutateRand(globalRand, g1, g2)
+}
+
+// ScrambleMutateRand is ScrambleMutate drawing from the random number generator 'rng'
+func ScrambleMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	return mutatePermutation(g1, g2, func(p []int) {
+		a, b := cutPoints(rng, len(p))
+		segment := p[a:b]
+		rng.Shuffle(len(segment), func(i, j int) {
+			segment[i], segment[j] = segment[j], segment[i]
+		})
+	})
+}
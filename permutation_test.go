@@ -0,0 +1,284 @@
+package goga_test
+
+import (
+	"bytes"
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type PermutationSuite struct {
+}
+
+var _ = Suite(&PermutationSuite{})
+
+// MySimulatorSortedness scores permutation genomes by the number of neighbouring
+// elements that are in ascending order
+type MySimulatorSortedness struct {
+}
+
+func (ms *MySimulatorSortedness) Simulate(g goga.Genome) {
+	p := g.(goga.PermutationGenome).GetPermutation()
+	fitness := 0
+	for i := 1; i < len(p); i++ {
+		if p[i-1] < p[i] {
+			fitness++
+		}
+	}
+	g.SetFitness(float64(fitness))
+}
+func (ms *MySimulatorSortedness) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorSortedness) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorSortedness) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+type permutationOperator func(*rand.Rand, goga.Genome, goga.Genome) (goga.Genome, goga.Genome)
+
+var permutationCrossovers = []permutationOperator{
+	goga.PMXRand,
+	goga.OrderCrossoverRand,
+	func(rng *rand.Rand, g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
+		return goga.CycleCrossover(g1, g2)
+	},
+	goga.EdgeRecombinationRand,
+}
+
+var permutationMutations = []permutationOperator{
+	goga.SwapMutateRand,
+	goga.InsertMutateRand,
+	goga.InversionMutateRand,
+	goga.ScrambleMutateRand,
+}
+
+func helperGeneratePermutationGeneticAlgorithm(opt ...goga.Option) goga.GeneticAlgorithm {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorSortedness{}
+	genAlgo.GenomeCreate = &goga.PermutationGenomeCreate{Size: 12}
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, R: goga.TournamentRand(3, 1)},
+	})
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 1, R: goga.OrderCrossoverRand},
+		{P: 0.5, R: goga.InversionMutateRand},
+		{P: 0.5, R: goga.SwapMutateRand},
+	})
+	genAlgo.Init(append([]goga.Option{goga.PopulationSize(30), goga.ParallelSimulations(kNumThreads), goga.Seed(5)}, opt...)...)
+	return genAlgo
+}
+
+func helperAssertPermutation(t *C, g goga.Genome, n int) []int {
+	p := g.(goga.PermutationGenome).GetPermutation()
+	t.Assert(p, HasLen, n)
+	seen := make([]bool, n)
+	for _, v := range p {
+		t.Assert(v >= 0 && v < n, IsTrue)
+		t.Assert(seen[v], IsFalse, Commentf("%v repeats %v", p, v))
+		seen[v] = true
+	}
+	return p
+}
+
+func (s *PermutationSuite) TestShouldHoldPermutation(t *C) {
+	g := goga.NewPermutationGenome([]int{2, 0, 1})
+	t.Assert(g.GetPermutation(), DeepEquals, []int{2, 0, 1})
+
+	bits := g.GetBits()
+	t.Assert(bits.GetSize(), Equals, 3*64)
+	first, _ := bits.Uint64(0, 64)
+	t.Assert(first, Equals, uint64(2))
+
+	t.Assert(g.Key(), Equals, goga.NewPermutationGenome([]int{2, 0, 1}).Key())
+	t.Assert(g.Key() == goga.NewPermutationGenome([]int{2, 1, 0}).Key(), IsFalse)
+}
+
+func (s *PermutationSuite) TestShouldCreateRandomPermutations(t *C) {
+	create := &goga.PermutationGenomeCreate{Size: 10}
+	create.SetRand(rand.New(goga.NewSource(1)))
+	keys := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		g := create.Go()
+		helperAssertPermutation(t, g, 10)
+		keys[g.Key()] = true
+	}
+	t.Assert(len(keys) > 40, IsTrue)
+}
+
+func (s *PermutationSuite) TestShouldKeepPermutationsValid(t *C) {
+	rng := rand.New(goga.NewSource(1))
+	for _, operator := range append(append([]permutationOperator{}, permutationCrossovers...), permutationMutations...) {
+		for i := 0; i < 100; i++ {
+			n := rng.Intn(12) + 1
+			parent1, parent2 := goga.NewPermutationGenome(rng.Perm(n)), goga.NewPermutationGenome(rng.Perm(n))
+			expected1 := append([]int{}, parent1.GetPermutation()...)
+			expected2 := append([]int{}, parent2.GetPermutation()...)
+
+			child1, child2 := operator(rng, parent1, parent2)
+			helperAssertPermutation(t, child1, n)
+			helperAssertPermutation(t, child2, n)
+
+			// The parents are left untouched
+			t.Assert(parent1.GetPermutation(), DeepEquals, expected1)
+			t.Assert(parent2.GetPermutation(), DeepEquals, expected2)
+		}
+	}
+}
+
+func (s *PermutationSuite) TestShouldCycleCrossover(t *C) {
+	child1, child2 := goga.CycleCrossover(
+		goga.NewPermutationGenome([]int{1, 2, 3, 4, 5, 6, 7, 8}),
+		goga.NewPermutationGenome([]int{8, 5, 2, 1, 3, 6, 4, 7}))
+	t.Assert(child1.(goga.PermutationGenome).GetPermutation(), DeepEquals, []int{1, 5, 2, 4, 3, 6, 7, 8})
+	t.Assert(child2.(goga.PermutationGenome).GetPermutation(), DeepEquals, []int{8, 2, 3, 1, 5, 6, 4, 7})
+}
+
+// helperHasSegment reports whether 'child' holds the elements of 'p1' between some
+// cut points and 'rest' holds for those cut points
+func helperHasSegment(child, p1 []int, rest func(a, b int, inSegment map[int]bool) bool) bool {
+	for a := 0; a <= len(p1); a++ {
+		for b := a; b <= len(p1); b++ {
+			inSegment := map[int]bool{}
+			matches := true
+			for i := a; i < b; i++ {
+				inSegment[p1[i]] = true
+				matches = matches && child[i] == p1[i]
+			}
+			if matches && rest(a, b, inSegment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *PermutationSuite) TestShouldCrossSegmentsInPMXAndOrderCrossover(t *C) {
+	rng := rand.New(goga.NewSource(2))
+	p1 := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	p2 := []int{9, 3, 7, 8, 2, 6, 5, 1, 0, 4}
+	parent1, parent2 := goga.NewPermutationGenome(p1), goga.NewPermutationGenome(p2)
+	for i := 0; i < 50; i++ {
+		// Outside the segment a PMX child keeps the elements of the second parent
+		// in place unless they were moved by the segment
+		child, _ := goga.PMXRand(rng, parent1, parent2)
+		c := helperAssertPermutation(t, child, 10)
+		t.Assert(helperHasSegment(c, p1, func(a, b int, inSegment map[int]bool) bool {
+			for j := range c {
+				if (j < a || j >= b) && c[j] != p2[j] && !inSegment[p2[j]] {
+					return false
+				}
+			}
+			return true
+		}), IsTrue, Commentf("%v", c))
+
+		// After the segment an OX1 child holds the other elements in the order the
+		// second parent holds them, starting after the segment
+		child, _ = goga.OrderCrossoverRand(rng, parent1, parent2)
+		c = helperAssertPermutation(t, child, 10)
+		t.Assert(helperHasSegment(c, p1, func(a, b int, inSegment map[int]bool) bool {
+			j := b
+			for k := 0; k < len(p2); k++ {
+				if v := p2[(b+k)%len(p2)]; !inSegment[v] {
+					if c[j%len(c)] != v {
+						return false
+					}
+					j++
+				}
+			}
+			return true
+		}), IsTrue, Commentf("%v", c))
+	}
+}
+
+func (s *PermutationSuite) TestShouldRecombineEdgesOfParents(t *C) {
+	rng := rand.New(goga.NewSource(3))
+	parent := goga.NewPermutationGenome([]int{4, 2, 7, 0, 6, 1, 3, 5})
+	p := parent.GetPermutation()
+	adjacent := func(a, b int) bool {
+		for i := range p {
+			if p[i] == a && (p[(i+1)%len(p)] == b || p[(i+len(p)-1)%len(p)] == b) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// With identical parents every step of the child follows an edge of the parents
+	for i := 0; i < 20; i++ {
+		child1, _ := goga.EdgeRecombinationRand(rng, parent, parent)
+		c := helperAssertPermutation(t, child1, 8)
+		t.Assert(c[0], Equals, 4)
+		for j := 1; j < len(c); j++ {
+			t.Assert(adjacent(c[j-1], c[j]), IsTrue, Commentf("%v", c))
+		}
+	}
+}
+
+func (s *PermutationSuite) TestShouldMutateFirstGenomeOnly(t *C) {
+	rng := rand.New(goga.NewSource(4))
+	for _, mutation := range permutationMutations {
+		parent1 := goga.NewPermutationGenome(rng.Perm(10))
+		parent2 := goga.NewPermutationGenome(rng.Perm(10))
+		_, child2 := mutation(rng, parent1, parent2)
+		t.Assert(child2.(goga.PermutationGenome).GetPermutation(), DeepEquals, parent2.GetPermutation())
+	}
+
+	// A swap changes no more than two elements
+	for i := 0; i < 20; i++ {
+		parent := goga.NewPermutationGenome(rng.Perm(10))
+		child, _ := goga.SwapMutateRand(rng, parent, parent)
+		changed := 0
+		for j, v := range child.(goga.PermutationGenome).GetPermutation() {
+			if v != parent.GetPermutation()[j] {
+				changed++
+			}
+		}
+		t.Assert(changed == 0 || changed == 2, IsTrue)
+	}
+}
+
+func (s *PermutationSuite) TestShouldPanicWithNonPermutationGenomes(t *C) {
+	b := goga.Bitset{}
+	b.Create(3)
+	t.Assert(func() { goga.PMX(goga.NewGenome(b), goga.NewPermutationGenome([]int{0, 1, 2})) }, Panics, "genome is not a permutation")
+	t.Assert(func() { goga.PMX(goga.NewPermutationGenome([]int{0, 1}), goga.NewPermutationGenome([]int{0, 1, 2})) }, Panics, "permutations are of different lengths")
+}
+
+func (s *PermutationSuite) TestShouldEvolvePermutations(t *C) {
+	genAlgo := helperGeneratePermutationGeneticAlgorithm(goga.MaxGenerations(40))
+	result := genAlgo.Simulate()
+	for _, g := range genAlgo.GetPopulation() {
+		helperAssertPermutation(t, g, 12)
+	}
+	// A random permutation of 12 has about 5.5 ascending neighbours, a sorted one 11
+	t.Assert(result.Elite.GetFitness() >= 9, IsTrue, Commentf("%v", result.Elite.GetFitness()))
+}
+
+func (s *PermutationSuite) TestShouldRestorePermutationsFromCheckpoint(t *C) {
+	genAlgo := helperGeneratePermutationGeneticAlgorithm(goga.MaxGenerations(3))
+	genAlgo.Simulate()
+
+	buffer := bytes.Buffer{}
+	t.Assert(genAlgo.WriteCheckpoint(&buffer), IsNil)
+	restored := helperGeneratePermutationGeneticAlgorithm()
+	t.Assert(restored.RestoreCheckpoint(&buffer), IsNil)
+
+	expected, obtained := genAlgo.GetPopulation(), restored.GetPopulation()
+	t.Assert(obtained, HasLen, len(expected))
+	for i := range expected {
+		t.Assert(obtained[i].(goga.PermutationGenome).GetPermutation(), DeepEquals, expected[i].(goga.PermutationGenome).GetPermutation())
+	}
+}
+
+func (s *PermutationSuite) TestShouldMarshalPermutationGenome(t *C) {
+	data, err := goga.MarshalGenome(goga.NewPermutationGenome([]int{1, 0, 2}))
+	t.Assert(err, IsNil)
+	t.Assert(string(data), Equals, `{"fitness":0,"origin":0,"permutation":[1,0,2]}`)
+
+	decoded, err := goga.UnmarshalGenome(data)
+	t.Assert(err, IsNil)
+	t.Assert(decoded.(goga.PermutationGenome).GetPermutation(), DeepEquals, []int{1, 0, 2})
+}
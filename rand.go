@@ -0,0 +1,99 @@
+package goga
+
+import (
+	"context"
+	"math/rand"
+)
+
+// RandSetter - an optional interface for components that draw random numbers
+// The genetic algorithm passes its random number generator, see the RandSource and Seed
+// options, to its Selector, Mater, BitsetCreate and GenomeCreate when they implement it so that a run
+// can be reproduced
+type RandSetter interface {
+	SetRand(*rand.Rand)
+}
+
+// Source - a small, fast rand.Source64 (splitmix64) whose state can be saved and
+// restored, which lets checkpoints carry on a seeded run exactly where it stopped
+type Source struct {
+	state uint64
+}
+
+// NewSource returns a Source seeded with 'seed'
+func NewSource(seed int64) *Source {
+	return &Source{state: uint64(seed)}
+}
+
+// Seed resets the source to the state for 'seed'
+func (s *Source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Uint64 returns a pseudo-random 64-bit value
+func (s *Source) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer
+func (s *Source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// State returns the internal state of the source
+func (s *Source) State() uint64 {
+	return s.state
+}
+
+// SetState restores a state previously returned by State
+func (s *Source) SetState(state uint64) {
+	s.state = state
+}
+
+// globalSource draws from the top level math/rand functions, which are safe for concurrent use
+type globalSource struct {
+}
+
+func (gs globalSource) Int63() int64 {
+	return rand.Int63()
+}
+
+// Seed does nothing, the top level math/rand functions are seeded with rand.Seed
+func (gs globalSource) Seed(seed int64) {
+}
+
+// globalRand is used wherever no random number generator has been given
+var globalRand = rand.New(globalSource{})
+
+// RandOrGlobal returns 'rng', or when it is nil a generator drawing from the top level
+// math/rand functions, for components that implement RandSetter but may not be given a generator
+func RandOrGlobal(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return globalRand
+	}
+	return rng
+}
+
+type randContextKey struct {
+}
+
+// contextWithRand returns a copy of 'ctx' that carries 'rng'
+func contextWithRand(ctx context.Context, rng *rand.Rand) context.Context {
+	return context.WithValue(ctx, randContextKey{}, rng)
+}
+
+// RandFromContext returns the random number generator for the simulation of a single genome
+// from the context passed to ContextSimulator.SimulateContext
+// When the genetic algorithm has a RandSource or Seed option each simulation is given its own
+// generator, derived from the algorithm's one in the order genomes are handed out, so that
+// simulations are reproducible whatever the number of ParallelSimulations
+// Otherwise the top level math/rand functions are drawn from
+func RandFromContext(ctx context.Context) *rand.Rand {
+	if rng, ok := ctx.Value(randContextKey{}).(*rand.Rand); ok {
+		return rng
+	}
+	return globalRand
+}
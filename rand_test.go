@@ -0,0 +1,124 @@
+package goga_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type RandSuite struct {
+}
+
+var _ = Suite(&RandSuite{})
+
+func (s *RandSuite) TestShouldRepeatSequenceForSameSeed(t *C) {
+	r1 := rand.New(goga.NewSource(42))
+	r2 := rand.New(goga.NewSource(42))
+	r3 := rand.New(goga.NewSource(43))
+	different := false
+	for i := 0; i < 100; i++ {
+		v1, v3 := r1.Int63(), r3.Int63()
+		t.Assert(v1, Equals, r2.Int63())
+		different = different || v1 != v3
+	}
+	t.Assert(different, IsTrue)
+}
+
+func (s *RandSuite) TestShouldRestoreSourceState(t *C) {
+	source := goga.NewSource(7)
+	source.Uint64()
+	state := source.State()
+	expected := []uint64{source.Uint64(), source.Uint64(), source.Uint64()}
+
+	source.SetState(state)
+	t.Assert([]uint64{source.Uint64(), source.Uint64(), source.Uint64()}, DeepEquals, expected)
+}
+
+func (s *RandSuite) TestShouldFallBackToGlobalRandWithoutContextRand(t *C) {
+	t.Assert(goga.RandFromContext(context.Background()), NotNil)
+}
+
+type MySimulatorNoisy struct {
+}
+
+func (ms *MySimulatorNoisy) Simulate(goga.Genome) {
+	panic("Simulate should not be called on a ContextSimulator")
+}
+func (ms *MySimulatorNoisy) SimulateContext(ctx context.Context, g goga.Genome) {
+	bits := g.GetBits()
+	fitness := 0
+	for i := 0; i < bits.GetSize(); i++ {
+		fitness += bits.Get(i)
+	}
+	g.SetFitness(float64(fitness) + goga.RandFromContext(ctx).Float64())
+}
+func (ms *MySimulatorNoisy) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorNoisy) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorNoisy) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+func helperGenerateSeededGeneticAlgorithm(opt ...goga.Option) goga.GeneticAlgorithm {
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorNoisy{}
+	genAlgo.BitsetCreate = &goga.RandomBitsetCreate{Size: 64}
+	genAlgo.Selector = goga.NewSelector(
+		[]goga.SelectorFunctionProbability{
+			{P: 0.2, R: goga.RandomSelectRand},
+			{P: 1, R: goga.RouletteRand},
+		},
+	)
+	genAlgo.Mater = goga.NewMater(
+		[]goga.MaterFunctionProbability{
+			{P: 1, R: goga.OnePointCrossoverRand},
+			{P: 0.5, R: goga.MutateRand},
+			{P: 0.5, R: goga.UniformCrossoverRand, UseElite: true},
+		},
+	)
+	genAlgo.Init(append([]goga.Option{goga.PopulationSize(20)}, opt...)...)
+	return genAlgo
+}
+
+func helperPopulationKeys(genAlgo *goga.GeneticAlgorithm) []string {
+	var keys []string
+	for _, g := range genAlgo.GetPopulation() {
+		keys = append(keys, g.Key())
+	}
+	return keys
+}
+
+func (s *RandSuite) TestShouldReproduceSeededRunWhateverTheParallelism(t *C) {
+	serial := helperGenerateSeededGeneticAlgorithm(goga.Seed(1234), goga.MaxGenerations(15), goga.ParallelSimulations(1))
+	parallel := helperGenerateSeededGeneticAlgorithm(goga.Seed(1234), goga.MaxGenerations(15), goga.ParallelSimulations(kNumThreads))
+	other := helperGenerateSeededGeneticAlgorithm(goga.Seed(4321), goga.MaxGenerations(15), goga.ParallelSimulations(kNumThreads))
+
+	serialResult, parallelResult, otherResult := serial.Simulate(), parallel.Simulate(), other.Simulate()
+	t.Assert(parallelResult.EliteFitnessHistory, DeepEquals, serialResult.EliteFitnessHistory)
+	t.Assert(helperPopulationKeys(&parallel), DeepEquals, helperPopulationKeys(&serial))
+	t.Assert(otherResult.EliteFitnessHistory, Not(DeepEquals), serialResult.EliteFitnessHistory)
+}
+
+func (s *RandSuite) TestShouldCarryOnSeededRunFromCheckpoint(t *C) {
+	uninterrupted := helperGenerateSeededGeneticAlgorithm(goga.Seed(99), goga.MaxGenerations(10), goga.ParallelSimulations(kNumThreads))
+	expected := uninterrupted.Simulate()
+
+	buffer := &bytes.Buffer{}
+	create := func(int) (io.Writer, error) {
+		return buffer, nil
+	}
+	interrupted := helperGenerateSeededGeneticAlgorithm(goga.Seed(99), goga.MaxGenerations(5), goga.CheckpointEvery(5, create))
+	interrupted.Simulate()
+
+	resumed := helperGenerateSeededGeneticAlgorithm(goga.Seed(0), goga.MaxGenerations(10), goga.ParallelSimulations(kNumThreads))
+	t.Assert(resumed.RestoreCheckpoint(buffer), IsNil)
+	result := resumed.Simulate()
+	t.Assert(result.EliteFitnessHistory, DeepEquals, expected.EliteFitnessHistory)
+	t.Assert(helperPopulationKeys(&resumed), DeepEquals, helperPopulationKeys(&uninterrupted))
+}
@@ -0,0 +1,116 @@
+package goga
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+)
+
+// GenomeCreate - an interface to a genome create struct, used in place of BitsetCreate
+// for genomes that are not held in a bitset, such as real-valued genomes
+type GenomeCreate interface {
+	Go() Genome
+}
+
+// RealGenome - a genome of real values, see NewRealGenome
+type RealGenome interface {
+	Genome
+	GetValues() []float64
+}
+
+type realGenome struct {
+	values     []float64
+	fitness    float64
+	origin     float64
+	objectives []float64
+}
+
+// NewRealGenome creates a real-valued genome holding 'values' and a zero'd fitness score
+// Its bits, as returned by GetBits, are a copy of its values encoded by ParseFloat64ArrToBits,
+// so bit operators should not be used on it, see FloatMater
+func NewRealGenome(values []float64) RealGenome {
+	return &realGenome{values: values}
+}
+
+func (g *realGenome) GetValues() []float64 {
+	return g.values
+}
+
+func (g *realGenome) GetFitness() float64 {
+	return g.fitness
+}
+
+func (g *realGenome) SetFitness(fitness float64) {
+	g.fitness = fitness
+}
+
+func (g *realGenome) GetBits() *Bitset {
+	return ParseFloat64ArrToBits(g.values)
+}
+
+func (g *realGenome) GetOrigin() float64 {
+	return g.origin
+}
+
+func (g *realGenome) SetOrigin(origin float64) {
+	g.origin = origin
+}
+
+func (g *realGenome) Key() string {
+	buf := make([]byte, 8*len(g.values))
+	for i, v := range g.values {
+		binary.LittleEndian.PutUint64(buf[8*i:], math.Float64bits(v))
+	}
+	return string(buf)
+}
+
+func (g *realGenome) GetObjectives() []float64 {
+	return g.objectives
+}
+
+func (g *realGenome) SetObjectives(objectives []float64) {
+	g.objectives = objectives
+}
+
+// Child returns a genome holding a copy of the values of the genome
+func (g *realGenome) Child() Genome {
+	return NewRealGenome(append([]float64{}, g.values...))
+}
+
+// bounds returns the range and precision of the value at index 'i'
+func (r *Float64Requirement) bounds(i int) (min, max, precision float64) {
+	if require, ok := r.Specific[i]; ok {
+		return require.MinValue, require.MaxValue, require.Precision
+	}
+	return r.MinValue, r.MaxValue, r.Precision
+}
+
+// random returns a value drawn evenly from the range of the value at index 'i',
+// rounded to its precision
+func (r *Float64Requirement) random(rng *rand.Rand, i int) float64 {
+	min, max, precision := r.bounds(i)
+	return Round(rng.Float64()*(max-min)+min, precision)
+}
+
+// RealGenomeCreate - creates real-valued genomes of 'Size' values, each drawn
+// evenly from its range in the Float64Requirement
+type RealGenomeCreate struct {
+	Float64Requirement
+	Size int
+	rand *rand.Rand
+}
+
+// Go returns a real-valued genome of random values
+func (rgc *RealGenomeCreate) Go() Genome {
+	rng := RandOrGlobal(rgc.rand)
+	values := make([]float64, rgc.Size)
+	for i := range values {
+		values[i] = rgc.random(rng, i)
+	}
+	return NewRealGenome(values)
+}
+
+// SetRand sets the random number generator the values are drawn from
+func (rgc *RealGenomeCreate) SetRand(rng *rand.Rand) {
+	rgc.rand = rng
+}
@@ -0,0 +1,191 @@
+package goga_test
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type RealGenomeSuite struct {
+}
+
+var _ = Suite(&RealGenomeSuite{})
+
+// MySimulatorNegativeSquares scores real-valued genomes by the negative sum of the
+// squares of their values, which peaks at 0 when every value is 0
+type MySimulatorNegativeSquares struct {
+}
+
+func (ms *MySimulatorNegativeSquares) Simulate(g goga.Genome) {
+	fitness := 0.
+	for _, v := range g.(goga.RealGenome).GetValues() {
+		fitness -= v * v
+	}
+	g.SetFitness(fitness)
+}
+func (ms *MySimulatorNegativeSquares) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorNegativeSquares) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorNegativeSquares) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+var realRequirement = goga.Float64Requirement{
+	Precision: 0.01,
+	MinValue:  -5,
+	MaxValue:  5,
+	Specific: map[int]struct {
+		Precision float64
+		MaxValue  float64
+		MinValue  float64
+	}{
+		1: {Precision: 1, MinValue: 10, MaxValue: 20},
+	},
+}
+
+func helperGenerateRealGeneticAlgorithm(opt ...goga.Option) goga.GeneticAlgorithm {
+	floatMater := goga.FloatMater{Float64Requirement: realRequirement}
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorNegativeSquares{}
+	genAlgo.GenomeCreate = &goga.RealGenomeCreate{Float64Requirement: realRequirement, Size: 3}
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: goga.Roulette},
+	})
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 0.5, R: floatMater.ArithmeticMutateRand},
+		{P: 1, R: floatMater.ArithmeticCrossoverRand},
+		{P: 0.5, R: floatMater.ArithmeticExchangeRand},
+	})
+	genAlgo.Init(append([]goga.Option{goga.PopulationSize(20), goga.ParallelSimulations(kNumThreads), goga.Seed(3)}, opt...)...)
+	return genAlgo
+}
+
+func helperAssertRealValuesInRange(t *C, g goga.Genome) {
+	values := g.(goga.RealGenome).GetValues()
+	t.Assert(values, HasLen, 3)
+	for i, v := range values {
+		min, max := realRequirement.MinValue, realRequirement.MaxValue
+		if i == 1 {
+			min, max = 10, 20
+		}
+		t.Assert(v >= min && v <= max, IsTrue, Commentf("value %v of %v", i, v))
+	}
+	t.Assert(values[1], Equals, math.Round(values[1]))
+}
+
+func (s *RealGenomeSuite) TestShouldHoldValues(t *C) {
+	g := goga.NewRealGenome([]float64{1.5, -2})
+	t.Assert(g.GetValues(), DeepEquals, []float64{1.5, -2})
+	g.SetFitness(3)
+	g.SetOrigin(4)
+	t.Assert(g.GetFitness(), Equals, 3.)
+	t.Assert(g.GetOrigin(), Equals, 4.)
+
+	// Bits are the float64s as encoded by ParseFloat64ArrToBits
+	t.Assert(goga.ParseBitsToFloat64Arr(g.GetBits()), DeepEquals, []float64{1.5, -2})
+
+	t.Assert(g.Key(), Equals, goga.NewRealGenome([]float64{1.5, -2}).Key())
+	t.Assert(g.Key() == goga.NewRealGenome([]float64{1.5, -2.5}).Key(), IsFalse)
+
+	g.(goga.MultiObjectiveGenome).SetObjectives([]float64{1, 2})
+	t.Assert(goga.Objectives(g), DeepEquals, []float64{1, 2})
+}
+
+func (s *RealGenomeSuite) TestShouldCreateValuesWithinRequirement(t *C) {
+	create := &goga.RealGenomeCreate{Float64Requirement: realRequirement, Size: 3}
+	create.SetRand(rand.New(goga.NewSource(1)))
+	for i := 0; i < 100; i++ {
+		helperAssertRealValuesInRange(t, create.Go())
+	}
+
+	create1 := &goga.RealGenomeCreate{Float64Requirement: realRequirement, Size: 3}
+	create1.SetRand(rand.New(goga.NewSource(2)))
+	create2 := &goga.RealGenomeCreate{Float64Requirement: realRequirement, Size: 3}
+	create2.SetRand(rand.New(goga.NewSource(2)))
+	t.Assert(create1.Go().Key(), Equals, create2.Go().Key())
+}
+
+func (s *RealGenomeSuite) TestShouldMateRealValuesWithFloatMater(t *C) {
+	floatMater := goga.FloatMater{Float64Requirement: realRequirement}
+	rng := rand.New(goga.NewSource(1))
+	parent1 := goga.NewRealGenome([]float64{-5, 10, 5})
+	parent2 := goga.NewRealGenome([]float64{5, 20, -5})
+
+	for _, mate := range []func(*rand.Rand, goga.Genome, goga.Genome) (goga.Genome, goga.Genome){
+		floatMater.ArithmeticExchangeRand,
+		floatMater.ArithmeticCrossoverRand,
+		floatMater.ArithmeticMutateRand,
+	} {
+		for i := 0; i < 20; i++ {
+			child1, child2 := mate(rng, parent1, parent2)
+			helperAssertRealValuesInRange(t, child1)
+			helperAssertRealValuesInRange(t, child2)
+		}
+	}
+
+	// The parents are left untouched
+	t.Assert(parent1.GetValues(), DeepEquals, []float64{-5, 10, 5})
+	t.Assert(parent2.GetValues(), DeepEquals, []float64{5, 20, -5})
+}
+
+func (s *RealGenomeSuite) TestShouldKeepRealGenomesThroughMater(t *C) {
+	floatMater := goga.FloatMater{Float64Requirement: realRequirement}
+	for _, m := range []goga.Mater{
+		&goga.NullMater{},
+		goga.NewMater([]goga.MaterFunctionProbability{{P: 1, F: floatMater.ArithmeticCrossover}}),
+	} {
+		child1, child2 := m.Go(goga.NewRealGenome([]float64{1, 10, 2}), goga.NewRealGenome([]float64{2, 20, 1}))
+		helperAssertRealValuesInRange(t, child1)
+		helperAssertRealValuesInRange(t, child2)
+	}
+}
+
+func (s *RealGenomeSuite) TestShouldEvolveRealGenomes(t *C) {
+	genAlgo := helperGenerateRealGeneticAlgorithm(goga.MaxGenerations(60))
+	for _, g := range genAlgo.GetPopulation() {
+		helperAssertRealValuesInRange(t, g)
+	}
+
+	result := genAlgo.Simulate()
+	for _, g := range genAlgo.GetPopulation() {
+		helperAssertRealValuesInRange(t, g)
+	}
+	// The fittest possible genome scores -100, {0, 10, 0}
+	t.Assert(result.Elite.GetFitness() > -102, IsTrue, Commentf("%v", result.Elite.GetFitness()))
+}
+
+func (s *RealGenomeSuite) TestShouldRestoreRealGenomesFromCheckpoint(t *C) {
+	genAlgo := helperGenerateRealGeneticAlgorithm(goga.MaxGenerations(3))
+	genAlgo.Simulate()
+
+	buffer := bytes.Buffer{}
+	t.Assert(genAlgo.WriteCheckpoint(&buffer), IsNil)
+	restored := helperGenerateRealGeneticAlgorithm()
+	t.Assert(restored.RestoreCheckpoint(&buffer), IsNil)
+
+	expected, obtained := genAlgo.GetPopulation(), restored.GetPopulation()
+	t.Assert(obtained, HasLen, len(expected))
+	for i := range expected {
+		t.Assert(obtained[i].(goga.RealGenome).GetValues(), DeepEquals, expected[i].(goga.RealGenome).GetValues())
+		t.Assert(obtained[i].GetFitness(), Equals, expected[i].GetFitness())
+	}
+}
+
+func (s *RealGenomeSuite) TestShouldMarshalRealGenome(t *C) {
+	g := goga.NewRealGenome([]float64{0.25, -1})
+	g.SetFitness(2)
+
+	data, err := goga.MarshalGenome(g)
+	t.Assert(err, IsNil)
+	t.Assert(string(data), Equals, `{"fitness":2,"origin":0,"values":[0.25,-1]}`)
+
+	decoded, err := goga.UnmarshalGenome(data)
+	t.Assert(err, IsNil)
+	t.Assert(decoded.(goga.RealGenome).GetValues(), DeepEquals, g.GetValues())
+	t.Assert(decoded.GetFitness(), Equals, 2.)
+}
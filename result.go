@@ -0,0 +1,104 @@
+package goga
+
+import "time"
+
+// TerminationReason describes why a run of the genetic algorithm stopped
+type TerminationReason int
+
+const (
+	// TerminationExitFunc - the exit function returned true for the elite
+	TerminationExitFunc TerminationReason = iota
+	// TerminationContext - the context passed to SimulateContext was done
+	TerminationContext
+	// TerminationGenerationBudget - the maximum number of generations was simulated
+	TerminationGenerationBudget
+	// TerminationStagnation - the elite fitness did not improve for too many generations
+	TerminationStagnation
+	// TerminationError - the run could not carry on because of an error
+	TerminationError
+	// TerminationEvaluationBudget - the maximum number of evaluations was spent
+	TerminationEvaluationBudget
+	// TerminationTimeBudget - the maximum wall-clock time of the run passed
+	TerminationTimeBudget
+)
+
+func (r TerminationReason) String() string {
+	switch r {
+	case TerminationExitFunc:
+		return "exit func"
+	case TerminationContext:
+		return "context"
+	case TerminationGenerationBudget:
+		return "generation budget"
+	case TerminationStagnation:
+		return "stagnation"
+	case TerminationError:
+		return "error"
+	case TerminationEvaluationBudget:
+		return "evaluation budget"
+	case TerminationTimeBudget:
+		return "time budget"
+	}
+	return "unknown"
+}
+
+// Result - the outcome of a run of the genetic algorithm
+// * Elite - the elite of the last fully simulated generation, nil if no generation completed
+// * Generations - the number of fully simulated generations, including the initial population
+// * Evaluations - the number of genomes passed to the simulator
+// * Duration - the wall-clock time of the run
+// * Reason - why the run stopped
+// * EliteFitnessHistory - the fitness of the elite of each fully simulated generation
+// * Err - the error that stopped the run, if any, as also returned by SimulateContext
+// * CacheHits - the number of bred genomes whose key was in the duplicate genome cache, see
+// DuplicateGenomes
+// * CacheMisses - the number of bred genomes whose key was not in the duplicate genome cache
+// * Failures - the number of genomes whose simulation failed, after any retries, see SimulationFailures
+// * Timeouts - the number of genomes whose simulation timed out, see SimulationTimeout
+// * ParetoFront - the non-dominated genomes of the last fully simulated generation, only set
+// in multi-objective mode, see MultiObjective
+type Result struct {
+	Elite               Genome
+	Generations         int
+	Evaluations         int
+	Duration            time.Duration
+	Reason              TerminationReason
+	EliteFitnessHistory []float64
+	Err                 error
+	ParetoFront         []Genome
+	CacheHits           int
+	CacheMisses         int
+	Failures            int
+	Timeouts            int
+
+	bestFitness         float64
+	stagnantGenerations int
+}
+
+// record records the elite of the generation that has just been simulated
+func (r *Result) record(elite Genome, direction Direction) {
+	if r.Generations == 0 || direction.better(elite.GetFitness(), r.bestFitness) {
+		r.bestFitness = elite.GetFitness()
+		r.stagnantGenerations = 0
+	} else {
+		r.stagnantGenerations++
+	}
+	r.Elite = elite
+	r.Generations++
+	r.EliteFitnessHistory = append(r.EliteFitnessHistory, elite.GetFitness())
+}
+
+// limitReached reports whether, and why, a run that has recorded this result should stop
+// because of its MaxGenerations, StagnationLimit or MaxEvaluations options
+func (r *Result) limitReached(maxGenerations, stagnationLimit, maxEvaluations int) (TerminationReason, bool) {
+	if maxGenerations > 0 && r.Generations >= maxGenerations {
+		return TerminationGenerationBudget, true
+	}
+	if maxEvaluations > 0 && r.Evaluations >= maxEvaluations {
+		return TerminationEvaluationBudget, true
+	}
+	if stagnationLimit > 0 && r.stagnantGenerations >= stagnationLimit {
+		return TerminationStagnation, true
+	}
+	return TerminationExitFunc, false
+}
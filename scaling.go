@@ -0,0 +1,189 @@
+package goga
+
+import (
+	"math"
+	"sort"
+)
+
+// Direction - whether the genetic algorithm looks for the highest or the lowest fitness
+type Direction int
+
+const (
+	// Maximise - the fittest genome has the highest fitness
+	Maximise Direction = iota
+	// Minimise - the fittest genome has the lowest fitness
+	Minimise
+)
+
+// better reports whether fitness 'a' is strictly better than fitness 'b'
+func (d Direction) better(a, b float64) bool {
+	if d == Minimise {
+		return a < b
+	}
+	return a > b
+}
+
+// directed returns 'fitness' such that higher is always better
+func (d Direction) directed(fitness float64) float64 {
+	if d == Minimise {
+		return -fitness
+	}
+	return fitness
+}
+
+// Scaling - how fitness is scaled before it is passed to the Selector
+type Scaling int
+
+const (
+	// NoScaling - the Selector is passed the fitness as it is, negated when minimising
+	NoScaling Scaling = iota
+	// LinearScaling - fitness is shifted so that the least fit genome scores 0 and then scaled
+	// linearly so that the average is kept and the fittest genome scores at most twice the
+	// average, which stops a few fit genomes taking over the population early on
+	LinearScaling
+	// SigmaScaling - fitness is scaled to 1 + (f - mean) / (2 * standard deviation), clamped at 0,
+	// which keeps the selection pressure steady as the spread of fitness changes
+	SigmaScaling
+	// RankScaling - fitness is replaced with its rank, from 1 for the least fit genome to the
+	// population size for the fittest, which ignores how far apart genomes score
+	RankScaling
+)
+
+// ObjectiveDirection sets whether the genetic algorithm maximises, the default, or minimises fitness
+// The elite, the ordering of the population, stagnation and the fitness scaling all follow it
+func ObjectiveDirection(d Direction) Option {
+	return func(o *Options) {
+		o.Direction = d
+	}
+}
+
+// DirectionSetter - an optional interface for simulators whose fitness depends on whether it
+// is maximised or minimised, see LengthPenalty
+// The genetic algorithm calls SetDirection with its ObjectiveDirection before each generation
+type DirectionSetter interface {
+	SetDirection(Direction)
+}
+
+// FitnessScaling scales fitness before it is passed to the Selector, see Scaling
+// The Selector is passed genomes whose GetFitness returns the scaled fitness, which is always
+// higher for fitter genomes and, other than with NoScaling, never negative, along with the
+// total of the scaled fitness; the genomes are handed on to the Mater unchanged
+func FitnessScaling(s Scaling) Option {
+	return func(o *Options) {
+		o.Scaling = s
+	}
+}
+
+// scaledGenome - a genome as passed to the Selector, with its fitness scaled for selection
+type scaledGenome struct {
+	Genome
+	fitness float64
+}
+
+func (sg *scaledGenome) GetFitness() float64 {
+	return sg.fitness
+}
+
+// unscaled returns the genome that 'g' scales, or 'g' itself
+func unscaled(g Genome) Genome {
+	for {
+		sg, ok := g.(*scaledGenome)
+		if !ok {
+			return g
+		}
+		g = sg.Genome
+	}
+}
+
+// scaleFitness returns 'genomes' as they should be passed to the Selector along with their total
+// fitness, when maximising without scaling they are returned as they are
+func scaleFitness(genomes []Genome, direction Direction, scaling Scaling) ([]Genome, float64) {
+	if direction == Maximise && scaling == NoScaling {
+		total := 0.
+		for _, g := range genomes {
+			total += g.GetFitness()
+		}
+		return genomes, total
+	}
+
+	fitness := make([]float64, len(genomes))
+	for i, g := range genomes {
+		fitness[i] = direction.directed(g.GetFitness())
+	}
+	switch scaling {
+	case LinearScaling:
+		linearScale(fitness)
+	case SigmaScaling:
+		sigmaScale(fitness)
+	case RankScaling:
+		rankScale(fitness)
+	}
+
+	ret := make([]Genome, len(genomes))
+	total := 0.
+	for i, g := range genomes {
+		ret[i] = &scaledGenome{Genome: g, fitness: fitness[i]}
+		total += fitness[i]
+	}
+	return ret, total
+}
+
+func linearScale(fitness []float64) {
+	if len(fitness) == 0 {
+		return
+	}
+	min, max, sum := math.Inf(1), math.Inf(-1), 0.
+	for _, f := range fitness {
+		min = math.Min(min, f)
+		max = math.Max(max, f)
+		sum += f
+	}
+	avg := sum/float64(len(fitness)) - min
+	max -= min
+
+	// Scale so that the fittest genome scores twice the average, unless
+	// that would leave the least fit genome with a negative score
+	a, b := 1., 0.
+	if max > 2*avg {
+		a = avg / (max - avg)
+		b = avg * (max - 2*avg) / (max - avg)
+	}
+	for i := range fitness {
+		fitness[i] = a*(fitness[i]-min) + b
+	}
+}
+
+func sigmaScale(fitness []float64) {
+	if len(fitness) == 0 {
+		return
+	}
+	sum, sumOfSquares := 0., 0.
+	for _, f := range fitness {
+		sum += f
+	}
+	mean := sum / float64(len(fitness))
+	for _, f := range fitness {
+		sumOfSquares += (f - mean) * (f - mean)
+	}
+	sigma := math.Sqrt(sumOfSquares / float64(len(fitness)))
+	for i, f := range fitness {
+		if sigma == 0 {
+			fitness[i] = 1
+		} else {
+			fitness[i] = math.Max(0, 1+(f-mean)/(2*sigma))
+		}
+	}
+}
+
+func rankScale(fitness []float64) {
+	indices := make([]int, len(fitness))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return fitness[indices[i]] < fitness[indices[j]]
+	})
+	for rank, i := range indices {
+		fitness[i] = float64(rank + 1)
+	}
+}
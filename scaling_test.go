@@ -0,0 +1,110 @@
+package goga_test
+
+import (
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type ScalingSuite struct {
+}
+
+var _ = Suite(&ScalingSuite{})
+
+// MySelectorRecorder records the fitness of the genomes it is passed before selecting at random
+type MySelectorRecorder struct {
+	fitness      [][]float64
+	totalFitness []float64
+}
+
+func (ms *MySelectorRecorder) Go(genomes []goga.Genome, totalFitness float64) goga.Genome {
+	fitness := make([]float64, len(genomes))
+	for i, g := range genomes {
+		fitness[i] = g.GetFitness()
+	}
+	ms.fitness = append(ms.fitness, fitness)
+	ms.totalFitness = append(ms.totalFitness, totalFitness)
+	return goga.RandomSelect(genomes, totalFitness)
+}
+
+// MySimulatorNegativeBitCount scores each genome with minus the number of set bits
+type MySimulatorNegativeBitCount struct {
+	MySimulatorBitCount
+}
+
+func (ms *MySimulatorNegativeBitCount) Simulate(g goga.Genome) {
+	ms.MySimulatorBitCount.Simulate(g)
+	g.SetFitness(-g.GetFitness())
+}
+
+func helperGenerateScaledGeneticAlgorithm(selector goga.Selector, opt ...goga.Option) goga.GeneticAlgorithm {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	genAlgo.Simulator = &MySimulatorNegativeBitCount{}
+	genAlgo.Selector = selector
+	genAlgo.Init(append([]goga.Option{goga.PopulationSize(20), goga.ParallelSimulations(kNumThreads)}, opt...)...)
+	return genAlgo
+}
+
+func (s *ScalingSuite) TestShouldMinimise(t *C) {
+	selector := goga.NewSelector([]goga.SelectorFunctionProbability{{P: 1, F: goga.Roulette}})
+	genAlgo := helperGenerateScaledGeneticAlgorithm(selector, goga.ObjectiveDirection(goga.Minimise), goga.MaxGenerations(20))
+	genAlgo.Simulator = &MySimulatorBitCount{}
+
+	result := genAlgo.Simulate()
+	simulated := goga.NewGenome(result.Elite.GetBits().CreateCopy())
+	genAlgo.Simulator.Simulate(simulated)
+	t.Assert(result.Elite.GetFitness(), Equals, simulated.GetFitness())
+	for _, g := range genAlgo.GetPopulation() {
+		t.Assert(result.Elite.GetFitness() <= g.GetFitness(), IsTrue)
+	}
+	for i := 1; i < len(result.EliteFitnessHistory); i++ {
+		t.Assert(result.EliteFitnessHistory[i] <= result.EliteFitnessHistory[i-1], IsTrue)
+	}
+	t.Assert(result.EliteFitnessHistory[len(result.EliteFitnessHistory)-1] < result.EliteFitnessHistory[0], IsTrue)
+}
+
+func (s *ScalingSuite) TestShouldMaximiseNegativeFitness(t *C) {
+	selector := goga.NewSelector([]goga.SelectorFunctionProbability{{P: 1, F: goga.Roulette}})
+	genAlgo := helperGenerateScaledGeneticAlgorithm(selector, goga.MaxGenerations(20))
+
+	result := genAlgo.Simulate()
+	t.Assert(result.EliteFitnessHistory[len(result.EliteFitnessHistory)-1] > result.EliteFitnessHistory[0], IsTrue)
+}
+
+func (s *ScalingSuite) TestShouldPassScaledFitnessToSelector(t *C) {
+	for _, scaling := range []goga.Scaling{goga.LinearScaling, goga.SigmaScaling, goga.RankScaling} {
+		for _, direction := range []goga.Direction{goga.Maximise, goga.Minimise} {
+			selector := &MySelectorRecorder{}
+			genAlgo := helperGenerateScaledGeneticAlgorithm(selector, goga.FitnessScaling(scaling), goga.ObjectiveDirection(direction), goga.MaxGenerations(2))
+			genAlgo.Simulate()
+			t.Assert(selector.fitness, Not(HasLen), 0)
+
+			population := genAlgo.GetPopulation()
+			scaled := selector.fitness[0]
+			total := 0.
+			for _, f := range scaled {
+				t.Assert(f >= 0, IsTrue)
+				total += f
+			}
+			t.Assert(selector.totalFitness[0]-total < 1e-9, IsTrue)
+			t.Assert(scaled, HasLen, len(population))
+		}
+	}
+}
+
+func (s *ScalingSuite) TestShouldRankFitness(t *C) {
+	selector := &MySelectorRecorder{}
+	genAlgo := helperGenerateScaledGeneticAlgorithm(selector, goga.FitnessScaling(goga.RankScaling), goga.MaxGenerations(1))
+	genomes := genAlgo.Ask(20)
+	for i, g := range genomes {
+		g.SetFitness(float64(-i * i))
+	}
+	t.Assert(genAlgo.Tell(genomes), IsNil)
+	genAlgo.Ask(1)
+
+	expected := make([]float64, 20)
+	for i := range expected {
+		expected[i] = float64(20 - i)
+	}
+	t.Assert(selector.fitness[0], DeepEquals, expected)
+	t.Assert(selector.totalFitness[0], Equals, float64(20*21/2))
+}
@@ -0,0 +1,464 @@
+// Package schema maps the fields of a Go struct onto the bits of a genome, driven by
+// struct tags, so that a simulator can work on a populated struct rather than on bits
+//
+// Only fields with a goga tag are part of the schema, in the order they are declared.
+// The tag is a comma separated list of options:
+//
+//	type Config struct {
+//		Enabled bool     `goga:""`
+//		Size    int      `goga:"bits=9,min=0,max=511,gray"`
+//		Rate    float64  `goga:"float,min=-10,max=10,precision=0.001"`
+//		Points  [4]Point `goga:""`
+//	}
+//
+// * bool fields take a single bit and no options
+// * integer fields hold any value of 'bits' bits, by default the size of their type, or with
+// 'min' and 'max' any value in [min, max] in as many bits as that takes, bit patterns beyond
+// the range wrapping around
+// * float fields need 'min' and 'max' and either 'bits' or 'precision', the smallest step
+// between two values, their values are evenly spaced over the range, 'float' may be given to
+// make the intent clear
+// * 'gray' Gray-codes the bits of an unsigned or ranged integer or of a float, so that
+// neighbouring values are a single bit apart
+// * struct fields are mapped field by field and arrays element by element, the options of an
+// array applying to each of its elements
+//
+// The bits are decoded with goga.BitsetParse
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tomcraven/goga"
+)
+
+var (
+	// ErrInvalidTag is returned by New when a goga tag can't be applied to its field
+	ErrInvalidTag = errors.New("invalid goga tag")
+
+	// ErrTypeMismatch is returned when a value passed to a Schema is not of the
+	// struct type the schema was created from
+	ErrTypeMismatch = errors.New("value does not match the schema type")
+)
+
+const tagName = "goga"
+
+// Schema - the mapping of the tagged fields of a struct type onto a bitset
+type Schema struct {
+	typ    reflect.Type
+	fields []field
+	parse  goga.BitsetParse
+	size   int
+}
+
+// field - a leaf field of the struct, mapped to a single field of the bitset
+type field struct {
+	name  string
+	value func(reflect.Value) reflect.Value
+	kind  reflect.Kind
+	bits  int
+	gray  bool
+
+	// Integers with a range, 'min' and the width of the range, 'max - min', in two's complement
+	ranged bool
+	min    uint64
+	span   uint64
+
+	// Floats
+	fmin, fmax float64
+}
+
+// New returns the schema of the struct type of 'v', a struct or a pointer to one
+func New(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrTypeMismatch
+	}
+
+	s := &Schema{typ: t, parse: goga.CreateBitsetParse()}
+	if err := s.addStruct(t, "", func(v reflect.Value) reflect.Value { return v }); err != nil {
+		return nil, err
+	}
+
+	descriptors := make([]goga.Field, len(s.fields))
+	for i, f := range s.fields {
+		descriptors[i] = f.descriptor()
+		s.size += f.bits
+	}
+	if err := s.parse.SetFields(descriptors); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) addStruct(t reflect.Type, prefix string, value func(reflect.Value) reflect.Value) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(tagName)
+		if !ok || tag == "-" {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return fmt.Errorf("%s%s: %w, the field is unexported", prefix, sf.Name, ErrInvalidTag)
+		}
+		index := i
+		fieldValue := func(v reflect.Value) reflect.Value { return value(v).Field(index) }
+		if err := s.add(sf.Type, tag, prefix+sf.Name, fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) add(t reflect.Type, tag, name string, value func(reflect.Value) reflect.Value) error {
+	switch t.Kind() {
+	case reflect.Struct:
+		if tag != "" {
+			return fmt.Errorf("%s: %w, struct fields take no options", name, ErrInvalidTag)
+		}
+		return s.addStruct(t, name+".", value)
+	case reflect.Array:
+		for i := 0; i < t.Len(); i++ {
+			index := i
+			elemValue := func(v reflect.Value) reflect.Value { return value(v).Index(index) }
+			if err := s.add(t.Elem(), tag, fmt.Sprintf("%s[%d]", name, i), elemValue); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f, err := newField(t, tag)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	f.name = name
+	f.value = value
+	s.fields = append(s.fields, f)
+	return nil
+}
+
+func newField(t reflect.Type, tag string) (field, error) {
+	f := field{kind: t.Kind()}
+	options := map[string]string{}
+	if tag != "" {
+		for _, option := range strings.Split(tag, ",") {
+			key, value := option, ""
+			if i := strings.Index(option, "="); i >= 0 {
+				key, value = option[:i], option[i+1:]
+			}
+			options[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	has := func(key string) bool {
+		_, ok := options[key]
+		return ok
+	}
+	for key := range options {
+		switch key {
+		case "bits", "min", "max", "gray", "float", "precision":
+		default:
+			return f, fmt.Errorf("%w, unknown option '%s'", ErrInvalidTag, key)
+		}
+	}
+	if has("bits") {
+		b, err := strconv.Atoi(options["bits"])
+		if err != nil || b < 1 || b > 64 {
+			return f, fmt.Errorf("%w, bits must be between 1 and 64", ErrInvalidTag)
+		}
+		f.bits = b
+	}
+	f.gray = has("gray")
+
+	switch {
+	case f.kind == reflect.Bool:
+		if len(options) != 0 {
+			return f, fmt.Errorf("%w, bool fields take no options", ErrInvalidTag)
+		}
+		f.bits = 1
+	case isInt(f.kind) || isUint(f.kind):
+		if has("float") || has("precision") {
+			return f, fmt.Errorf("%w, float options on an integer field", ErrInvalidTag)
+		}
+		if has("min") != has("max") {
+			return f, fmt.Errorf("%w, min and max must be given together", ErrInvalidTag)
+		}
+		if has("min") {
+			if err := f.parseRange(t, options["min"], options["max"]); err != nil {
+				return f, err
+			}
+			if f.bits == 0 {
+				f.bits = bits.Len64(f.span)
+			}
+			if f.bits < bits.Len64(f.span) {
+				return f, fmt.Errorf("%w, too few bits for the range", ErrInvalidTag)
+			}
+		} else {
+			if f.gray && isInt(f.kind) {
+				return f, fmt.Errorf("%w, gray needs a range on signed fields", ErrInvalidTag)
+			}
+			if f.bits == 0 {
+				f.bits = t.Bits()
+			}
+			if f.bits > t.Bits() {
+				return f, fmt.Errorf("%w, more bits than the type holds", ErrInvalidTag)
+			}
+		}
+	case f.kind == reflect.Float32 || f.kind == reflect.Float64:
+		if !has("min") || !has("max") {
+			return f, fmt.Errorf("%w, float fields need min and max", ErrInvalidTag)
+		}
+		var err1, err2 error
+		f.fmin, err1 = strconv.ParseFloat(options["min"], 64)
+		f.fmax, err2 = strconv.ParseFloat(options["max"], 64)
+		if err1 != nil || err2 != nil || !(f.fmin < f.fmax) || math.IsInf(f.fmax-f.fmin, 0) {
+			return f, fmt.Errorf("%w, invalid range", ErrInvalidTag)
+		}
+		if f.bits == 0 {
+			if !has("precision") {
+				return f, fmt.Errorf("%w, float fields need bits or precision", ErrInvalidTag)
+			}
+			precision, err := strconv.ParseFloat(options["precision"], 64)
+			if err != nil || !(precision > 0) {
+				return f, fmt.Errorf("%w, invalid precision", ErrInvalidTag)
+			}
+			f.bits = int(math.Ceil(math.Log2((f.fmax-f.fmin)/precision + 1)))
+			if f.bits < 1 {
+				f.bits = 1
+			} else if f.bits > 64 {
+				f.bits = 64
+			}
+		}
+	default:
+		return f, fmt.Errorf("%w, unsupported type %s", ErrInvalidTag, t)
+	}
+	if has("float") && f.kind != reflect.Float32 && f.kind != reflect.Float64 {
+		return f, fmt.Errorf("%w, float on a %s field", ErrInvalidTag, t)
+	}
+	return f, nil
+}
+
+func (f *field) parseRange(t reflect.Type, min, max string) error {
+	if isInt(f.kind) {
+		lo, err1 := strconv.ParseInt(min, 10, 64)
+		hi, err2 := strconv.ParseInt(max, 10, 64)
+		if err1 != nil || err2 != nil || lo > hi || reflect.Zero(t).OverflowInt(lo) || reflect.Zero(t).OverflowInt(hi) {
+			return fmt.Errorf("%w, invalid range", ErrInvalidTag)
+		}
+		f.min, f.span = uint64(lo), uint64(hi)-uint64(lo)
+	} else {
+		lo, err1 := strconv.ParseUint(min, 10, 64)
+		hi, err2 := strconv.ParseUint(max, 10, 64)
+		if err1 != nil || err2 != nil || lo > hi || reflect.Zero(t).OverflowUint(hi) {
+			return fmt.Errorf("%w, invalid range", ErrInvalidTag)
+		}
+		f.min, f.span = lo, hi-lo
+	}
+	f.ranged = true
+	return nil
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uint64
+}
+
+// descriptor returns the BitsetParse field the field is decoded with
+func (f *field) descriptor() goga.Field {
+	switch {
+	case f.kind == reflect.Bool:
+		return goga.Bool()
+	case f.gray:
+		return goga.Gray(f.bits)
+	case f.ranged || isUint(f.kind):
+		return goga.Unsigned(f.bits)
+	case isInt(f.kind):
+		return goga.Signed(f.bits)
+	}
+	return goga.FixedPoint(f.bits, f.fmin, f.fmax)
+}
+
+// maxUnsigned returns the largest value the bits of the field hold
+func (f *field) maxUnsigned() uint64 {
+	if f.bits == 64 {
+		return math.MaxUint64
+	}
+	return (uint64(1) << uint(f.bits)) - 1
+}
+
+// set assigns 'decoded', as decoded by the field's descriptor, to 'v'
+func (f *field) set(v reflect.Value, decoded interface{}) {
+	switch {
+	case f.kind == reflect.Bool:
+		v.SetBool(decoded.(bool))
+	case f.kind == reflect.Float32 || f.kind == reflect.Float64:
+		if f.gray {
+			u := decoded.(uint64)
+			v.SetFloat(math.Min(f.fmax, f.fmin+(f.fmax-f.fmin)*float64(u)/float64(f.maxUnsigned())))
+		} else {
+			v.SetFloat(decoded.(float64))
+		}
+	case f.ranged:
+		u := decoded.(uint64)
+		if f.span != math.MaxUint64 {
+			u %= f.span + 1
+		}
+		if isInt(f.kind) {
+			v.SetInt(int64(f.min + u))
+		} else {
+			v.SetUint(f.min + u)
+		}
+	case isInt(f.kind):
+		v.SetInt(decoded.(int64))
+	default:
+		v.SetUint(decoded.(uint64))
+	}
+}
+
+// get returns the value of 'v' as the field's descriptor encodes it
+func (f *field) get(v reflect.Value) (interface{}, error) {
+	outOfRange := fmt.Errorf("%s: %w", f.name, goga.ErrInvalidValue)
+	switch {
+	case f.kind == reflect.Bool:
+		return v.Bool(), nil
+	case f.kind == reflect.Float32 || f.kind == reflect.Float64:
+		r := v.Float()
+		if !(r >= f.fmin && r <= f.fmax) {
+			return nil, outOfRange
+		}
+		if !f.gray {
+			return r, nil
+		}
+		steps := float64(f.maxUnsigned())
+		u := math.Round((r - f.fmin) / (f.fmax - f.fmin) * steps)
+		if u >= steps {
+			return f.maxUnsigned(), nil
+		}
+		return uint64(u), nil
+	case f.ranged:
+		var raw uint64
+		if isInt(f.kind) {
+			raw = uint64(v.Int())
+		} else {
+			raw = v.Uint()
+		}
+		u := raw - f.min
+		if isInt(f.kind) && v.Int() < int64(f.min) || isUint(f.kind) && raw < f.min || u > f.span {
+			return nil, outOfRange
+		}
+		return u, nil
+	case isInt(f.kind):
+		return v.Int(), nil
+	}
+	return v.Uint(), nil
+}
+
+// Size returns the number of bits of the bitsets of the schema
+func (s *Schema) Size() int {
+	return s.size
+}
+
+// structValue returns the struct 'v' points to, or holds when 'settable' is false
+func (s *Schema) structValue(v interface{}, settable bool) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	} else if settable {
+		return rv, ErrTypeMismatch
+	}
+	if rv.Type() != s.typ {
+		return rv, ErrTypeMismatch
+	}
+	return rv, nil
+}
+
+// Decode sets the tagged fields of the struct 'out' points to from 'b'
+// Fields without a goga tag are left as they are
+func (s *Schema) Decode(b *goga.Bitset, out interface{}) error {
+	rv, err := s.structValue(out, true)
+	if err != nil {
+		return err
+	}
+	values, err := s.parse.Decode(b)
+	if err != nil {
+		return err
+	}
+	for i := range s.fields {
+		f := &s.fields[i]
+		f.set(f.value(rv), values[i])
+	}
+	return nil
+}
+
+// DecodeGenome sets the tagged fields of the struct 'out' points to from the bits of 'g'
+func (s *Schema) DecodeGenome(g goga.Genome, out interface{}) error {
+	return s.Decode(g.GetBits(), out)
+}
+
+// Encode returns the bitset that decodes to the tagged fields of 'v', a struct or a
+// pointer to one, it returns an error wrapping goga.ErrInvalidValue if a field is out of
+// the range of its tag
+// Floats are rounded to the nearest value their bits hold
+func (s *Schema) Encode(v interface{}) (goga.Bitset, error) {
+	rv, err := s.structValue(v, false)
+	if err != nil {
+		return goga.Bitset{}, err
+	}
+	values := make([]interface{}, len(s.fields))
+	for i := range s.fields {
+		f := &s.fields[i]
+		if values[i], err = f.get(f.value(rv)); err != nil {
+			return goga.Bitset{}, err
+		}
+	}
+	return s.parse.Encode(values)
+}
+
+// BitsetCreate returns a goga.BitsetCreate that creates bitsets of random values
+// Ranged integers are drawn evenly from their range and every other field from its bits
+func (s *Schema) BitsetCreate() goga.BitsetCreate {
+	return &bitsetCreate{schema: s}
+}
+
+type bitsetCreate struct {
+	schema *Schema
+	rand   *rand.Rand
+}
+
+// Go returns a bitset of random values
+func (bc *bitsetCreate) Go() goga.Bitset {
+	b := goga.Bitset{}
+	b.Create(bc.schema.size)
+	rng := goga.RandOrGlobal(bc.rand)
+	index := 0
+	for _, f := range bc.schema.fields {
+		u := rng.Uint64()
+		if f.ranged && f.span < math.MaxInt64 {
+			u = uint64(rng.Int63n(int64(f.span) + 1))
+			if f.gray {
+				u ^= u >> 1
+			}
+		}
+		b.SetUint64(index, f.bits, u)
+		index += f.bits
+	}
+	return b
+}
+
+// SetRand sets the random number generator the values are drawn from
+func (bc *bitsetCreate) SetRand(rng *rand.Rand) {
+	bc.rand = rng
+}
@@ -0,0 +1,214 @@
+package schema_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/tomcraven/goga"
+	"github.com/tomcraven/goga/schema"
+	. "gopkg.in/check.v1"
+)
+
+type SchemaSuite struct {
+}
+
+var _ = Suite(&SchemaSuite{})
+
+type point struct {
+	X int `goga:"bits=9,min=0,max=511,gray"`
+	Y int `goga:"min=-10,max=10"`
+}
+
+type config struct {
+	Enabled  bool     `goga:""`
+	Rate     float64  `goga:"float,min=-10,max=10,precision=0.001"`
+	Scale    float32  `goga:"min=0,max=1,bits=8,gray"`
+	Count    uint8    `goga:""`
+	Offset   int16    `goga:"bits=5"`
+	Points   [2]point `goga:""`
+	Ignored  string
+	Excluded int `goga:"-"`
+}
+
+func (s *SchemaSuite) TestShouldComputeSize(t *C) {
+	sc, err := schema.New(config{})
+	t.Assert(err, IsNil)
+	// 1 + 15 + 8 + 8 + 5 + 2 * (9 + 5)
+	t.Assert(sc.Size(), Equals, 65)
+
+	sc, err = schema.New(&point{})
+	t.Assert(err, IsNil)
+	t.Assert(sc.Size(), Equals, 14)
+}
+
+func (s *SchemaSuite) TestShouldEncodeAndDecodeStruct(t *C) {
+	sc, err := schema.New(config{})
+	t.Assert(err, IsNil)
+
+	in := config{
+		Enabled: true,
+		Rate:    -3.25,
+		Scale:   1,
+		Count:   200,
+		Offset:  -16,
+		Points:  [2]point{{X: 511, Y: -10}, {X: 3, Y: 10}},
+		Ignored: "kept",
+	}
+	b, err := sc.Encode(in)
+	t.Assert(err, IsNil)
+	t.Assert(b.GetSize(), Equals, sc.Size())
+
+	out := config{Ignored: "untouched", Excluded: 7}
+	t.Assert(sc.DecodeGenome(goga.NewGenome(b), &out), IsNil)
+	t.Assert(math.Abs(out.Rate-in.Rate) <= 0.001, Equals, true)
+	out.Rate = in.Rate
+	t.Assert(out, DeepEquals, config{
+		Enabled:  true,
+		Rate:     -3.25,
+		Scale:    1,
+		Count:    200,
+		Offset:   -16,
+		Points:   in.Points,
+		Ignored:  "untouched",
+		Excluded: 7,
+	})
+}
+
+func (s *SchemaSuite) TestShouldWrapRangedIntegers(t *C) {
+	type small struct {
+		V int `goga:"min=5,max=7"`
+	}
+	sc, err := schema.New(small{})
+	t.Assert(err, IsNil)
+	t.Assert(sc.Size(), Equals, 2)
+
+	b := goga.Bitset{}
+	b.Create(2)
+	b.SetAll(1)
+	out := small{}
+	t.Assert(sc.Decode(&b, &out), IsNil)
+	t.Assert(out.V, Equals, 5)
+
+	_, err = sc.Encode(small{V: 8})
+	t.Assert(errors.Is(err, goga.ErrInvalidValue), Equals, true)
+	t.Assert(err, ErrorMatches, "V: .*")
+	_, err = sc.Encode(small{V: 4})
+	t.Assert(errors.Is(err, goga.ErrInvalidValue), Equals, true)
+}
+
+func (s *SchemaSuite) TestShouldUseGrayCode(t *C) {
+	type gray struct {
+		V uint `goga:"bits=4,gray"`
+	}
+	sc, err := schema.New(gray{})
+	t.Assert(err, IsNil)
+	for v := uint(0); v < 15; v++ {
+		b1, err := sc.Encode(gray{V: v})
+		t.Assert(err, IsNil)
+		b2, err := sc.Encode(gray{V: v + 1})
+		t.Assert(err, IsNil)
+		t.Assert(b1.HammingDistance(&b2), Equals, 1)
+	}
+}
+
+func (s *SchemaSuite) TestShouldRejectInvalidTags(t *C) {
+	for _, v := range []interface{}{
+		struct {
+			V int `goga:"colour=red"`
+		}{},
+		struct {
+			V bool `goga:"bits=2"`
+		}{},
+		struct {
+			V int `goga:"min=1"`
+		}{},
+		struct {
+			V int8 `goga:"min=0,max=300"`
+		}{},
+		struct {
+			V int `goga:"min=0,max=300,bits=4"`
+		}{},
+		struct {
+			V int8 `goga:"bits=9"`
+		}{},
+		struct {
+			V int `goga:"gray"`
+		}{},
+		struct {
+			V int `goga:"float"`
+		}{},
+		struct {
+			V float64 `goga:"min=0,max=1"`
+		}{},
+		struct {
+			V float64 `goga:"min=1,max=0,bits=4"`
+		}{},
+		struct {
+			V float64 `goga:"min=0,max=1,precision=0"`
+		}{},
+		struct {
+			V string `goga:""`
+		}{},
+		struct {
+			V []int `goga:""`
+		}{},
+		struct {
+			V point `goga:"bits=3"`
+		}{},
+		struct {
+			v int `goga:""`
+		}{},
+	} {
+		_, err := schema.New(v)
+		t.Assert(errors.Is(err, schema.ErrInvalidTag), Equals, true, Commentf("%#v", v))
+		t.Assert(err, ErrorMatches, "V: .*|v: .*")
+	}
+}
+
+func (s *SchemaSuite) TestShouldRejectMismatchedTypes(t *C) {
+	_, err := schema.New(3)
+	t.Assert(err, Equals, schema.ErrTypeMismatch)
+
+	sc, err := schema.New(point{})
+	t.Assert(err, IsNil)
+	_, err = sc.Encode(config{})
+	t.Assert(err, Equals, schema.ErrTypeMismatch)
+
+	b, err := sc.Encode(&point{})
+	t.Assert(err, IsNil)
+	t.Assert(sc.Decode(&b, point{}), Equals, schema.ErrTypeMismatch)
+	t.Assert(sc.Decode(&b, &config{}), Equals, schema.ErrTypeMismatch)
+
+	short := goga.Bitset{}
+	short.Create(3)
+	t.Assert(sc.Decode(&short, &point{}), Equals, goga.ErrFormatMismatch)
+}
+
+func (s *SchemaSuite) TestShouldCreateRandomBitsetsInRange(t *C) {
+	type ranged struct {
+		V int     `goga:"min=0,max=4"`
+		G int     `goga:"min=0,max=4,gray"`
+		F float64 `goga:"min=-1,max=1,bits=16"`
+	}
+	sc, err := schema.New(ranged{})
+	t.Assert(err, IsNil)
+
+	create := sc.BitsetCreate()
+	create.(goga.RandSetter).SetRand(rand.New(goga.NewSource(1)))
+	seen := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		b := create.Go()
+		t.Assert(b.GetSize(), Equals, sc.Size())
+
+		out := ranged{}
+		t.Assert(sc.Decode(&b, &out), IsNil)
+		// Drawn from the range rather than wrapped into it
+		encoded, err := sc.Encode(out)
+		t.Assert(err, IsNil)
+		encodedInts, ints := encoded.Slice(0, 6), b.Slice(0, 6)
+		t.Assert(encodedInts.Equal(&ints), Equals, true)
+		seen[out.V] = true
+	}
+	t.Assert(len(seen), Equals, 5)
+}
@@ -1,7 +1,10 @@
 package goga
 
 import (
+	"math"
 	"math/rand"
+	"sort"
+	"sync"
 )
 
 // Selector - a selector interface used to pick 2 genomes to mate
@@ -9,6 +12,21 @@ type Selector interface {
 	Go([]Genome, float64) Genome
 }
 
+// BatchSelector - an optional extension of the Selector interface for selectors that pick
+// many genomes at once, such as stochastic universal sampling
+// When a selector implements it the genetic algorithm asks for the parents of the rest of
+// a generation in one go rather than calling Go for every parent
+type BatchSelector interface {
+	GoBatch(genomes []Genome, totalFitness float64, n int) []Genome
+}
+
+// GenerationSetter - an optional interface for selectors that change over a run
+// The genetic algorithm calls SetGeneration with the number of each generation
+// before any of its genomes are selected
+type GenerationSetter interface {
+	SetGeneration(generation int)
+}
+
 // NullSelector - a null implementation of the Selector interface
 type NullSelector struct {
 }
@@ -23,13 +41,20 @@ func (ns *NullSelector) Go(genomes []Genome, totalFitness float64) Genome {
 // where selector function 'F' is called with probability 'P'
 // where 'P' is a value between 0 and 1
 // 0 = never called, 1 = called every time we need a new genome to mate
+// 'R' can be given in place of 'F', it is also passed the selector's random number generator
+// so that selection can be reproduced, see RouletteRand
+// 'OnGeneration', if given, is called with the number of each generation before any of its
+// genomes are selected, see Boltzmann
 type SelectorFunctionProbability struct {
-	P float32
-	F func([]Genome, float64) Genome
+	P            float32
+	F            func([]Genome, float64) Genome
+	R            func(*rand.Rand, []Genome, float64) Genome
+	OnGeneration func(generation int)
 }
 
 type selector struct {
 	selectorConfig []SelectorFunctionProbability
+	rand           *rand.Rand
 }
 
 // NewSelector returns an instance of an ISelector with several SelectorFunctionProbabiities
@@ -41,28 +66,60 @@ func NewSelector(selectorConfig []SelectorFunctionProbability) Selector {
 
 // Go - cycles through the selector function probabilities until one returns a genome
 func (s *selector) Go(genomeArray []Genome, totalFitness float64) Genome {
+	rng := RandOrGlobal(s.rand)
 	for {
 		for _, config := range s.selectorConfig {
-			if rand.Float32() < config.P {
+			if rng.Float32() < config.P {
+				if config.R != nil {
+					return config.R(rng, genomeArray, totalFitness)
+				}
 				return config.F(genomeArray, totalFitness)
 			}
 		}
 	}
 }
 
+// SetRand - sets the random number generator used by the selector and passed to each 'R'
+func (s *selector) SetRand(rng *rand.Rand) {
+	s.rand = rng
+}
+
+// SetGeneration - passes the number of the generation on to each 'OnGeneration'
+func (s *selector) SetGeneration(generation int) {
+	for _, config := range s.selectorConfig {
+		if config.OnGeneration != nil {
+			config.OnGeneration(generation)
+		}
+	}
+}
+
 // Roulette is a selection function that selects a genome where genomes that have a higher fitness are more likely to be picked
+// When any fitness is negative every fitness is shifted up so that the least fit genome has a fitness of 0
+// When the shifted fitness is too large to be summed, such as when a genome scores -math.MaxFloat64,
+// genomes are picked with a probability proportional to their rank instead
 func Roulette(genomeArray []Genome, totalFitness float64) Genome {
+	return RouletteRand(globalRand, genomeArray, totalFitness)
+}
+
+// RouletteRand is Roulette drawing from the random number generator 'rng'
+func RouletteRand(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
 	if len(genomeArray) == 0 {
 		panic("genome array contains no elements")
 	}
+	offset := fitnessOffset(genomeArray)
+	totalFitness -= offset * float64(len(genomeArray))
+	if !finite(totalFitness) {
+		ranked, totalRank := rankWeighted(genomeArray)
+		return RouletteRand(rng, ranked, totalRank).(*scaledGenome).Genome
+	}
 	if totalFitness == 0 {
-		randomIndex := rand.Intn(len(genomeArray))
+		randomIndex := rng.Intn(len(genomeArray))
 		return genomeArray[randomIndex]
 	}
 
-	randomFitness := rand.Float64() * totalFitness
+	randomFitness := rng.Float64() * totalFitness
 	for i := range genomeArray {
-		randomFitness -= genomeArray[i].GetFitness()
+		randomFitness -= genomeArray[i].GetFitness() - offset
 		if randomFitness <= 0 {
 			return genomeArray[i]
 		}
@@ -71,11 +128,440 @@ func Roulette(genomeArray []Genome, totalFitness float64) Genome {
 	panic("total fitness is too large")
 }
 
+// finite reports whether 'f' is neither infinite nor NaN
+func finite(f float64) bool {
+	return !math.IsInf(f, 0) && !math.IsNaN(f)
+}
+
+// rankWeighted returns 'genomeArray' with the fitness of each genome replaced by its rank, from 1
+// for the least fit, along with the total of the ranks
+func rankWeighted(genomeArray []Genome) ([]Genome, float64) {
+	ranked := rankGenomes(genomeArray)
+	total := 0.
+	for i, g := range ranked {
+		ranked[i] = &scaledGenome{Genome: g, fitness: float64(i + 1)}
+		total += float64(i + 1)
+	}
+	return ranked, total
+}
+
+// fitnessOffset returns the lowest fitness of 'genomeArray' if it is negative, or 0
+func fitnessOffset(genomeArray []Genome) float64 {
+	offset := 0.
+	for i := range genomeArray {
+		if fitness := genomeArray[i].GetFitness(); fitness < offset {
+			offset = fitness
+		}
+	}
+	return offset
+}
+
 // RandomSelect is a selection function that selects a genome randomly
 func RandomSelect(genomeArray []Genome, totalFitness float64) Genome {
+	return RandomSelectRand(globalRand, genomeArray, totalFitness)
+}
+
+// RandomSelectRand is RandomSelect drawing from the random number generator 'rng'
+func RandomSelectRand(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
 	if len(genomeArray) == 0 {
 		panic("genome array contains no elements")
 	}
-	randomIndex := rand.Intn(len(genomeArray))
+	randomIndex := rng.Intn(len(genomeArray))
 	return genomeArray[randomIndex]
 }
+
+// Tournament returns a selection function that holds a tournament between 'k' genomes picked at
+// random, genomes may be picked more than once, see TournamentRand
+func Tournament(k int, p float64) func([]Genome, float64) Genome {
+	return withGlobalRand(TournamentRand(k, p))
+}
+
+// TournamentRand returns a selection function that holds a tournament between 'k' genomes picked at
+// random from the random number generator it is passed, genomes may be picked more than once
+// The fittest contestant wins with probability 'p', failing that the second fittest wins with
+// probability 'p', and so on, with the least fit contestant winning if no other does
+// A 'p' of 1 gives a deterministic tournament, the fittest contestant always wins
+// Only the order of fitness matters, so tournaments work with any real valued fitness
+func TournamentRand(k int, p float64) func(*rand.Rand, []Genome, float64) Genome {
+	return func(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
+		return tournament(rng, genomeArray, k, p, true)
+	}
+}
+
+// TournamentWithoutReplacement returns a selection function like Tournament where no genome
+// is picked more than once in the same tournament, see TournamentWithoutReplacementRand
+func TournamentWithoutReplacement(k int, p float64) func([]Genome, float64) Genome {
+	return withGlobalRand(TournamentWithoutReplacementRand(k, p))
+}
+
+// TournamentWithoutReplacementRand returns a selection function like TournamentRand where no
+// genome is picked more than once in the same tournament
+// When 'k' is larger than the number of genomes every genome takes part
+func TournamentWithoutReplacementRand(k int, p float64) func(*rand.Rand, []Genome, float64) Genome {
+	return func(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
+		return tournament(rng, genomeArray, k, p, false)
+	}
+}
+
+// withGlobalRand adapts a selection function drawing from a random number generator into one
+// drawing from the top level math/rand functions
+func withGlobalRand(f func(*rand.Rand, []Genome, float64) Genome) func([]Genome, float64) Genome {
+	return func(genomeArray []Genome, totalFitness float64) Genome {
+		return f(globalRand, genomeArray, totalFitness)
+	}
+}
+
+func tournament(rng *rand.Rand, genomeArray []Genome, k int, p float64, replacement bool) Genome {
+	if len(genomeArray) == 0 {
+		panic("genome array contains no elements")
+	}
+	if k < 1 {
+		k = 1
+	}
+	if !replacement && k > len(genomeArray) {
+		k = len(genomeArray)
+	}
+
+	picked := make([]int, 0, k)
+	for len(picked) < k {
+		index := rng.Intn(len(genomeArray))
+		if !replacement && containsIndex(picked, index) {
+			continue
+		}
+		picked = append(picked, index)
+	}
+
+	contestants := make([]Genome, k)
+	for i, index := range picked {
+		contestants[i] = genomeArray[index]
+	}
+	sort.SliceStable(contestants, func(i, j int) bool {
+		return contestants[i].GetFitness() > contestants[j].GetFitness()
+	})
+	for i := 0; i < k-1; i++ {
+		if p >= 1 || rng.Float64() < p {
+			return contestants[i]
+		}
+	}
+	return contestants[k-1]
+}
+
+func containsIndex(indices []int, index int) bool {
+	for _, i := range indices {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// LinearRank returns a selection function that ranks genomes by fitness and picks them with a
+// probability that grows linearly with their rank, see LinearRankRand
+func LinearRank(pressure float64) func([]Genome, float64) Genome {
+	return withGlobalRand(LinearRankRand(pressure))
+}
+
+// LinearRankRand returns a selection function that ranks genomes by fitness and picks them with a
+// probability that grows linearly with their rank, drawing from the random number generator it is passed
+// 'pressure', between 1 and 2, is the expected number of times the fittest genome is picked for every
+// time an average genome is, the least fit genome is expected to be picked '2 - pressure' times
+// The genomes are ranked once for as long as they, and their fitness, stay the same, so
+// picking the parents of a generation sorts its population once
+func LinearRankRand(pressure float64) func(*rand.Rand, []Genome, float64) Genome {
+	cache := &rankCache{}
+	return func(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
+		return cache.pick(rng, genomeArray, 0, func(ranked []Genome, rank int) float64 {
+			n := float64(len(ranked))
+			if n == 1 {
+				return 1
+			}
+			return 2 - pressure + 2*(pressure-1)*float64(rank)/(n-1)
+		})
+	}
+}
+
+// ExponentialRank returns a selection function that ranks genomes by fitness and picks them with
+// a probability that grows exponentially with their rank, see ExponentialRankRand
+func ExponentialRank(base float64) func([]Genome, float64) Genome {
+	return withGlobalRand(ExponentialRankRand(base))
+}
+
+// ExponentialRankRand returns a selection function that ranks genomes by fitness and picks them
+// with a probability that grows exponentially with their rank, drawing from the random number
+// generator it is passed
+// Each genome is 'base', between 0 and 1, times as likely to be picked as the next fitter genome
+// The genomes are ranked once for as long as they stay the same, see LinearRankRand
+func ExponentialRankRand(base float64) func(*rand.Rand, []Genome, float64) Genome {
+	cache := &rankCache{}
+	return func(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
+		return cache.pick(rng, genomeArray, 0, func(ranked []Genome, rank int) float64 {
+			return math.Pow(base, float64(len(ranked)-1-rank))
+		})
+	}
+}
+
+// Truncation returns a selection function that picks at random from the fittest genomes,
+// see TruncationRand
+func Truncation(ratio float64) func([]Genome, float64) Genome {
+	return withGlobalRand(TruncationRand(ratio))
+}
+
+// TruncationRand returns a selection function that picks at random, from the random number generator
+// it is passed, from the fittest 'ratio' of the genomes, at least the fittest genome is always picked from
+// The genomes are ranked once for as long as they stay the same, see LinearRankRand
+func TruncationRand(ratio float64) func(*rand.Rand, []Genome, float64) Genome {
+	cache := &rankCache{}
+	return func(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
+		ranked := cache.rank(genomeArray)
+		n := int(ratio * float64(len(ranked)))
+		if n < 1 {
+			n = 1
+		}
+		if n > len(ranked) {
+			n = len(ranked)
+		}
+		return ranked[len(ranked)-1-rng.Intn(n)]
+	}
+}
+
+// SUS is a selection function that picks a single genome by stochastic universal sampling, which
+// on its own is the same as Roulette, see SUSBatch
+func SUS(genomeArray []Genome, totalFitness float64) Genome {
+	return SUSRand(globalRand, genomeArray, totalFitness)
+}
+
+// SUSRand is SUS drawing from the random number generator 'rng'
+func SUSRand(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
+	return SUSBatchRand(rng, genomeArray, totalFitness, 1)[0]
+}
+
+// SUSBatch picks 'n' genomes by stochastic universal sampling, see SUSBatchRand
+func SUSBatch(genomeArray []Genome, totalFitness float64, n int) []Genome {
+	return SUSBatchRand(globalRand, genomeArray, totalFitness, n)
+}
+
+// SUSBatchRand picks 'n' genomes by stochastic universal sampling, drawing from 'rng'
+// Like Roulette genomes with a higher fitness are more likely to be picked, but the 'n' genomes are
+// picked in a single pass by 'n' evenly spaced pointers, so each genome is picked within one of
+// the number of times it is expected to be. The picked genomes are returned in a random order
+// Negative fitness is shifted, and fitness too large to be summed replaced by rank, as it is by Roulette
+func SUSBatchRand(rng *rand.Rand, genomeArray []Genome, totalFitness float64, n int) []Genome {
+	if len(genomeArray) == 0 {
+		panic("genome array contains no elements")
+	}
+	offset := fitnessOffset(genomeArray)
+	totalFitness -= offset * float64(len(genomeArray))
+	if !finite(totalFitness) {
+		ranked, totalRank := rankWeighted(genomeArray)
+		ret := SUSBatchRand(rng, ranked, totalRank, n)
+		for i := range ret {
+			ret[i] = ret[i].(*scaledGenome).Genome
+		}
+		return ret
+	}
+
+	ret := make([]Genome, 0, n)
+	if totalFitness == 0 {
+		for len(ret) < n {
+			ret = append(ret, genomeArray[rng.Intn(len(genomeArray))])
+		}
+		return ret
+	}
+
+	step := totalFitness / float64(n)
+	pointer := rng.Float64() * step
+	cumulative := 0.
+	for i := 0; i < len(genomeArray) && len(ret) < n; i++ {
+		cumulative += genomeArray[i].GetFitness() - offset
+		for len(ret) < n && pointer < cumulative {
+			ret = append(ret, genomeArray[i])
+			pointer += step
+		}
+	}
+	if len(ret) < n {
+		panic("total fitness is too large")
+	}
+	rng.Shuffle(len(ret), func(i, j int) {
+		ret[i], ret[j] = ret[j], ret[i]
+	})
+	return ret
+}
+
+type susSelector struct {
+	rand *rand.Rand
+}
+
+// NewSUSSelector returns a Selector that picks genomes by stochastic universal sampling
+// It implements BatchSelector so the genetic algorithm picks all of the parents it still needs
+// for a generation in a single pass over the population
+func NewSUSSelector() Selector {
+	return &susSelector{}
+}
+
+// Go - picks a single genome, see SUS
+func (s *susSelector) Go(genomeArray []Genome, totalFitness float64) Genome {
+	return SUSRand(RandOrGlobal(s.rand), genomeArray, totalFitness)
+}
+
+// GoBatch - picks 'n' genomes, see SUSBatch
+func (s *susSelector) GoBatch(genomeArray []Genome, totalFitness float64, n int) []Genome {
+	return SUSBatchRand(RandOrGlobal(s.rand), genomeArray, totalFitness, n)
+}
+
+// SetRand - sets the random number generator used by the selector
+func (s *susSelector) SetRand(rng *rand.Rand) {
+	s.rand = rng
+}
+
+// Boltzmann - Boltzmann selection, genomes are picked with a probability proportional to
+// exp(fitness / temperature), where the temperature of each generation is given by 'Schedule'
+// A high temperature picks genomes almost uniformly, as it falls the fitter genomes are picked
+// more and more often, a temperature of 0 or less always picks the fittest genome
+// Boltzmann is a Selector, or can be used through NewSelector by passing its Select or SelectRand
+// method along with its SetGeneration method as the 'OnGeneration' of the same
+// SelectorFunctionProbability
+// The genomes are ranked once for as long as they stay the same, see LinearRankRand
+type Boltzmann struct {
+	Schedule   func(generation int) float64
+	generation int
+	rand       *rand.Rand
+	cache      rankCache
+}
+
+// NewBoltzmann returns a Boltzmann selector with the temperature schedule 'schedule'
+func NewBoltzmann(schedule func(generation int) float64) *Boltzmann {
+	return &Boltzmann{Schedule: schedule}
+}
+
+// ExponentialSchedule returns a temperature schedule, for Boltzmann selection, that starts at
+// 'temperature' and is multiplied by 'decay' every generation
+func ExponentialSchedule(temperature, decay float64) func(generation int) float64 {
+	return func(generation int) float64 {
+		return temperature * math.Pow(decay, float64(generation))
+	}
+}
+
+// Go - picks a genome, see Select
+func (b *Boltzmann) Go(genomeArray []Genome, totalFitness float64) Genome {
+	return b.SelectRand(RandOrGlobal(b.rand), genomeArray, totalFitness)
+}
+
+// Select is a selection function that picks a genome at the temperature of the current generation
+func (b *Boltzmann) Select(genomeArray []Genome, totalFitness float64) Genome {
+	return b.SelectRand(globalRand, genomeArray, totalFitness)
+}
+
+// SelectRand is Select drawing from the random number generator 'rng'
+func (b *Boltzmann) SelectRand(rng *rand.Rand, genomeArray []Genome, totalFitness float64) Genome {
+	temperature := b.Schedule(b.generation)
+	if temperature <= 0 {
+		ranked := b.cache.rank(genomeArray)
+		return ranked[len(ranked)-1]
+	}
+	return b.cache.pick(rng, genomeArray, temperature, func(ranked []Genome, rank int) float64 {
+		best := ranked[len(ranked)-1].GetFitness()
+		return math.Exp((ranked[rank].GetFitness() - best) / temperature)
+	})
+}
+
+// SetGeneration - sets the generation whose temperature is used
+func (b *Boltzmann) SetGeneration(generation int) {
+	b.generation = generation
+}
+
+// SetRand - sets the random number generator used when Boltzmann is the Selector
+func (b *Boltzmann) SetRand(rng *rand.Rand) {
+	b.rand = rng
+}
+
+// rankGenomes returns a copy of 'genomeArray' sorted from the least to the most fit
+func rankGenomes(genomeArray []Genome) []Genome {
+	if len(genomeArray) == 0 {
+		panic("genome array contains no elements")
+	}
+	ranked := append([]Genome{}, genomeArray...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].GetFitness() < ranked[j].GetFitness()
+	})
+	return ranked
+}
+
+// rankCache - the ranking of the genomes a selection function last picked from, and the
+// weights it last picked them by, which are reused for as long as the genomes and their
+// fitness stay the same rather than sorting the genomes every time one is picked
+type rankCache struct {
+	mutex     sync.Mutex
+	genomes   []Genome
+	fitness   []float64
+	ranked    []Genome
+	parameter float64
+	weights   []float64
+}
+
+// rank returns 'genomeArray' sorted from the least to the most fit, see rankGenomes
+func (c *rankCache) rank(genomeArray []Genome) []Genome {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.rankLocked(genomeArray)
+}
+
+func (c *rankCache) rankLocked(genomeArray []Genome) []Genome {
+	if c.unchanged(genomeArray) {
+		return c.ranked
+	}
+	c.genomes = append(c.genomes[:0], genomeArray...)
+	c.fitness = c.fitness[:0]
+	for _, g := range genomeArray {
+		c.fitness = append(c.fitness, g.GetFitness())
+	}
+	c.ranked = rankGenomes(genomeArray)
+	c.weights = nil
+	return c.ranked
+}
+
+// unchanged reports whether 'genomeArray' holds the genomes last ranked, in the same order
+// and with the same fitness
+func (c *rankCache) unchanged(genomeArray []Genome) bool {
+	if c.ranked == nil || len(genomeArray) != len(c.genomes) {
+		return false
+	}
+	for i, g := range genomeArray {
+		if g != c.genomes[i] || g.GetFitness() != c.fitness[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pick picks one of 'genomeArray' with a probability proportional to 'weight' of its rank,
+// the weights are worked out again whenever the ranking or 'parameter' they depend on changes
+func (c *rankCache) pick(rng *rand.Rand, genomeArray []Genome, parameter float64,
+	weight func(ranked []Genome, rank int) float64) Genome {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ranked := c.rankLocked(genomeArray)
+	if c.weights == nil || c.parameter != parameter {
+		c.parameter = parameter
+		c.weights = make([]float64, len(ranked))
+		for i := range ranked {
+			c.weights[i] = weight(ranked, i)
+		}
+	}
+	return pickWeighted(rng, ranked, c.weights)
+}
+
+// pickWeighted picks one of 'ranked' with a probability proportional to its weight in 'weights'
+func pickWeighted(rng *rand.Rand, ranked []Genome, weights []float64) Genome {
+	total := 0.
+	for _, w := range weights {
+		total += w
+	}
+	r := rng.Float64() * total
+	for i := range ranked {
+		r -= weights[i]
+		if r < 0 {
+			return ranked[i]
+		}
+	}
+	return ranked[len(ranked)-1]
+}
@@ -2,6 +2,7 @@ package goga_test
 
 import (
 	"math"
+	"math/rand"
 
 	"github.com/tomcraven/goga"
 	. "gopkg.in/check.v1"
@@ -139,3 +140,236 @@ func (s *SelectorSuite) TestShouldConfig_Multiple(t *C) {
 		t.Assert(numCalls2 > fourtyPercent, IsTrue, Commentf("Num calls [%v] fourty percent [%v]", numCalls2, fourtyPercent))
 	}
 }
+
+func (s *SelectorSuite) TestShouldRouletteWithNegativeFitness(t *C) {
+	genomeArray := make([]goga.Genome, 10)
+	totalFitness := 0.
+	for i := range genomeArray {
+		genomeArray[i] = goga.NewGenome(goga.Bitset{})
+		genomeArray[i].SetFitness(float64(i - 20))
+		totalFitness += genomeArray[i].GetFitness()
+	}
+
+	for i := 0; i < 1000; i++ {
+		selectedGenome := goga.Roulette(genomeArray, totalFitness)
+
+		// The least fit genome is shifted to a fitness of 0 so is never picked
+		t.Assert(selectedGenome, Not(Equals), genomeArray[0])
+	}
+}
+
+func (s *SelectorSuite) TestShouldRouletteByRankWhenFitnessOverflows(t *C) {
+	genomeArray := helperGenomesWithFitness(-math.MaxFloat64, -math.MaxFloat64, 1, 2)
+	totalFitness := -math.Inf(1)
+
+	// Picked by rank, from 1 to 4, so the two least fit genomes are picked 3 times in 10
+	picked := helperPickFrequency(goga.Roulette, genomeArray)
+	t.Assert(picked[genomeArray[0]]+picked[genomeArray[1]] > 1500, IsTrue)
+	t.Assert(picked[genomeArray[0]]+picked[genomeArray[1]] < 4500, IsTrue)
+	t.Assert(picked[genomeArray[3]] > picked[genomeArray[2]], IsTrue)
+
+	t.Assert(goga.SUSBatch(genomeArray, totalFitness, 10), HasLen, 10)
+	t.Assert(goga.SUSBatch(helperGenomesWithFitness(-math.MaxFloat64, math.MaxFloat64), 0, 10), HasLen, 10)
+}
+
+func helperGenomesWithFitness(fitness ...float64) []goga.Genome {
+	genomeArray := make([]goga.Genome, len(fitness))
+	for i, f := range fitness {
+		genomeArray[i] = goga.NewGenome(goga.Bitset{})
+		genomeArray[i].SetFitness(f)
+	}
+	return genomeArray
+}
+
+func (s *SelectorSuite) TestShouldPickFittestInDeterministicTournament(t *C) {
+	genomeArray := helperGenomesWithFitness(-3, 7, -10, 2, 0)
+	tournament := goga.TournamentWithoutReplacement(len(genomeArray), 1)
+	for i := 0; i < 100; i++ {
+		t.Assert(tournament(genomeArray, 0), Equals, genomeArray[1])
+	}
+
+	// Asking for a bigger tournament than there are genomes still ends
+	t.Assert(goga.TournamentWithoutReplacement(100, 1)(genomeArray, 0), Equals, genomeArray[1])
+}
+
+func (s *SelectorSuite) TestShouldPickLeastFitWhenFittestNeverWins(t *C) {
+	genomeArray := helperGenomesWithFitness(-3, 7, -10, 2, 0)
+	tournament := goga.TournamentWithoutReplacement(len(genomeArray), 0)
+	for i := 0; i < 100; i++ {
+		t.Assert(tournament(genomeArray, 0), Equals, genomeArray[2])
+	}
+}
+
+func (s *SelectorSuite) TestShouldFavourFitterGenomesInTournament(t *C) {
+	genomeArray := helperGenomesWithFitness(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	for _, tournament := range []func([]goga.Genome, float64) goga.Genome{
+		goga.Tournament(3, 1),
+		goga.Tournament(3, 0.75),
+		goga.TournamentWithoutReplacement(3, 0.75),
+	} {
+		picked := make([]int, len(genomeArray))
+		for i := 0; i < 10000; i++ {
+			picked[int(tournament(genomeArray, 0).GetFitness())]++
+		}
+		t.Assert(picked[9] > picked[5], IsTrue)
+		t.Assert(picked[5] > picked[0], IsTrue)
+	}
+
+	// A tournament of one is a random pick
+	picked := map[goga.Genome]bool{}
+	for i := 0; i < 1000; i++ {
+		picked[goga.Tournament(1, 1)(genomeArray, 0)] = true
+	}
+	t.Assert(picked, HasLen, len(genomeArray))
+}
+
+func (s *SelectorSuite) TestShouldRepeatTournamentForSameRand(t *C) {
+	genomeArray := helperGenomesWithFitness(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	tournament := goga.TournamentRand(4, 0.6)
+	r1, r2 := rand.New(goga.NewSource(5)), rand.New(goga.NewSource(5))
+	for i := 0; i < 100; i++ {
+		t.Assert(tournament(r1, genomeArray, 0), Equals, tournament(r2, genomeArray, 0))
+	}
+}
+
+func helperPickFrequency(selection func([]goga.Genome, float64) goga.Genome, genomeArray []goga.Genome) map[goga.Genome]int {
+	totalFitness := 0.
+	for _, g := range genomeArray {
+		totalFitness += g.GetFitness()
+	}
+	picked := map[goga.Genome]int{}
+	for i := 0; i < 10000; i++ {
+		picked[selection(genomeArray, totalFitness)]++
+	}
+	return picked
+}
+
+func (s *SelectorSuite) TestShouldPickByLinearRank(t *C) {
+	genomeArray := helperGenomesWithFitness(5, -2, 9, 0)
+	picked := helperPickFrequency(goga.LinearRank(2), genomeArray)
+
+	// The least fit genome has no chance with the highest pressure
+	t.Assert(picked[genomeArray[1]], Equals, 0)
+	t.Assert(picked[genomeArray[2]] > picked[genomeArray[0]], IsTrue)
+	t.Assert(picked[genomeArray[0]] > picked[genomeArray[3]], IsTrue)
+
+	// Without pressure every genome is as likely to be picked
+	picked = helperPickFrequency(goga.LinearRank(1), genomeArray)
+	for _, g := range genomeArray {
+		t.Assert(picked[g] > 2000, IsTrue)
+	}
+}
+
+func (s *SelectorSuite) TestShouldPickByExponentialRank(t *C) {
+	genomeArray := helperGenomesWithFitness(5, -2, 9, 0)
+	picked := helperPickFrequency(goga.ExponentialRank(0.1), genomeArray)
+	t.Assert(picked[genomeArray[2]] > 8500, IsTrue)
+	t.Assert(picked[genomeArray[0]] > picked[genomeArray[3]], IsTrue)
+	t.Assert(picked[genomeArray[3]] >= picked[genomeArray[1]], IsTrue)
+}
+
+func (s *SelectorSuite) TestShouldPickFromFittestByTruncation(t *C) {
+	genomeArray := helperGenomesWithFitness(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	picked := helperPickFrequency(goga.Truncation(0.3), genomeArray)
+	t.Assert(picked, HasLen, 3)
+	for _, g := range genomeArray[7:] {
+		t.Assert(picked[g] > 0, IsTrue)
+	}
+
+	// The fittest genome is always a candidate
+	picked = helperPickFrequency(goga.Truncation(0), genomeArray)
+	t.Assert(picked, DeepEquals, map[goga.Genome]int{genomeArray[9]: 10000})
+}
+
+func (s *SelectorSuite) TestShouldRankAgainWhenFitnessChanges(t *C) {
+	genomeArray := helperGenomesWithFitness(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	truncation := goga.Truncation(0)
+	linear := goga.LinearRank(2)
+	boltzmann := goga.NewBoltzmann(func(int) float64 { return 0.01 })
+	t.Assert(truncation(genomeArray, 0), Equals, genomeArray[9])
+	t.Assert(helperPickFrequency(linear, genomeArray)[genomeArray[0]], Equals, 0)
+	t.Assert(helperPickFrequency(boltzmann.Go, genomeArray)[genomeArray[9]], Equals, 10000)
+
+	genomeArray[0].SetFitness(10)
+	t.Assert(truncation(genomeArray, 0), Equals, genomeArray[0])
+	t.Assert(helperPickFrequency(linear, genomeArray)[genomeArray[1]], Equals, 0)
+	t.Assert(helperPickFrequency(boltzmann.Go, genomeArray)[genomeArray[0]], Equals, 10000)
+
+	reordered := append([]goga.Genome{helperGenomesWithFitness(20)[0]}, genomeArray[1:]...)
+	t.Assert(truncation(reordered, 0), Equals, reordered[0])
+}
+
+func (s *SelectorSuite) TestShouldSampleUniversally(t *C) {
+	genomeArray := helperGenomesWithFitness(1, 1, 2, 0, 4)
+	for i := 0; i < 100; i++ {
+		picked := map[goga.Genome]int{}
+		for _, g := range goga.SUSBatch(genomeArray, 8, 8) {
+			picked[g]++
+		}
+		t.Assert(picked[genomeArray[0]], Equals, 1)
+		t.Assert(picked[genomeArray[1]], Equals, 1)
+		t.Assert(picked[genomeArray[2]], Equals, 2)
+		t.Assert(picked[genomeArray[3]], Equals, 0)
+		t.Assert(picked[genomeArray[4]], Equals, 4)
+	}
+
+	t.Assert(goga.SUSBatch(helperGenomesWithFitness(-1, -3), -4, 10), HasLen, 10)
+	t.Assert(func() { goga.SUS([]goga.Genome{}, 0) }, Panics, "genome array contains no elements")
+}
+
+func (s *SelectorSuite) TestShouldSelectByBoltzmann(t *C) {
+	genomeArray := helperGenomesWithFitness(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	boltzmann := goga.NewBoltzmann(goga.ExponentialSchedule(1000, 0.01))
+	hot := helperPickFrequency(boltzmann.Select, genomeArray)
+	t.Assert(hot, HasLen, len(genomeArray))
+
+	boltzmann.SetGeneration(2)
+	cold := helperPickFrequency(boltzmann.Select, genomeArray)
+	t.Assert(cold[genomeArray[9]] > 9900, IsTrue)
+
+	frozen := goga.NewBoltzmann(func(int) float64 { return 0 })
+	t.Assert(helperPickFrequency(frozen.Go, genomeArray), DeepEquals, map[goga.Genome]int{genomeArray[9]: 10000})
+}
+
+func (s *SelectorSuite) TestShouldPassGenerationOnThroughSelector(t *C) {
+	boltzmann := goga.NewBoltzmann(goga.ExponentialSchedule(1000, 0.01))
+	selector := goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, F: boltzmann.Select, OnGeneration: boltzmann.SetGeneration},
+	})
+	selector.(goga.GenerationSetter).SetGeneration(2)
+
+	genomeArray := helperGenomesWithFitness(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	t.Assert(helperPickFrequency(selector.Go, genomeArray)[genomeArray[9]] > 9900, IsTrue)
+}
+
+type MyBatchSelector struct {
+	batches, parents, singles int
+	generations               []int
+}
+
+func (ms *MyBatchSelector) Go(genomes []goga.Genome, totalFitness float64) goga.Genome {
+	ms.singles++
+	return genomes[0]
+}
+func (ms *MyBatchSelector) GoBatch(genomes []goga.Genome, totalFitness float64, n int) []goga.Genome {
+	ms.batches++
+	ms.parents += n
+	return goga.SUSBatch(genomes, totalFitness, n)
+}
+func (ms *MyBatchSelector) SetGeneration(generation int) {
+	ms.generations = append(ms.generations, generation)
+}
+
+func (s *SelectorSuite) TestShouldSelectParentsInBatches(t *C) {
+	genAlgo := helperGenerateMutatingGeneticAlgorithm()
+	selector := &MyBatchSelector{}
+	genAlgo.Selector = selector
+	genAlgo.Simulator = &MySimulatorBitCount{}
+	genAlgo.Init(goga.PopulationSize(10), goga.MaxGenerations(4))
+	genAlgo.Simulate()
+
+	t.Assert(selector.singles, Equals, 0)
+	t.Assert(selector.batches >= 3, IsTrue)
+	t.Assert(selector.parents >= 3*2*(10*2-1), IsTrue)
+	t.Assert(selector.generations, DeepEquals, []int{0, 1, 2, 3})
+}
@@ -0,0 +1,188 @@
+package goga
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+)
+
+// ShardedCache - an LRU cache that is safe for concurrent access
+// Keys are spread over a number of shards by their hash, each shard being an LRU cache of
+// its own behind its own lock, so that simulation workers, islands or parallel runs can share
+// the cache without queueing on a single lock. Entries are evicted from the least recently used
+// end of the shard they are in, so the cache as a whole is only approximately least recently used
+type ShardedCache[K comparable, V any] struct {
+	// OnEvicted optionally specifies a callback function to be executed when an entry is
+	// purged from the cache, it is called without any lock held and should be set before
+	// the cache is used
+	OnEvicted func(key K, value V)
+
+	hash   func(K) uint64
+	shards []*cacheShard[K, V]
+}
+
+type cacheShard[K comparable, V any] struct {
+	m          sync.Mutex
+	maxEntries int
+	ll         *list.List
+	cache      map[K]*list.Element
+}
+
+type shardEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// NewShardedCache creates a new ShardedCache of 'shards' shards that hashes keys with 'hash'
+// If maxEntries is zero the cache has no limit, otherwise each shard holds up to its share of
+// 'maxEntries', rounded up
+func NewShardedCache[K comparable, V any](shards, maxEntries int, hash func(K) uint64) *ShardedCache[K, V] {
+	if shards < 1 {
+		shards = 1
+	}
+	perShard := 0
+	if maxEntries > 0 {
+		perShard = (maxEntries + shards - 1) / shards
+	}
+	c := &ShardedCache[K, V]{hash: hash, shards: make([]*cacheShard[K, V], shards)}
+	for i := range c.shards {
+		c.shards[i] = &cacheShard[K, V]{
+			maxEntries: perShard,
+			ll:         list.New(),
+			cache:      make(map[K]*list.Element),
+		}
+	}
+	return c
+}
+
+// NewStringShardedCache creates a new ShardedCache with string keys, such as genome keys,
+// see NewShardedCache
+func NewStringShardedCache[V any](shards, maxEntries int) *ShardedCache[string, V] {
+	return NewShardedCache[string, V](shards, maxEntries, hashString)
+}
+
+// hashString returns the 64-bit FNV-1a hash of 's'
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func (c *ShardedCache[K, V]) shard(key K) *cacheShard[K, V] {
+	return c.shards[c.hash(key)%uint64(len(c.shards))]
+}
+
+// Add adds a value to the cache.
+func (c *ShardedCache[K, V]) Add(key K, value V) {
+	s := c.shard(key)
+	s.m.Lock()
+	if ee, ok := s.cache[key]; ok {
+		s.ll.MoveToFront(ee)
+		ee.Value.(*shardEntry[K, V]).value = value
+		s.m.Unlock()
+		return
+	}
+	s.cache[key] = s.ll.PushFront(&shardEntry[K, V]{key, value})
+	var evicted *shardEntry[K, V]
+	if s.maxEntries != 0 && s.ll.Len() > s.maxEntries {
+		evicted = s.remove(s.ll.Back())
+	}
+	s.m.Unlock()
+	if evicted != nil {
+		c.evicted(evicted)
+	}
+}
+
+// Get looks up a key's value from the cache.
+func (c *ShardedCache[K, V]) Get(key K) (value V, ok bool) {
+	s := c.shard(key)
+	s.m.Lock()
+	defer s.m.Unlock()
+	if ele, hit := s.cache[key]; hit {
+		s.ll.MoveToFront(ele)
+		return ele.Value.(*shardEntry[K, V]).value, true
+	}
+	return
+}
+
+// Remove removes the provided key from the cache.
+func (c *ShardedCache[K, V]) Remove(key K) {
+	s := c.shard(key)
+	s.m.Lock()
+	var evicted *shardEntry[K, V]
+	if ele, hit := s.cache[key]; hit {
+		evicted = s.remove(ele)
+	}
+	s.m.Unlock()
+	if evicted != nil {
+		c.evicted(evicted)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *ShardedCache[K, V]) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		s.m.Lock()
+		n += s.ll.Len()
+		s.m.Unlock()
+	}
+	return n
+}
+
+// Clear purges all stored items from the cache.
+func (c *ShardedCache[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.m.Lock()
+		ll := s.ll
+		s.ll = list.New()
+		s.cache = make(map[K]*list.Element)
+		s.m.Unlock()
+		for e := ll.Front(); e != nil; e = e.Next() {
+			c.evicted(e.Value.(*shardEntry[K, V]))
+		}
+	}
+}
+
+// remove removes 'e' from the shard, which must be locked, and returns its entry
+func (s *cacheShard[K, V]) remove(e *list.Element) *shardEntry[K, V] {
+	s.ll.Remove(e)
+	kv := e.Value.(*shardEntry[K, V])
+	delete(s.cache, kv.key)
+	return kv
+}
+
+func (c *ShardedCache[K, V]) evicted(kv *shardEntry[K, V]) {
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+}
+
+// MemoryFitnessStore - a FitnessStore held in memory in a ShardedCache, which can be shared by
+// the islands of an Islands, by genetic algorithms run side by side or by a simulator that
+// memoises fitness itself from its simulation workers
+type MemoryFitnessStore struct {
+	cache *ShardedCache[string, StoredFitness]
+}
+
+// NewMemoryFitnessStore returns a MemoryFitnessStore that holds the fitness of up to 'maxEntries'
+// genomes, evicting the least recently used, in 'shards' shards, zero 'maxEntries' means no limit
+func NewMemoryFitnessStore(shards, maxEntries int) *MemoryFitnessStore {
+	return &MemoryFitnessStore{cache: NewStringShardedCache[StoredFitness](shards, maxEntries)}
+}
+
+// Get returns the fitness stored for 'key'
+func (s *MemoryFitnessStore) Get(key string) (StoredFitness, bool) {
+	return s.cache.Get(key)
+}
+
+// Put stores the fitness of the genome with key 'key'
+func (s *MemoryFitnessStore) Put(key string, fitness StoredFitness) error {
+	s.cache.Add(key, fitness)
+	return nil
+}
+
+// Len returns the number of genomes whose fitness is stored
+func (s *MemoryFitnessStore) Len() int {
+	return s.cache.Len()
+}
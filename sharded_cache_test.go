@@ -0,0 +1,117 @@
+package goga_test
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type ShardedCacheSuite struct {
+}
+
+var _ = Suite(&ShardedCacheSuite{})
+
+func (s *ShardedCacheSuite) TestShouldEvictLeastRecentlyUsed(t *C) {
+	cache := goga.NewShardedCache[int, string](1, 2, func(k int) uint64 { return uint64(k) })
+	var evicted []int
+	cache.OnEvicted = func(key int, value string) {
+		evicted = append(evicted, key)
+	}
+	cache.Add(1, "one")
+	cache.Add(2, "two")
+	v, ok := cache.Get(1)
+	t.Assert(ok, Equals, true)
+	t.Assert(v, Equals, "one")
+
+	cache.Add(3, "three")
+	t.Assert(evicted, DeepEquals, []int{2})
+	_, ok = cache.Get(2)
+	t.Assert(ok, Equals, false)
+	t.Assert(cache.Len(), Equals, 2)
+
+	cache.Remove(1)
+	t.Assert(evicted, DeepEquals, []int{2, 1})
+	cache.Clear()
+	t.Assert(evicted, DeepEquals, []int{2, 1, 3})
+	t.Assert(cache.Len(), Equals, 0)
+}
+
+func (s *ShardedCacheSuite) TestShouldBoundEachShard(t *C) {
+	cache := goga.NewStringShardedCache[int](4, 10)
+	for i := 0; i < 100; i++ {
+		cache.Add(strconv.Itoa(i), i)
+	}
+	// Each of the 4 shards holds at most 3 entries
+	t.Assert(cache.Len() <= 12, Equals, true)
+	t.Assert(cache.Len() >= 4, Equals, true)
+
+	unbounded := goga.NewStringShardedCache[int](4, 0)
+	for i := 0; i < 100; i++ {
+		unbounded.Add(strconv.Itoa(i), i)
+	}
+	t.Assert(unbounded.Len(), Equals, 100)
+}
+
+func (s *ShardedCacheSuite) TestShouldBeSafeForConcurrentAccess(t *C) {
+	cache := goga.NewStringShardedCache[int](8, 0)
+	wg := sync.WaitGroup{}
+	for w := 0; w < kNumThreads; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(i)
+				cache.Add(key, i)
+				if v, ok := cache.Get(key); ok && v != i {
+					panic("unexpected value")
+				}
+				if i%10 == w%10 {
+					cache.Remove(key)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	t.Assert(cache.Len() <= 1000, Equals, true)
+	t.Assert(cache.Len() > 0, Equals, true)
+}
+
+func (s *ShardedCacheSuite) TestShouldShareMemoryFitnessStore(t *C) {
+	store := goga.NewMemoryFitnessStore(4, 0)
+	first := helperGenerateSeededGeneticAlgorithm(goga.Seed(5), goga.MaxGenerations(1), goga.StoreFitness(store))
+	first.Simulator = &MySimulatorBitCount{}
+	t.Assert(first.Simulate().Evaluations, Equals, 20)
+	t.Assert(store.Len(), Equals, 20)
+
+	ms := MySimulatorCounter{}
+	second := helperGenerateSeededGeneticAlgorithm(goga.Seed(5), goga.MaxGenerations(1), goga.StoreFitness(store))
+	second.Simulator = &ms
+	result := second.Simulate()
+	t.Assert(ms.NumCalls, Equals, 0)
+	t.Assert(result.Elite.GetFitness(), Equals, first.Elite().GetFitness())
+}
+
+func (s *ShardedCacheSuite) TestShouldShareMemoryFitnessStoreBetweenWorkers(t *C) {
+	store := goga.NewMemoryFitnessStore(4, 0)
+	ms := MySimulatorCounter{}
+	islands := make([]*goga.GeneticAlgorithm, 2)
+	for i := range islands {
+		island := helperGenerateSeededGeneticAlgorithm(goga.Seed(6), goga.StoreFitness(store))
+		island.Simulator = &ms
+		islands[i] = &island
+	}
+	is := goga.NewIslands(islands...)
+	is.Init(goga.ParallelSimulations(1), goga.MaxGenerations(1))
+	result := is.Simulate()
+
+	// Both islands breed the same genomes at the same time, which are looked up in the store as
+	// they are bred, before either is simulated, and again by the worker about to simulate them
+	t.Assert(ms.NumCalls, Equals, 20)
+	t.Assert(result.Evaluations, Equals, 20)
+	hits0, _ := islands[0].CacheStats()
+	hits1, _ := islands[1].CacheStats()
+	t.Assert(hits0+hits1, Equals, 20)
+	t.Assert(store.Len(), Equals, 20)
+}
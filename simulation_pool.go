@@ -0,0 +1,155 @@
+package goga
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// simulation - a genome handed to a simulation worker along with the simulator to
+// score it with, the random number generator for its simulation, if there is one, the
+// number of times to retry it should it fail and how long it may take, and the FitnessStore
+// to look it up in and put its fitness in, if there is one
+type simulation struct {
+	genome    Genome
+	simulator Simulator
+	rand      *rand.Rand
+	retries   int
+	timeout   time.Duration
+	store     FitnessStore
+}
+
+// simulationPool - a fixed number of workers that simulate the genomes submitted to them
+// A pool is begun for each generation and synced once every genome of it has been submitted
+// The pool counts the simulations submitted to it, over every generation, and refuses any
+// more once 'maxEvaluations', if set, have been, genomes the workers find in the FitnessStore
+// are not counted
+type simulationPool struct {
+	channel        chan simulation
+	waitGroup      sync.WaitGroup
+	m              sync.Mutex
+	failures       map[Genome]error
+	hits           map[Genome]bool
+	storeErr       error
+	evaluations    int
+	maxEvaluations int
+}
+
+// begin starts 'workers' simulation workers
+func (p *simulationPool) begin(ctx context.Context, workers int) {
+	p.channel = make(chan simulation)
+	p.failures = make(map[Genome]error)
+	p.hits = make(map[Genome]bool)
+	p.storeErr = nil
+
+	for i := 0; i < workers; i++ {
+		go func(channel chan simulation) {
+			for s := range channel {
+				p.simulate(ctx, s)
+				p.waitGroup.Done()
+			}
+		}(p.channel)
+	}
+}
+
+// simulate simulates 's', retrying it as many times as it allows while it fails, and records
+// the error of a simulation that still fails, see failed
+// A genome found in the FitnessStore, having been simulated since it was bred by a run or island
+// sharing the store, is given the stored fitness instead, see storeHits, and the fitness of a
+// genome that is simulated is put in the store
+func (p *simulationPool) simulate(ctx context.Context, s simulation) {
+	if s.store != nil {
+		if f, ok := s.store.Get(s.genome.Key()); ok {
+			setStoredFitness(s.genome, f)
+			p.m.Lock()
+			p.hits[s.genome] = true
+			p.m.Unlock()
+			return
+		}
+	}
+	if s.rand != nil {
+		ctx = contextWithRand(ctx, s.rand)
+	}
+	var err error
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if err = s.attempt(ctx); err == nil || err == ErrSimulationTimeout || ctx.Err() != nil {
+			break
+		}
+	}
+	if err == nil && s.store != nil {
+		err := s.store.Put(s.genome.Key(), storedFitness(s.genome))
+		p.m.Lock()
+		if err != nil && p.storeErr == nil {
+			p.storeErr = err
+		}
+		p.m.Unlock()
+	}
+	if err != nil {
+		p.m.Lock()
+		p.failures[s.genome] = err
+		p.m.Unlock()
+	}
+}
+
+// attempt simulates 's' once, giving up on it after its timeout, if it has one
+func (s simulation) attempt(ctx context.Context) error {
+	simulator := AsErrorSimulator(s.simulator)
+	if s.timeout <= 0 {
+		return simulator.SimulateErr(ctx, s.genome)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	g := copyGenome(s.genome)
+	done := make(chan error, 1)
+	go func() {
+		done <- simulator.SimulateErr(ctx, g)
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			setStoredFitness(s.genome, storedFitness(g))
+		}
+		return err
+	case <-ctx.Done():
+		return ErrSimulationTimeout
+	}
+}
+
+// exhausted reports whether the pool has been submitted its maximum number of simulations
+func (p *simulationPool) exhausted() bool {
+	return p.maxEvaluations > 0 && p.evaluations >= p.maxEvaluations
+}
+
+// submit hands 's' to a simulation worker, it returns false without
+// simulating 's' if the context is done first
+func (p *simulationPool) submit(ctx context.Context, s simulation) bool {
+	p.waitGroup.Add(1)
+	select {
+	case p.channel <- s:
+		p.evaluations++
+		return true
+	case <-ctx.Done():
+		p.waitGroup.Done()
+		return false
+	}
+}
+
+// sync stops the workers once every submitted simulation has finished
+func (p *simulationPool) sync() {
+	close(p.channel)
+	p.waitGroup.Wait()
+	p.evaluations -= len(p.hits)
+}
+
+// failed returns the error of each genome whose simulation failed since the pool was begun
+func (p *simulationPool) failed() map[Genome]error {
+	return p.failures
+}
+
+// storeHits returns the genomes found in the FitnessStore since the pool was begun, which
+// were not simulated, along with the first error from putting fitness in the store
+func (p *simulationPool) storeHits() (map[Genome]bool, error) {
+	return p.hits, p.storeErr
+}
@@ -1,5 +1,11 @@
 package goga
 
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
 // Simulator - a Simulator interface
 type Simulator interface {
 	OnBeginSimulation() []Genome
@@ -8,6 +14,62 @@ type Simulator interface {
 	ExitFunc(Genome) bool
 }
 
+// ContextSimulator - an optional extension of the Simulator interface
+// When a simulator implements it, SimulateContext is called in place of Simulate and
+// is passed the context of the run so that long simulations can stop early once
+// the context is done
+type ContextSimulator interface {
+	SimulateContext(context.Context, Genome)
+}
+
+// ErrorSimulator - an optional extension of the Simulator interface for simulations that can fail
+// When a simulator implements it, SimulateErr is called in place of Simulate and SimulateContext
+// and is passed the context of the run; a returned error is handled by the FailurePolicy of the
+// genetic algorithm, see SimulationFailures, and the genome's fitness is ignored
+type ErrorSimulator interface {
+	SimulateErr(context.Context, Genome) error
+}
+
+// AsErrorSimulator returns 's' as an ErrorSimulator, simulators that do not implement it are
+// adapted to call SimulateContext, if they implement ContextSimulator, or Simulate and never fail
+// Whatever the simulator, a panic while simulating is recovered and returned as a *PanicError
+func AsErrorSimulator(s Simulator) ErrorSimulator {
+	return &recoveringSimulator{simulator: s}
+}
+
+// PanicError - the error returned for a simulation that panicked, holding the value
+// passed to panic and the stack of the goroutine that panicked
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("simulation panicked: %v", e.Value)
+}
+
+// recoveringSimulator - the ErrorSimulator returned by AsErrorSimulator
+type recoveringSimulator struct {
+	simulator Simulator
+}
+
+func (rs *recoveringSimulator) SimulateErr(ctx context.Context, g Genome) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	switch s := rs.simulator.(type) {
+	case ErrorSimulator:
+		return s.SimulateErr(ctx, g)
+	case ContextSimulator:
+		s.SimulateContext(ctx, g)
+	default:
+		s.Simulate(g)
+	}
+	return nil
+}
+
 // NullSimulator - a null implementation of the Simulator interface
 type NullSimulator struct {
 }
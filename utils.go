@@ -56,7 +56,7 @@ limitations under the License.
 
 // Package lru implements an LRU cache.
 
-// Cache is an LRU cache. It is not safe for concurrent access.
+// Cache is an LRU cache. It is not safe for concurrent access, see ShardedCache.
 type Cache struct {
 	// MaxEntries is the maximum number of cache entries before
 	// an item is evicted. Zero means no limit.
@@ -119,6 +119,17 @@ func (c *Cache) Get(key Key) (value interface{}, ok bool) {
 	return
 }
 
+// peek looks up a key's value from the cache without marking it as recently used.
+func (c *Cache) peek(key Key) (value interface{}, ok bool) {
+	if c.cache == nil {
+		return
+	}
+	if ele, hit := c.cache[key]; hit {
+		return ele.Value.(*entry).value, true
+	}
+	return
+}
+
 // Remove removes the provided key from the cache.
 func (c *Cache) Remove(key Key) {
 	if c.cache == nil {
@@ -149,6 +160,18 @@ func (c *Cache) removeElement(e *list.Element) {
 	}
 }
 
+// keys returns the keys in the cache from the least to the most recently used.
+func (c *Cache) keys() []Key {
+	if c.cache == nil {
+		return nil
+	}
+	ret := make([]Key, 0, c.ll.Len())
+	for e := c.ll.Back(); e != nil; e = e.Prev() {
+		ret = append(ret, e.Value.(*entry).key)
+	}
+	return ret
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache) Len() int {
 	if c.cache == nil {
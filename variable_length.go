@@ -0,0 +1,242 @@
+package goga
+
+import (
+	"context"
+	"math/rand"
+)
+
+// BlockMater - grows, shrinks and splices bitset genomes a block of 'Width' bits at a time, such
+// as one shape of a drawing or one rule of a rule set, so that the number of blocks can evolve
+// MinBlocks and MaxBlocks bound the number of blocks of the offspring, a MaxBlocks of 0 leaves it
+// unbounded, a MinBlocks of at least 1 keeps genomes long enough for the bit operators, such as
+// Mutate, that may follow. Genomes must be a whole number of blocks long
+type BlockMater struct {
+	Width     int
+	MinBlocks int
+	MaxBlocks int
+}
+
+// blocks returns a copy of the bits of 'g' and its number of blocks, it panics if
+// 'g' is not a whole number of blocks long
+func (bm *BlockMater) blocks(g Genome) (Bitset, int) {
+	if bm.Width <= 0 {
+		panic("block width must be positive")
+	}
+	bits := g.GetBits().CreateCopy()
+	if bits.GetSize()%bm.Width != 0 {
+		panic("genome is not a whole number of blocks")
+	}
+	return bits, bits.GetSize() / bm.Width
+}
+
+// maxBlocks returns the most blocks offspring may have
+func (bm *BlockMater) maxBlocks() int {
+	if bm.MaxBlocks <= 0 {
+		return int(^uint(0) >> 1)
+	}
+	return bm.MaxBlocks
+}
+
+// randomBlock returns a block of random bits
+func (bm *BlockMater) randomBlock(rng *rand.Rand) Bitset {
+	b := Bitset{}
+	b.Create(bm.Width)
+	for i := 0; i < bm.Width; i += wordSize {
+		width := min(wordSize, bm.Width-i)
+		b.SetUint64(i, width, rng.Uint64()&(^uint64(0)>>(wordSize-width)))
+	}
+	return b
+}
+
+// splice returns the first 'head' blocks of 'b1' followed by the blocks of 'b2' from block 'tail' on
+func (bm *BlockMater) splice(b1 *Bitset, head int, b2 *Bitset, tail int) Bitset {
+	ret := b1.Slice(0, head*bm.Width)
+	rest := b2.Slice(tail*bm.Width, b2.GetSize()-tail*bm.Width)
+	ret.Append(&rest)
+	return ret
+}
+
+// InsertMutate -
+// Accepts 2 genomes and inserts a block of random bits at a random block boundary of the
+// first, unless it already has MaxBlocks blocks
+// i.e. with a width of 2
+// input genomes of:
+// 0000 and 1111
+// could produce output genomes of:
+// 001000 and 1111
+func (bm *BlockMater) InsertMutate(g1, g2 Genome) (Genome, Genome) {
+	return bm.InsertMutateRand(globalRand, g1, g2)
+}
+
+// InsertMutateRand is InsertMutate drawing from the random number generator 'rng'
+func (bm *BlockMater) InsertMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	b1, n := bm.blocks(g1)
+	if n < bm.maxBlocks() {
+		at := rng.Intn(n + 1)
+		block := bm.randomBlock(rng)
+		head := b1.Slice(0, at*bm.Width)
+		head.Append(&block)
+		b1 = bm.splice(&head, at+1, &b1, at)
+	}
+	return NewGenome(b1), NewGenome(*g2.GetBits())
+}
+
+// DeleteMutate -
+// Accepts 2 genomes and removes a random block of the first, unless it already has
+// MinBlocks blocks or fewer
+// i.e. with a width of 2
+// input genomes of:
+// 001011 and 1111
+// could produce output genomes of:
+// 0011 and 1111
+func (bm *BlockMater) DeleteMutate(g1, g2 Genome) (Genome, Genome) {
+	return bm.DeleteMutateRand(globalRand, g1, g2)
+}
+
+// DeleteMutateRand is DeleteMutate drawing from the random number generator 'rng'
+func (bm *BlockMater) DeleteMutateRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	b1, n := bm.blocks(g1)
+	if n > bm.MinBlocks && n > 0 {
+		at := rng.Intn(n)
+		b1 = bm.splice(&b1, at, &b1, at+1)
+	}
+	return NewGenome(b1), NewGenome(*g2.GetBits())
+}
+
+// CutAndSplice -
+// Accepts 2 genomes and cuts each at its own random block boundary, the first child is the
+// head of the first genome followed by the tail of the second and the second child is the
+// head of the second followed by the tail of the first, so the children may differ in length
+// from their parents. The cut of the second genome is drawn from those that keep both children
+// within MinBlocks and MaxBlocks, if there are none the children are copies of their parents
+// i.e. with a width of 1
+// input genomes of:
+// 000000 and 111
+// could produce output genomes of:
+// 0011 and 10000
+func (bm *BlockMater) CutAndSplice(g1, g2 Genome) (Genome, Genome) {
+	return bm.CutAndSpliceRand(globalRand, g1, g2)
+}
+
+// CutAndSpliceRand is CutAndSplice drawing from the random number generator 'rng'
+func (bm *BlockMater) CutAndSpliceRand(rng *rand.Rand, g1, g2 Genome) (Genome, Genome) {
+	b1, n1 := bm.blocks(g1)
+	b2, n2 := bm.blocks(g2)
+	cut1 := rng.Intn(n1 + 1)
+
+	// The first child has cut1 + n2 - cut2 blocks and the second cut2 + n1 - cut1
+	lowest, highest := 0, n2
+	if low := cut1 + n2 - bm.maxBlocks(); low > lowest {
+		lowest = low
+	}
+	if low := bm.MinBlocks - n1 + cut1; low > lowest {
+		lowest = low
+	}
+	if high := cut1 + n2 - bm.MinBlocks; high < highest {
+		highest = high
+	}
+	if high := bm.maxBlocks() - n1 + cut1; high < highest {
+		highest = high
+	}
+	if lowest > highest {
+		return NewGenome(b1), NewGenome(b2)
+	}
+	cut2 := lowest + rng.Intn(highest-lowest+1)
+	return NewGenome(bm.splice(&b1, cut1, &b2, cut2)), NewGenome(bm.splice(&b2, cut2, &b1, cut1))
+}
+
+// BlockBitsetCreate - creates bitsets of between 'MinBlocks' and 'MaxBlocks' blocks of
+// 'Width' random bits, the number of blocks is drawn evenly, see BlockMater
+type BlockBitsetCreate struct {
+	Width     int
+	MinBlocks int
+	MaxBlocks int
+	rand      *rand.Rand
+}
+
+// Go returns a bitset of a random number of blocks of random bits
+func (bbc *BlockBitsetCreate) Go() Bitset {
+	rng := RandOrGlobal(bbc.rand)
+	bm := BlockMater{Width: bbc.Width}
+	b := Bitset{}
+	b.Create(0)
+	for n := bbc.MinBlocks + rng.Intn(bbc.MaxBlocks-bbc.MinBlocks+1); n > 0; n-- {
+		block := bm.randomBlock(rng)
+		b.Append(&block)
+	}
+	return b
+}
+
+// SetRand sets the random number generator the blocks are drawn from
+func (bbc *BlockBitsetCreate) SetRand(rng *rand.Rand) {
+	bbc.rand = rng
+}
+
+// LengthPenalty - a Simulator that wraps another and makes the fitness it gives each genome
+// worse by 'Penalty' of the length of the genome, to stop variable-length genomes growing
+// without getting any fitter
+// The length is the number of values of real-valued, integer and permutation genomes and the
+// number of bits of any other genome. The penalty is taken off the fitness when maximising and
+// added to it when minimising, 'Direction' is set to the ObjectiveDirection of the genetic
+// algorithm it simulates for, see DirectionSetter
+// When the wrapped simulator is an ErrorSimulator its failures are passed on by SimulateErr,
+// to be handled by the FailurePolicy of the genetic algorithm, and failed genomes are not penalised
+type LengthPenalty struct {
+	Simulator
+	Penalty   func(length int) float64
+	Direction Direction
+}
+
+// Simulate simulates 'g' with the wrapped simulator and then penalises its length
+func (lp *LengthPenalty) Simulate(g Genome) {
+	lp.Simulator.Simulate(g)
+	lp.penalise(g)
+}
+
+// SimulateContext simulates 'g' with the wrapped simulator, passing it 'ctx' if it is a
+// ContextSimulator, and then penalises its length
+func (lp *LengthPenalty) SimulateContext(ctx context.Context, g Genome) {
+	if contextSimulator, ok := lp.Simulator.(ContextSimulator); ok {
+		contextSimulator.SimulateContext(ctx, g)
+	} else {
+		lp.Simulator.Simulate(g)
+	}
+	lp.penalise(g)
+}
+
+// SimulateErr simulates 'g' with the wrapped simulator, see AsErrorSimulator, and then penalises
+// its length unless the simulation failed
+func (lp *LengthPenalty) SimulateErr(ctx context.Context, g Genome) error {
+	if err := AsErrorSimulator(lp.Simulator).SimulateErr(ctx, g); err != nil {
+		return err
+	}
+	lp.penalise(g)
+	return nil
+}
+
+// SetDirection - sets the direction the penalty is applied in and passes it on to the wrapped
+// simulator if it is a DirectionSetter
+func (lp *LengthPenalty) SetDirection(d Direction) {
+	lp.Direction = d
+	if setter, ok := lp.Simulator.(DirectionSetter); ok {
+		setter.SetDirection(d)
+	}
+}
+
+func (lp *LengthPenalty) penalise(g Genome) {
+	penalty := lp.Penalty(genomeLength(g))
+	g.SetFitness(g.GetFitness() - lp.Direction.directed(penalty))
+}
+
+// genomeLength returns the number of values of 'g', or of bits if it is not a genome of values
+func genomeLength(g Genome) int {
+	switch vg := g.(type) {
+	case RealGenome:
+		return len(vg.GetValues())
+	case IntegerGenome:
+		return len(vg.GetValues())
+	case PermutationGenome:
+		return len(vg.GetPermutation())
+	}
+	return g.GetBits().GetSize()
+}
@@ -0,0 +1,245 @@
+package goga_test
+
+import (
+	"context"
+	"math/rand"
+	"regexp"
+	"strings"
+
+	"github.com/tomcraven/goga"
+	. "gopkg.in/check.v1"
+)
+
+type VariableLengthSuite struct {
+}
+
+var _ = Suite(&VariableLengthSuite{})
+
+// MySimulatorFiveFullBlocks scores bitset genomes of 4 bit blocks by the number of their
+// blocks with every bit set, counting no more than 5
+type MySimulatorFiveFullBlocks struct {
+}
+
+func (ms *MySimulatorFiveFullBlocks) Simulate(g goga.Genome) {
+	full := 0
+	for _, block := range helperBlocks(g, 4) {
+		if block == 15 && full < 5 {
+			full++
+		}
+	}
+	g.SetFitness(float64(full))
+}
+func (ms *MySimulatorFiveFullBlocks) OnBeginSimulation() []goga.Genome {
+	return nil
+}
+func (ms *MySimulatorFiveFullBlocks) OnEndSimulation([]goga.Genome) {
+}
+func (ms *MySimulatorFiveFullBlocks) ExitFunc(goga.Genome) bool {
+	return false
+}
+
+// MyContextSimulatorFiveFullBlocks is MySimulatorFiveFullBlocks that counts the times it is
+// simulated with a context
+type MyContextSimulatorFiveFullBlocks struct {
+	MySimulatorFiveFullBlocks
+	contextCalls int
+}
+
+func (ms *MyContextSimulatorFiveFullBlocks) SimulateContext(ctx context.Context, g goga.Genome) {
+	ms.contextCalls++
+	ms.Simulate(g)
+}
+
+// helperBlocks returns the value of each 'width' bit block of 'g'
+func helperBlocks(g goga.Genome, width int) []uint64 {
+	bits := g.GetBits()
+	blocks := make([]uint64, bits.GetSize()/width)
+	for i := range blocks {
+		blocks[i], _ = bits.Uint64(i*width, width)
+	}
+	return blocks
+}
+
+// helperBlockGenome returns a genome of 4 bit blocks holding 'blocks'
+func helperBlockGenome(blocks ...uint64) goga.Genome {
+	b := goga.Bitset{}
+	b.Create(4 * len(blocks))
+	for i, v := range blocks {
+		b.SetUint64(i*4, 4, v)
+	}
+	return goga.NewGenome(b)
+}
+
+func (s *VariableLengthSuite) TestShouldInsertBlocks(t *C) {
+	rng := rand.New(goga.NewSource(1))
+	blockMater := goga.BlockMater{Width: 4, MaxBlocks: 4}
+	parent1, parent2 := helperBlockGenome(1, 2, 3), helperBlockGenome(4)
+	positions := map[int]bool{}
+	for i := 0; i < 50; i++ {
+		child1, child2 := blockMater.InsertMutateRand(rng, parent1, parent2)
+		t.Assert(helperBlocks(child2, 4), DeepEquals, []uint64{4})
+
+		// Removing one block of the child gives back the parent
+		blocks := helperBlocks(child1, 4)
+		t.Assert(blocks, HasLen, 4)
+		found := false
+		for at := range blocks {
+			rest := append(append([]uint64{}, blocks[:at]...), blocks[at+1:]...)
+			if rest[0] == 1 && rest[1] == 2 && rest[2] == 3 {
+				positions[at] = true
+				found = true
+			}
+		}
+		t.Assert(found, IsTrue, Commentf("%v", blocks))
+	}
+	t.Assert(positions, HasLen, 4)
+	t.Assert(helperBlocks(parent1, 4), DeepEquals, []uint64{1, 2, 3})
+
+	// A genome of MaxBlocks blocks does not grow
+	child, _ := blockMater.InsertMutateRand(rng, helperBlockGenome(1, 2, 3, 4), parent2)
+	t.Assert(helperBlocks(child, 4), DeepEquals, []uint64{1, 2, 3, 4})
+}
+
+func (s *VariableLengthSuite) TestShouldDeleteBlocks(t *C) {
+	rng := rand.New(goga.NewSource(2))
+	blockMater := goga.BlockMater{Width: 4, MinBlocks: 2}
+	parent1, parent2 := helperBlockGenome(1, 2, 3), helperBlockGenome(4)
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		child1, child2 := blockMater.DeleteMutateRand(rng, parent1, parent2)
+		t.Assert(helperBlocks(child2, 4), DeepEquals, []uint64{4})
+		blocks := helperBlocks(child1, 4)
+		t.Assert(blocks, HasLen, 2)
+		t.Assert(blocks[0] < blocks[1], IsTrue)
+		seen[child1.Key()] = true
+	}
+	t.Assert(seen, HasLen, 3)
+
+	// A genome of MinBlocks blocks does not shrink
+	child, _ := blockMater.DeleteMutateRand(rng, helperBlockGenome(1, 2), parent2)
+	t.Assert(helperBlocks(child, 4), DeepEquals, []uint64{1, 2})
+}
+
+func (s *VariableLengthSuite) TestShouldCutAndSplice(t *C) {
+	rng := rand.New(goga.NewSource(3))
+	blockMater := goga.BlockMater{Width: 2}
+	parent1 := goga.NewGenome(helperBitsetFromString(strings.Repeat("00", 6)))
+	parent2 := goga.NewGenome(helperBitsetFromString(strings.Repeat("11", 3)))
+	headOf1, headOf2 := regexp.MustCompile("^(00)*(11)*$"), regexp.MustCompile("^(11)*(00)*$")
+	lengths := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		child1, child2 := blockMater.CutAndSpliceRand(rng, parent1, parent2)
+		c1, c2 := helperBitsetToString(*child1.GetBits()), helperBitsetToString(*child2.GetBits())
+		t.Assert(headOf1.MatchString(c1), IsTrue, Commentf("%v", c1))
+		t.Assert(headOf2.MatchString(c2), IsTrue, Commentf("%v", c2))
+		t.Assert(strings.Count(c1+c2, "0"), Equals, 12)
+		t.Assert(strings.Count(c1+c2, "1"), Equals, 6)
+		lengths[len(c1)] = true
+	}
+	t.Assert(len(lengths) > 5, IsTrue)
+}
+
+func (s *VariableLengthSuite) TestShouldCutAndSpliceWithinBounds(t *C) {
+	rng := rand.New(goga.NewSource(4))
+	blockMater := goga.BlockMater{Width: 4, MinBlocks: 2, MaxBlocks: 4}
+	for i := 0; i < 200; i++ {
+		parent1 := helperBlockGenome(make([]uint64, 2+rng.Intn(3))...)
+		parent2 := helperBlockGenome(make([]uint64, 2+rng.Intn(3))...)
+		child1, child2 := blockMater.CutAndSpliceRand(rng, parent1, parent2)
+		for _, child := range []goga.Genome{child1, child2} {
+			n := len(helperBlocks(child, 4))
+			t.Assert(n >= 2 && n <= 4, IsTrue, Commentf("%v blocks", n))
+		}
+		t.Assert(child1.GetBits().GetSize()+child2.GetBits().GetSize(), Equals, parent1.GetBits().GetSize()+parent2.GetBits().GetSize())
+	}
+}
+
+func (s *VariableLengthSuite) TestShouldPanicWithPartialBlocks(t *C) {
+	blockMater := goga.BlockMater{Width: 4}
+	t.Assert(func() {
+		blockMater.DeleteMutate(goga.NewGenome(helperBitsetFromString("000")), helperBlockGenome(1))
+	}, Panics, "genome is not a whole number of blocks")
+	t.Assert(func() {
+		(&goga.BlockMater{}).InsertMutate(helperBlockGenome(1), helperBlockGenome(1))
+	}, Panics, "block width must be positive")
+}
+
+func (s *VariableLengthSuite) TestShouldCreateBitsetsOfBlocks(t *C) {
+	create := &goga.BlockBitsetCreate{Width: 70, MinBlocks: 1, MaxBlocks: 3}
+	create.SetRand(rand.New(goga.NewSource(5)))
+	sizes := map[int]bool{}
+	for i := 0; i < 50; i++ {
+		b := create.Go()
+		t.Assert(b.GetSize()%70, Equals, 0)
+		sizes[b.GetSize()] = true
+	}
+	t.Assert(sizes, DeepEquals, map[int]bool{70: true, 140: true, 210: true})
+}
+
+func (s *VariableLengthSuite) TestShouldPenaliseLength(t *C) {
+	penalty := func(length int) float64 { return 0.5 * float64(length) }
+	g := helperBlockGenome(15, 15, 0)
+
+	maximise := &goga.LengthPenalty{Simulator: &MySimulatorFiveFullBlocks{}, Penalty: penalty}
+	maximise.Simulate(g)
+	t.Assert(g.GetFitness(), Equals, 2-0.5*12)
+
+	minimise := &goga.LengthPenalty{Simulator: &MySimulatorFiveFullBlocks{}, Penalty: penalty, Direction: goga.Minimise}
+	minimise.Simulate(g)
+	t.Assert(g.GetFitness(), Equals, 2+0.5*12)
+
+	// Genomes of values are as long as their number of values
+	rg := goga.NewRealGenome([]float64{1, 2, 3})
+	(&goga.LengthPenalty{Simulator: &goga.NullSimulator{}, Penalty: penalty}).Simulate(rg)
+	t.Assert(rg.GetFitness(), Equals, -1.5)
+
+	// The context is passed on to a context simulator
+	contextSimulator := &MyContextSimulatorFiveFullBlocks{}
+	withContext := &goga.LengthPenalty{Simulator: contextSimulator, Penalty: penalty}
+	withContext.SimulateContext(context.Background(), g)
+	t.Assert(contextSimulator.contextCalls, Equals, 1)
+	t.Assert(g.GetFitness(), Equals, 2-0.5*12)
+}
+
+func (s *VariableLengthSuite) TestShouldEvolveNumberOfBlocks(t *C) {
+	blockMater := goga.BlockMater{Width: 4, MinBlocks: 1, MaxBlocks: 12}
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = &goga.LengthPenalty{
+		Simulator: &MySimulatorFiveFullBlocks{},
+		Penalty:   func(length int) float64 { return 0.1 * float64(length/4) },
+	}
+	genAlgo.BitsetCreate = &goga.BlockBitsetCreate{Width: 4, MinBlocks: 1, MaxBlocks: 2}
+	genAlgo.Selector = goga.NewSelector([]goga.SelectorFunctionProbability{
+		{P: 1, R: goga.TournamentRand(3, 1)},
+	})
+	genAlgo.Mater = goga.NewMater([]goga.MaterFunctionProbability{
+		{P: 0.8, R: blockMater.CutAndSpliceRand},
+		{P: 0.3, R: blockMater.InsertMutateRand},
+		{P: 0.3, R: blockMater.DeleteMutateRand},
+		{P: 0.5, R: goga.MutateRand},
+	})
+	genAlgo.Init(goga.PopulationSize(30), goga.ParallelSimulations(kNumThreads), goga.Seed(6), goga.MaxGenerations(60))
+	result := genAlgo.Simulate()
+
+	// Grown from 1 or 2 blocks to the 5 full blocks that score best once penalised
+	t.Assert(helperBlocks(result.Elite, 4), DeepEquals, []uint64{15, 15, 15, 15, 15})
+	t.Assert(result.Elite.GetFitness(), Equals, 5-0.1*5)
+}
+
+func (s *VariableLengthSuite) TestShouldPenaliseInDirectionOfGeneticAlgorithm(t *C) {
+	penalty := &goga.LengthPenalty{
+		Simulator: &MySimulatorFiveFullBlocks{},
+		Penalty:   func(length int) float64 { return 0.5 * float64(length) },
+	}
+	genAlgo := goga.NewGeneticAlgorithm()
+	genAlgo.Simulator = penalty
+	genAlgo.BitsetCreate = &goga.BlockBitsetCreate{Width: 4, MinBlocks: 3, MaxBlocks: 3}
+	genAlgo.Init(goga.PopulationSize(4), goga.ObjectiveDirection(goga.Minimise))
+
+	genomes := genAlgo.Ask(4)
+	t.Assert(penalty.Direction, Equals, goga.Minimise)
+	for _, g := range genomes {
+		penalty.Simulate(g)
+		t.Assert(g.GetFitness() >= 0.5*12, IsTrue)
+	}
+}